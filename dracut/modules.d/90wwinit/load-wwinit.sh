@@ -43,14 +43,14 @@ get_stage() {
         system)  uri="${base}/system/${hwaddr}" ;;
         runtime) uri="${base}/runtime/${hwaddr}" ;;
     esac
+    # --compressed negotiates the compression (zstd, if curl supports it,
+    # or gzip) with the server and decompresses the response
     (
-        curl --location --silent --get ${localport} ${cacert_opt} \
+        curl --location --silent --get --compressed ${localport} ${cacert_opt} \
             --retry 60 --retry-connrefused --retry-delay 1 \
             --data-urlencode "assetkey=${wwinit_assetkey}" \
             --data-urlencode "uuid=${wwinit_uuid}" \
-            --data-urlencode "compress=gz" \
             "${uri}" \
-        | gzip -d \
         | cpio -ium --directory="${NEWROOT}"
     )
 }
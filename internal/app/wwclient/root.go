@@ -1,6 +1,7 @@
 package wwclient
 
 import (
+	"compress/gzip"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/coreos/go-systemd/daemon"
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 	"github.com/opencontainers/selinux/go-selinux"
 	"github.com/siderolabs/go-smbios/smbios"
 	"github.com/spf13/cobra"
@@ -316,7 +318,6 @@ func updateSystem(target string, ipaddr string, port int, wwid string, tag strin
 		values := &url.Values{}
 		values.Set("assetkey", tag)
 		values.Set("uuid", localUUID.String())
-		values.Set("compress", "gz")
 		getURL := &url.URL{
 			Scheme:   scheme,
 			Host:     fmt.Sprintf("%s:%d", ipaddr, port),
@@ -324,7 +325,15 @@ func updateSystem(target string, ipaddr string, port int, wwid string, tag strin
 			RawQuery: values.Encode(),
 		}
 		wwlog.Debug("making request: %s", getURL)
-		resp, err = Webclient.Get(getURL.String())
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodGet, getURL.String(), nil)
+		if err != nil {
+			return err
+		}
+		// servers that do not negotiate the compression send the overlay
+		// uncompressed
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err = Webclient.Do(req)
 		if err == nil {
 			break
 		} else {
@@ -367,8 +376,14 @@ func updateSystem(target string, ipaddr string, port int, wwid string, tag strin
 		}
 	}()
 	wwlog.Debug("unpacking runtime overlay to %s", tempDir)
-	command := exec.Command("/bin/sh", "-c", fmt.Sprintf("gzip -dc | cpio -imu --directory=%s", tempDir))
-	command.Stdin = resp.Body
+	body, err := decodeBody(resp)
+	if err != nil {
+		wwlog.Error("failed to decompress runtime overlay: %s", err)
+		return nil
+	}
+	defer func() { _ = body.Close() }()
+	command := exec.Command("cpio", "-imu", "--directory="+tempDir)
+	command.Stdin = body
 	err = command.Run()
 	if err != nil {
 		wwlog.Error("failed running cpio: %s", err)
@@ -383,6 +398,28 @@ func updateSystem(target string, ipaddr string, port int, wwid string, tag strin
 	return nil
 }
 
+// acceptEncoding lists the compression formats that decodeBody supports.
+const acceptEncoding = "zstd, gzip"
+
+// decodeBody returns a reader of the decompressed body of resp, according
+// to its Content-Encoding.
+func decodeBody(resp *http.Response) (io.ReadCloser, error) {
+	switch encoding := resp.Header.Get("Content-Encoding"); encoding {
+	case "":
+		return io.NopCloser(resp.Body), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(resp.Body)
+	case "zstd":
+		decoder, err := zstd.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
 func atomicApplyOverlay(srcDir, destDir string) error {
 	return filepath.Walk(srcDir, func(srcPath string, info os.FileInfo, err error) error {
 		if err != nil {
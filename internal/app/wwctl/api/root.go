@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/api/token"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "api COMMAND [OPTIONS]",
+		Short:                 "Warewulf REST API Management",
+		Long:                  "Management interface for access to the Warewulf REST API",
+		Args:                  cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(token.GetCommand())
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,53 @@
+package create
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	expires, err := parseExpires(Expires, time.Now())
+	if err != nil {
+		return err
+	}
+
+	tokensConf := warewulfconf.Get().Paths.APITokensConf()
+	tokens, err := warewulfconf.ReadAPITokens(tokensConf)
+	if err != nil {
+		return err
+	}
+	secret, err := tokens.Create(args[0], warewulfconf.Role(Scope), expires)
+	if err != nil {
+		return err
+	}
+	if err := tokens.Write(tokensConf); err != nil {
+		return fmt.Errorf("could not write %s: %w", tokensConf, err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), secret)
+	return nil
+}
+
+// parseExpires parses a token expiry given either as a duration from now or
+// as a date. An empty value means the token does not expire.
+func parseExpires(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("expiry must be in the future: %s", value)
+		}
+		return now.Add(duration), nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry (use a duration such as 720h or a date such as 2006-01-02): %s", value)
+	}
+	if !date.After(now) {
+		return time.Time{}, fmt.Errorf("expiry must be in the future: %s", value)
+	}
+	return date, nil
+}
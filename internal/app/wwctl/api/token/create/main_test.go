@@ -0,0 +1,35 @@
+package create
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseExpires(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	tests := map[string]struct {
+		value   string
+		expires time.Time
+		err     bool
+	}{
+		"none":     {"", time.Time{}, false},
+		"duration": {"720h", now.Add(720 * time.Hour), false},
+		"date":     {"2025-02-01", time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), false},
+		"past":     {"2024-12-31", time.Time{}, true},
+		"negative": {"-1h", time.Time{}, true},
+		"invalid":  {"tomorrow", time.Time{}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			expires, err := parseExpires(tt.value, now)
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expires, expires)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package create
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "create [OPTIONS] NAME",
+		Short:                 "Create a REST API token",
+		Long: "This command creates a new REST API bearer token called NAME and prints its\n" +
+			"secret. The secret is not stored and cannot be shown again.",
+		RunE:    CobraRunE,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"new", "add"},
+	}
+	Scope   string
+	Expires string
+)
+
+func init() {
+	baseCmd.PersistentFlags().StringVar(&Scope, "scope", "", "Role granted to the token: read-only, node-operator, or admin (default admin)")
+	baseCmd.PersistentFlags().StringVar(&Expires, "expires", "", "Expire the token after a duration (e.g., 720h) or on a date (YYYY-MM-DD)")
+	_ = baseCmd.RegisterFlagCompletionFunc("scope", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"read-only", "node-operator", "admin"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,37 @@
+package list
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	tokens, err := warewulfconf.ReadAPITokens(warewulfconf.Get().Paths.APITokensConf())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("NAME", "SCOPE", "CREATED", "EXPIRES")
+	for _, token := range tokens.Tokens {
+		expires := ""
+		if token.Expires != 0 {
+			expires = time.Unix(token.Expires, 0).Format(time.DateTime)
+			if token.Expired(now) {
+				expires += " (expired)"
+			}
+		}
+		t.AddLine(table.Prep([]string{
+			token.Name,
+			string(token.GetScope()),
+			time.Unix(token.Created, 0).Format(time.DateTime),
+			expires,
+		})...)
+	}
+	t.Print()
+	return nil
+}
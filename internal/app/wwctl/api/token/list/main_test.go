@@ -0,0 +1,35 @@
+package list
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Token_List(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	tokensConf := warewulfconf.Get().Paths.APITokensConf()
+	tokens := new(warewulfconf.APITokens)
+	_, err := tokens.Create("ci", warewulfconf.RoleReadOnly, time.Time{})
+	assert.NoError(t, err)
+	_, err = tokens.Create("automation", "", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, tokens.Write(tokensConf))
+
+	baseCmd := GetCommand()
+	buf := new(bytes.Buffer)
+	baseCmd.SetOut(buf)
+	baseCmd.SetErr(buf)
+	baseCmd.SetArgs([]string{})
+	assert.NoError(t, baseCmd.Execute())
+	assert.Regexp(t, `ci\s+read-only`, buf.String())
+	assert.Regexp(t, `automation\s+admin`, buf.String())
+	assert.NotContains(t, buf.String(), "sha256:")
+}
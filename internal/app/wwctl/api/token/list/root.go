@@ -0,0 +1,22 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list [OPTIONS]",
+		Short:                 "List REST API tokens",
+		Long:                  "This command lists REST API tokens with their scope and expiry.",
+		RunE:                  CobraRunE,
+		Args:                  cobra.NoArgs,
+		Aliases:               []string{"ls"},
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
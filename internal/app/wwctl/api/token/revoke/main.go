@@ -0,0 +1,29 @@
+package revoke
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	tokensConf := warewulfconf.Get().Paths.APITokensConf()
+	tokens, err := warewulfconf.ReadAPITokens(tokensConf)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := tokens.Revoke(name); err != nil {
+			return err
+		}
+	}
+	if err := tokens.Write(tokensConf); err != nil {
+		return fmt.Errorf("could not write %s: %w", tokensConf, err)
+	}
+	for _, name := range args {
+		wwlog.Info("Revoked token: %s", name)
+	}
+	return nil
+}
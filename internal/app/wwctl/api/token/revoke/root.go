@@ -0,0 +1,22 @@
+package revoke
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "revoke [OPTIONS] NAME [NAME ...]",
+		Short:                 "Revoke REST API tokens",
+		Long:                  "This command revokes the named REST API tokens, which can no longer be used\nto authenticate.",
+		RunE:                  CobraRunE,
+		Args:                  cobra.MinimumNArgs(1),
+		Aliases:               []string{"delete", "rm"},
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
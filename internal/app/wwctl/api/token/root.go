@@ -0,0 +1,30 @@
+package token
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/api/token/create"
+	"github.com/warewulf/warewulf/internal/app/wwctl/api/token/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/api/token/revoke"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "token COMMAND [OPTIONS]",
+		Short:                 "REST API token management",
+		Long: "Management interface for REST API bearer tokens. Tokens are stored hashed\n" +
+			"in tokens.conf, next to auth.conf.",
+		Args: cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(create.GetCommand())
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(revoke.GetCommand())
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
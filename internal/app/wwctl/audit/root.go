@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/audit/show"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "audit COMMAND [OPTIONS]",
+		Short:                 "Warewulf audit log",
+		Long:                  "Query the log of changes made to Warewulf through the REST API and wwctl",
+		Args:                  cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(show.GetCommand())
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,87 @@
+package show
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	since, err := parseSince(Since, time.Now())
+	if err != nil {
+		return err
+	}
+	filter := audit.Filter{User: User, Since: since}
+	selected := 0
+	for _, id := range []string{Node, Profile, Image, Overlay} {
+		if id != "" {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return fmt.Errorf("only one of --node, --profile, --image, or --overlay may be given")
+	}
+	switch {
+	case Node != "":
+		filter.Entity, filter.ID = audit.EntityNode, Node
+	case Profile != "":
+		filter.Entity, filter.ID = audit.EntityProfile, Profile
+	case Image != "":
+		filter.Entity, filter.ID = audit.EntityImage, Image
+	case Overlay != "":
+		filter.Entity, filter.ID = audit.EntityOverlay, Overlay
+	}
+
+	entries, err := audit.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("TIME", "USER", "SOURCE", "ENTITY", "ID", "OPERATION", "CHANGE")
+	for _, entry := range entries {
+		change := entry.File
+		if len(entry.Changes) > 0 {
+			change = formatChange(entry.Changes[0].Path, entry.Changes[0].Before, entry.Changes[0].After)
+		}
+		t.AddLine(table.Prep([]string{
+			entry.Time.Local().Format(time.DateTime),
+			entry.User,
+			entry.Source,
+			entry.Entity,
+			entry.ID,
+			entry.Operation,
+			change,
+		})...)
+		for _, c := range entry.Changes[min(1, len(entry.Changes)):] {
+			t.AddLine("", "", "", "", "", "", formatChange(c.Path, c.Before, c.After))
+		}
+	}
+	t.Print()
+	return nil
+}
+
+func formatChange(path, before, after string) string {
+	return fmt.Sprintf("%s: %s → %s", path, before, after)
+}
+
+// parseSince parses the start of the period to show, given either as a
+// duration before now or as a date. An empty value shows every entry.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time (use a duration such as 24h or a date such as 2006-01-02): %s", value)
+}
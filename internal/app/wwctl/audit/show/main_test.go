@@ -0,0 +1,73 @@
+package show
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Audit_Show(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	now := time.Now()
+	for _, entry := range []audit.Entry{
+		{Time: now.Add(-48 * time.Hour), User: "alice", Source: "wwctl", Entity: audit.EntityNode, ID: "n001", Operation: "set",
+			Changes: []node.Change{{Path: "comment", Before: "<unset>", After: "old"}}},
+		{Time: now.Add(-time.Hour), User: "bob", Source: "127.0.0.1", Entity: audit.EntityNode, ID: "n001", Operation: "update",
+			Changes: []node.Change{{Path: "comment", Before: "old", After: "new"}, {Path: "tags[rack]", Before: "<unset>", After: "12"}}},
+		{Time: now.Add(-time.Hour), User: "bob", Source: "127.0.0.1", Entity: audit.EntityNode, ID: "n002", Operation: "delete"},
+		{Time: now.Add(-time.Hour), User: "alice", Source: "wwctl", Entity: audit.EntityOverlay, ID: "site", Operation: "edit", File: "etc/motd"},
+	} {
+		assert.NoError(t, audit.Record(entry))
+	}
+
+	tests := map[string]struct {
+		args        []string
+		contains    []string
+		notContains []string
+	}{
+		"all": {
+			args:     []string{},
+			contains: []string{"old → new", "tags[rack]: <unset> → 12", "n002", "etc/motd"},
+		},
+		"node since": {
+			args:        []string{"--node", "n001", "--since", "24h"},
+			contains:    []string{"bob", "old → new"},
+			notContains: []string{"<unset> → old", "n002", "etc/motd"},
+		},
+		"user": {
+			args:        []string{"--user", "alice"},
+			contains:    []string{"<unset> → old", "etc/motd"},
+			notContains: []string{"bob"},
+		},
+		"overlay": {
+			args:        []string{"--overlay", "site"},
+			contains:    []string{"edit", "etc/motd"},
+			notContains: []string{"n001"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			Node, Profile, Image, Overlay, User, Since = "", "", "", "", "", ""
+			baseCmd := GetCommand()
+			buf := new(bytes.Buffer)
+			baseCmd.SetOut(buf)
+			baseCmd.SetErr(buf)
+			baseCmd.SetArgs(tt.args)
+			assert.NoError(t, baseCmd.Execute())
+			for _, s := range tt.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tt.notContains {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package show
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "show [OPTIONS]",
+		Short:                 "Show audit log entries",
+		Long: "This command shows entries from the audit log, oldest first, optionally\n" +
+			"limited to a single node, profile, image, or overlay, a user, or a period.",
+		RunE:    CobraRunE,
+		Args:    cobra.NoArgs,
+		Aliases: []string{"list", "ls"},
+	}
+	Node    string
+	Profile string
+	Image   string
+	Overlay string
+	User    string
+	Since   string
+)
+
+func init() {
+	baseCmd.PersistentFlags().StringVar(&Node, "node", "", "Show changes to a node")
+	baseCmd.PersistentFlags().StringVar(&Profile, "profile", "", "Show changes to a profile")
+	baseCmd.PersistentFlags().StringVar(&Image, "image", "", "Show changes to an image")
+	baseCmd.PersistentFlags().StringVar(&Overlay, "overlay", "", "Show changes to an overlay")
+	baseCmd.PersistentFlags().StringVar(&User, "user", "", "Show changes made by a user")
+	baseCmd.PersistentFlags().StringVar(&Since, "since", "", "Show changes made within a duration (e.g., 24h) or since a date (YYYY-MM-DD)")
+	_ = baseCmd.RegisterFlagCompletionFunc("node", completions.Nodes)
+	_ = baseCmd.RegisterFlagCompletionFunc("profile", completions.Profiles)
+	_ = baseCmd.RegisterFlagCompletionFunc("image", completions.Images)
+	_ = baseCmd.RegisterFlagCompletionFunc("overlay", completions.Overlays)
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,63 @@
+package flags
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+const WhereDocstring = "Nodes may also be selected by attribute with --where, e.g.\n" +
+	"--where profile=gpu,tag.rack=12,image=rocky9, alone or together with node patterns."
+
+// AddWhere adds the --where flag, which selects nodes by their attributes.
+func AddWhere(cmd *cobra.Command, dest *string) {
+	cmd.PersistentFlags().StringVar(dest, "where", "",
+		"Select nodes by attribute (e.g. profile=gpu,tag.rack=12,image=rocky9)")
+}
+
+// MinimumNodeArgs requires at least n arguments, or n-1 if --where is given,
+// in which case the node pattern may be omitted.
+func MinimumNodeArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		minimum := n
+		if where := cmd.Flags().Lookup("where"); where != nil && where.Changed {
+			minimum--
+		}
+		return cobra.MinimumNArgs(minimum)(cmd, args)
+	}
+}
+
+// SelectNodes returns the names of the nodes that are selected by where, from
+// among the nodes matched by the node patterns in args, or from all nodes if
+// args is empty. If where is empty, args is returned unchanged. It is an
+// error for where to select no nodes.
+func SelectNodes(args []string, where string) ([]string, error) {
+	if where == "" {
+		return args, nil
+	}
+	sel, err := node.ParseSelector(where)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := node.New()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := registry.FindAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		nodes = node.FilterNodeListByName(nodes, hostlist.Expand(args))
+	}
+	var names []string
+	for _, n := range node.FilterNodeListBySelector(nodes, sel) {
+		names = append(names, n.Id())
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no nodes match --where %s", where)
+	}
+	return names, nil
+}
@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/history"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	var versions []history.Version
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", arg)
+		}
+		v, err := history.Get(id)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, v)
+	}
+	from := versions[0]
+	before, err := from.Content()
+	if err != nil {
+		return err
+	}
+
+	var after []byte
+	toLabel := from.Target() + "@current"
+	if len(versions) > 1 {
+		to := versions[1]
+		if to.Target() != from.Target() {
+			return fmt.Errorf("versions %d and %d are of different files: %s, %s", from.ID, to.ID, from.Target(), to.Target())
+		}
+		if after, err = to.Content(); err != nil {
+			return err
+		}
+		toLabel = fmt.Sprintf("%s@%d", to.Target(), to.ID)
+	} else if after, err = Current(from); err != nil {
+		return err
+	}
+	return Print(cmd.OutOrStdout(), from, fmt.Sprintf("%s@%d", from.Target(), from.ID), before, toLabel, after)
+}
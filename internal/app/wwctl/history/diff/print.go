@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/history"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+)
+
+// Current returns the current content of the file of which v is a version,
+// or nil if the file does not exist.
+func Current(v history.Version) ([]byte, error) {
+	fileName := NodesFile(v)
+	if v.Overlay != "" {
+		o, err := overlay.Get(v.Overlay)
+		if err != nil {
+			return nil, nil
+		}
+		fileName = o.File(v.File)
+	}
+	content, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// NodesFile returns the path of the node configuration file of which v is a
+// version: nodes.conf or a fragment it includes.
+func NodesFile(v history.Version) string {
+	nodesConf := config.Get().Paths.NodesConf()
+	if v.File == history.NodesConf {
+		return nodesConf
+	}
+	if filepath.IsAbs(v.File) {
+		return v.File
+	}
+	return filepath.Join(filepath.Dir(nodesConf), v.File)
+}
+
+// Print writes the differences between two contents of the file of which v
+// is a version. Changes to nodes.conf and its fragments are shown per node
+// and profile; overlay files are shown as a unified diff.
+func Print(w io.Writer, v history.Version, fromLabel string, from []byte, toLabel string, to []byte) error {
+	if v.Overlay == "" {
+		return printNodesConf(w, from, to)
+	}
+	text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(from)),
+		B:        difflib.SplitLines(string(to)),
+		FromFile: fromLabel,
+		ToFile:   toLabel,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, text)
+	return err
+}
+
+func printNodesConf(w io.Writer, from, to []byte) error {
+	before, err := node.Parse(from)
+	if err != nil {
+		return err
+	}
+	after, err := node.Parse(to)
+	if err != nil {
+		return err
+	}
+
+	profileChanges := make(map[string][]node.Change)
+	for _, id := range union(before.NodeProfiles, after.NodeProfiles) {
+		printPresence(w, "profile", id, before.NodeProfiles, after.NodeProfiles)
+		if changes := node.Diff(profileOrNew(before.NodeProfiles, id), profileOrNew(after.NodeProfiles, id)); len(changes) > 0 {
+			profileChanges[id] = changes
+		}
+	}
+	nodeChanges := make(map[string][]node.Change)
+	for _, id := range union(before.Nodes, after.Nodes) {
+		printPresence(w, "node", id, before.Nodes, after.Nodes)
+		if changes := node.Diff(nodeOrNew(before.Nodes, id), nodeOrNew(after.Nodes, id)); len(changes) > 0 {
+			nodeChanges[id] = changes
+		}
+	}
+
+	if summary := node.FormatChanges(profileChanges); summary != "" {
+		fmt.Fprintf(w, "Profiles:\n%s", summary)
+	}
+	if summary := node.FormatChanges(nodeChanges); summary != "" {
+		fmt.Fprintf(w, "Nodes:\n%s", summary)
+	}
+	return nil
+}
+
+// printPresence reports an entity that was added or deleted.
+func printPresence[V any](w io.Writer, kind, id string, before, after map[string]V) {
+	_, inBefore := before[id]
+	_, inAfter := after[id]
+	if inAfter && !inBefore {
+		fmt.Fprintf(w, "Added %s: %s\n", kind, id)
+	} else if inBefore && !inAfter {
+		fmt.Fprintf(w, "Deleted %s: %s\n", kind, id)
+	}
+}
+
+func profileOrNew(profiles map[string]*node.Profile, id string) *node.Profile {
+	if profile := profiles[id]; profile != nil {
+		return profile
+	}
+	profile := node.NewProfile(id)
+	return &profile
+}
+
+func nodeOrNew(nodes map[string]*node.Node, id string) *node.Node {
+	if n := nodes[id]; n != nil {
+		return n
+	}
+	n := node.NewNode(id)
+	return &n
+}
+
+func union[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool)
+	for id := range a {
+		seen[id] = true
+	}
+	for id := range b {
+		seen[id] = true
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
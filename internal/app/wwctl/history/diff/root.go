@@ -0,0 +1,22 @@
+package diff
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "diff [OPTIONS] ID [ID]",
+		Short:                 "Compare recorded versions",
+		Long: "This command shows the changes from version ID to a second version ID of the same\n" +
+			"file or, if only one is given, to the current content of the file.",
+		RunE: CobraRunE,
+		Args: cobra.RangeArgs(1, 2),
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,49 @@
+package list
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/history"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	if !history.Enabled() {
+		wwlog.Warn("History is disabled: set \"history: true\" under \"warewulf:\" in warewulf.conf")
+	}
+	versions, err := history.List()
+	if err != nil {
+		return err
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("ID", "TIME", "USER", "FILE", "COMMAND")
+	for _, v := range versions {
+		if len(args) > 0 && !match(v, args[0]) {
+			continue
+		}
+		t.AddLine(table.Prep([]string{
+			strconv.Itoa(v.ID),
+			v.Time.Local().Format(time.DateTime),
+			v.User,
+			v.Target(),
+			v.Command,
+		})...)
+	}
+	t.Print()
+	return nil
+}
+
+// match returns true if v is a version of file, which is either the target
+// of v or the name of its overlay.
+func match(v history.Version, file string) bool {
+	if overlayName, fileName, ok := strings.Cut(file, ":"); ok {
+		return v.Overlay == overlayName && v.File == path.Clean("/"+fileName)
+	}
+	return v.Target() == file || (v.Overlay != "" && v.Overlay == file)
+}
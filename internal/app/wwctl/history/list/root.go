@@ -0,0 +1,24 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list [OPTIONS] [FILE]",
+		Short:                 "List recorded versions",
+		Long: "This command lists the recorded versions of nodes.conf and of files in site overlays,\n" +
+			"oldest first. FILE selects the versions of \"nodes.conf\", of an overlay (OVERLAY),\n" +
+			"or of a file in an overlay (OVERLAY:FILE).",
+		RunE:    CobraRunE,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"ls"},
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,72 @@
+package rollback
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/diff"
+	"github.com/warewulf/warewulf/internal/pkg/history"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid version: %s", args[0])
+	}
+	v, err := history.Get(id)
+	if err != nil {
+		return err
+	}
+	content, err := v.Content()
+	if err != nil {
+		return err
+	}
+	current, err := diff.Current(v)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(current, content) {
+		wwlog.Info("%s is already at version %d", v.Target(), v.ID)
+		return nil
+	}
+
+	if err := diff.Print(cmd.OutOrStdout(), v, v.Target()+"@current", current, fmt.Sprintf("%s@%d", v.Target(), v.ID), content); err != nil {
+		return err
+	}
+	if !SetYes {
+		if !util.Confirm(fmt.Sprintf("Are you sure you want to roll back %s to version %d", v.Target(), v.ID)) {
+			return nil
+		}
+	}
+
+	if v.Overlay != "" {
+		o, err := overlay.Get(v.Overlay)
+		if err != nil {
+			return err
+		}
+		return o.AddFile(v.File, content, true, true)
+	}
+
+	registry, err := node.New()
+	if err != nil {
+		return err
+	}
+	restored, err := node.Parse(content)
+	if err != nil {
+		return err
+	}
+	if err := registry.Replace(diff.NodesFile(v), restored); err != nil {
+		return err
+	}
+	if err := registry.Persist(); err != nil {
+		return fmt.Errorf("failed to persist nodedb: %w", err)
+	}
+	return warewulfd.DaemonReload()
+}
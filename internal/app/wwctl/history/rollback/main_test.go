@@ -0,0 +1,79 @@
+package rollback
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/diff"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func Test_Rollback(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	enabled := true
+	conf := env.Configure()
+	conf.Warewulf.HistoryP = &enabled
+	warewulfd.SetNoDaemon()
+
+	env.WriteFile("etc/warewulf/nodes.conf", `nodeprofiles:
+  default:
+    comment: original
+nodes:
+  n1:
+    profiles:
+    - default
+`)
+	registry, err := node.New()
+	assert.NoError(t, err)
+	registry.NodeProfiles["default"].Comment = "changed"
+	_, err = registry.AddNode("n2")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Persist())
+
+	t.Run("diff", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cmd := diff.GetCommand()
+		cmd.SetOut(buf)
+		cmd.SetArgs([]string{"1", "2"})
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, `Added node: n2
+Profiles:
+default:
+  comment: "original" → "changed"
+`, buf.String())
+	})
+
+	t.Run("rollback nodes.conf", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		baseCmd.SetOut(buf)
+		baseCmd.SetArgs([]string{"--yes", "1"})
+		assert.NoError(t, baseCmd.Execute())
+		assert.Contains(t, buf.String(), "Deleted node: n2\nProfiles:\ndefault:\n  comment: \"changed\" → \"original\"\n")
+
+		registry, err := node.New()
+		assert.NoError(t, err)
+		assert.Equal(t, "original", registry.NodeProfiles["default"].Comment)
+		assert.NotContains(t, registry.Nodes, "n2")
+	})
+
+	t.Run("rollback overlay file", func(t *testing.T) {
+		env.WriteFile("var/lib/warewulf/overlays/site/rootfs/etc/motd", "hello\n")
+		o, err := overlay.Get("site")
+		assert.NoError(t, err)
+		assert.NoError(t, o.AddFile("etc/motd", []byte("goodbye\n"), true, true))
+
+		// versions 1-3 are of nodes.conf
+		buf := new(bytes.Buffer)
+		baseCmd.SetOut(buf)
+		baseCmd.SetArgs([]string{"--yes", "4"})
+		assert.NoError(t, baseCmd.Execute())
+		assert.Contains(t, buf.String(), "-goodbye\n+hello\n")
+		assert.Equal(t, "hello\n", env.ReadFile("var/lib/warewulf/overlays/site/rootfs/etc/motd"))
+	})
+}
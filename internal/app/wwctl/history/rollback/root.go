@@ -0,0 +1,28 @@
+package rollback
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "rollback [OPTIONS] ID",
+		Short:                 "Restore a recorded version",
+		Long: "This command restores the file of version ID, either nodes.conf or a file in a site\n" +
+			"overlay, to its content at that version. The changes to be made are shown before\n" +
+			"they are applied, and the rollback is itself recorded as a new version.",
+		RunE: CobraRunE,
+		Args: cobra.ExactArgs(1),
+	}
+	SetYes bool
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVarP(&SetYes, "yes", "y", false, "Set 'yes' to all questions asked")
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,32 @@
+package history
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/diff"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/rollback"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/show"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "history COMMAND [OPTIONS]",
+		Short:                 "Configuration history",
+		Long: "Review and roll back changes to nodes.conf and to files in site overlays. History is\n" +
+			"kept only if \"history: true\" is set under \"warewulf:\" in warewulf.conf.",
+		Args: cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(show.GetCommand())
+	baseCmd.AddCommand(diff.GetCommand())
+	baseCmd.AddCommand(rollback.GetCommand())
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,51 @@
+package show
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history/diff"
+	"github.com/warewulf/warewulf/internal/pkg/history"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid version: %s", args[0])
+	}
+	v, err := history.Get(id)
+	if err != nil {
+		return err
+	}
+	content, err := v.Content()
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	if Content {
+		_, err := w.Write(content)
+		return err
+	}
+
+	fmt.Fprintf(w, "Version: %d\n", v.ID)
+	fmt.Fprintf(w, "Time:    %s\n", v.Time.Local().Format(time.DateTime))
+	fmt.Fprintf(w, "User:    %s\n", v.User)
+	fmt.Fprintf(w, "File:    %s\n", v.Target())
+	fmt.Fprintf(w, "Command: %s\n\n", v.Command)
+
+	previous, err := history.Previous(v)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		fmt.Fprintf(w, "First recorded version of %s\n", v.Target())
+		return nil
+	}
+	before, err := previous.Content()
+	if err != nil {
+		return err
+	}
+	return diff.Print(w, v, fmt.Sprintf("%s@%d", v.Target(), previous.ID), before, fmt.Sprintf("%s@%d", v.Target(), v.ID), content)
+}
@@ -0,0 +1,27 @@
+package show
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "show [OPTIONS] ID",
+		Short:                 "Show a recorded version",
+		Long: "This command shows the version ID and the changes it made to the previous version\n" +
+			"of the same file. Changes to nodes.conf are shown per node and profile.",
+		RunE: CobraRunE,
+		Args: cobra.ExactArgs(1),
+	}
+	Content bool
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVar(&Content, "content", false, "Show the content of the file at this version rather than its changes")
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/image"
 )
 
@@ -32,6 +33,7 @@ func CobraRunE(cmd *cobra.Command, imageNames []string) error {
 		if err := image.Build(imageName, BuildForce); err != nil {
 			return fmt.Errorf("error building image %s: %s", imageName, err)
 		}
+		audit.LogCommand(audit.Entry{Entity: audit.EntityImage, ID: imageName, Operation: "build"})
 	}
 
 	return nil
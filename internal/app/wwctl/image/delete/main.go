@@ -3,6 +3,7 @@ package delete
 import (
 	"fmt"
 
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/image"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 
@@ -22,6 +23,7 @@ func CobraRunE(cmd *cobra.Command, imageNames []string) (err error) {
 		if err := image.Delete(imageName); err != nil {
 			return fmt.Errorf("error deleting image %s: %s", imageName, err)
 		}
+		audit.LogCommand(audit.Entry{Entity: audit.EntityImage, ID: imageName, Operation: "delete"})
 	}
 
 	return nil
@@ -9,7 +9,9 @@ import (
 
 	"github.com/containers/image/v5/types"
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -73,6 +75,8 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	} else {
 		return fmt.Errorf("invalid dir or uri: %s", source)
 	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityImage, ID: name, Operation: "import",
+		Changes: []node.Change{{Path: "uri", After: source}}})
 
 	if SyncUser {
 		if err := image.Syncuser(name, true); err != nil {
@@ -85,6 +89,7 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		if err := image.Build(name, true); err != nil {
 			return fmt.Errorf("could not build image %s: %s", name, err.Error())
 		}
+		audit.LogCommand(audit.Entry{Entity: audit.EntityImage, ID: name, Operation: "build"})
 	}
 	return nil
 }
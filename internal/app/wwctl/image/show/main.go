@@ -1,7 +1,10 @@
 package show
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/warewulf/warewulf/internal/pkg/image"
 	"github.com/warewulf/warewulf/internal/pkg/kernel"
@@ -46,6 +49,22 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if ShowManifest {
+		manifest, err := image.ReadManifest(imageName)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("image %s has not been built", imageName)
+			}
+			return err
+		}
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+
 	if !ShowAll {
 		fmt.Printf("%s\n", rootFsDir)
 	} else {
@@ -57,6 +76,17 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Rootfs: %s\n", rootFsDir)
 		fmt.Printf("Nr nodes: %d\n", len(nodeList))
 		fmt.Printf("Nodes: %v\n", nodeList)
+		if manifest, err := image.ReadManifest(imageName); err == nil {
+			fmt.Printf("Built: %s\n", time.Unix(manifest.BuildTime, 0).Format(time.RFC3339))
+			fmt.Printf("Source digest: %s\n", manifest.SourceDigest)
+			fmt.Printf("Excludes: %v\n", manifest.Excludes)
+			fmt.Printf("Nr files: %d\n", len(manifest.Files))
+			for _, imageFile := range manifest.Images {
+				fmt.Printf("Image: %s (%s) %s\n", imageFile.File, util.ByteToString(imageFile.Size), imageFile.Digest)
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
 	}
 
 	return nil
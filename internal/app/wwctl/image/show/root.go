@@ -11,7 +11,9 @@ var (
 		Use:                   "show [OPTIONS] IMAGE",
 		Short:                 "Show root fs dir for image",
 		Long: `Shows the base directory for the chroot of the given image.
-More information about the image can be shown with the '-a' option.`,
+More information about the image can be shown with the '-a' option, and
+the manifest of the built image, listing its files and digests, with the
+'--manifest' option.`,
 		RunE: CobraRunE,
 		Args: cobra.ExactArgs(1),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -21,11 +23,13 @@ More information about the image can be shown with the '-a' option.`,
 			return completions.None(cmd, args, toComplete)
 		},
 	}
-	ShowAll bool
+	ShowAll      bool
+	ShowManifest bool
 )
 
 func init() {
 	baseCmd.PersistentFlags().BoolVarP(&ShowAll, "all", "a", false, "Show all information about an image")
+	baseCmd.PersistentFlags().BoolVarP(&ShowManifest, "manifest", "m", false, "Show the manifest of the built image")
 
 }
 
@@ -0,0 +1,37 @@
+package list
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	statuses, err := jobs.List()
+	if err != nil {
+		return err
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("ID", "TYPE", "TARGET", "STATE", "CREATED", "PROGRESS", "ERROR")
+	for _, status := range statuses {
+		progress := "--"
+		if status.Total > 0 {
+			progress = fmt.Sprintf("%d/%d", status.Done, status.Total)
+		}
+		t.AddLine(table.Prep([]string{
+			status.ID,
+			status.Type,
+			status.Target,
+			string(status.State),
+			time.Unix(status.Created, 0).Format(time.DateTime),
+			progress,
+			status.Error,
+		})...)
+	}
+	t.Print()
+	return nil
+}
@@ -0,0 +1,22 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list [OPTIONS]",
+		Short:                 "List background jobs",
+		Long:                  "This command lists running and recently finished background jobs, oldest first.",
+		RunE:                  CobraRunE,
+		Args:                  cobra.NoArgs,
+		Aliases:               []string{"ls"},
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
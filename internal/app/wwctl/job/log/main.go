@@ -0,0 +1,30 @@
+package log
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	printed := 0
+	for {
+		status, err := jobs.Read(args[0])
+		if err != nil {
+			return err
+		}
+		for _, line := range status.Log[min(printed, len(status.Log)):] {
+			fmt.Fprintln(cmd.OutOrStdout(), line)
+		}
+		printed = max(printed, len(status.Log))
+		if !Follow || status.State.Finished() {
+			if status.State == jobs.Failed {
+				return fmt.Errorf("job %s failed: %s", status.ID, status.Error)
+			}
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Job_Log(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	pollInterval = 10 * time.Millisecond
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	job, err := jobs.Start("test", "target", func(ctx context.Context, job *jobs.Job) error {
+		job.Logf("first step")
+		close(started)
+		<-release
+		job.Logf("second step")
+		return nil
+	})
+	assert.NoError(t, err)
+	<-started
+
+	Follow = false
+	baseCmd := GetCommand()
+	buf := new(bytes.Buffer)
+	baseCmd.SetOut(buf)
+	baseCmd.SetErr(buf)
+	baseCmd.SetArgs([]string{job.ID()})
+	assert.NoError(t, baseCmd.Execute())
+	assert.Contains(t, buf.String(), "first step")
+	assert.NotContains(t, buf.String(), "second step")
+
+	buf.Reset()
+	baseCmd.SetArgs([]string{"--follow", job.ID()})
+	close(release)
+	assert.NoError(t, baseCmd.Execute())
+	assert.Contains(t, buf.String(), "second step")
+	assert.Contains(t, buf.String(), "Job succeeded")
+}
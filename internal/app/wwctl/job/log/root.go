@@ -0,0 +1,33 @@
+package log
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "log [OPTIONS] ID",
+		Short:                 "Show the log of a background job",
+		Long: "This command shows the log of a background job. With --follow, it\n" +
+			"continues to show new log lines until the job finishes.",
+		RunE: CobraRunE,
+		Args: cobra.ExactArgs(1),
+	}
+	Follow bool
+
+	// pollInterval is how often the job is checked for new log lines
+	// when following.
+	pollInterval = time.Second
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVarP(&Follow, "follow", "f", false, "Show new log lines until the job finishes")
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -0,0 +1,27 @@
+package job
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/job/list"
+	"github.com/warewulf/warewulf/internal/app/wwctl/job/log"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "job COMMAND [OPTIONS]",
+		Short:                 "Warewulf background jobs",
+		Long:                  "Inspect background jobs, such as image imports and builds, started through the REST API",
+		Args:                  cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(log.GetCommand())
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
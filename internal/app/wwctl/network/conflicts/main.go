@@ -0,0 +1,35 @@
+package conflicts
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/ipam"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return err
+	}
+	assignments, err := ipam.Assignments(&registry)
+	if err != nil {
+		return err
+	}
+	conflicts := ipam.Conflicts(assignments)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("IP", "NODE", "FIELD")
+	for _, conflict := range conflicts {
+		for _, assignment := range conflict.Assignments {
+			t.AddLine(table.Prep([]string{conflict.IP.String(), assignment.Node, assignment.Field})...)
+		}
+	}
+	t.Print()
+	return fmt.Errorf("duplicate IP addresses: %d", len(conflicts))
+}
@@ -0,0 +1,23 @@
+package conflicts
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "conflicts [OPTIONS]",
+		Short:                 "List duplicate IP addresses",
+		Long: "This command lists IP addresses that are assigned to more than one node network\n" +
+			"device or IPMI interface, including addresses inherited from profiles. It exits\n" +
+			"with an error if any are found.",
+		RunE: CobraRunE,
+		Args: cobra.NoArgs,
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
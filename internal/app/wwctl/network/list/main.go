@@ -0,0 +1,57 @@
+package list
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/ipam"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	pools, err := ipam.Pools()
+	if err != nil {
+		return err
+	}
+	registry, err := node.New()
+	if err != nil {
+		return err
+	}
+	assignments, err := ipam.Assignments(&registry)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader("NAME", "SUBNET", "RANGE", "GATEWAY", "USED", "FREE")
+	for _, name := range names {
+		pool := pools[name]
+		used := make(map[string]bool)
+		for _, assignment := range pool.Assigned(assignments) {
+			used[assignment.IP.String()] = true
+		}
+		free := new(big.Int).Sub(pool.Size(), big.NewInt(int64(len(used))))
+		gateway := "--"
+		if pool.Gateway != nil {
+			gateway = pool.Gateway.String()
+		}
+		t.AddLine(table.Prep([]string{
+			name,
+			pool.Subnet.String(),
+			pool.Start.String() + "-" + pool.End.String(),
+			gateway,
+			big.NewInt(int64(len(used))).String(),
+			free.String(),
+		})...)
+	}
+	t.Print()
+	return nil
+}
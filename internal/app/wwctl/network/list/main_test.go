@@ -0,0 +1,60 @@
+package list
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Network_List(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/warewulf.conf", `
+address pools:
+  cluster:
+    subnet: 10.0.0.0/24
+    range start: 10.0.0.10
+    range end: 10.0.0.19
+    gateway: 10.0.0.1
+  bmc:
+    subnet: 10.1.0.0/24
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 10.0.0.10
+      ib:
+        ipaddr: 10.0.0.11
+    ipmi:
+      ipaddr: 10.1.0.5
+  n2:
+    network devices:
+      default:
+        ipaddr: 10.0.0.50
+`)
+	env.Configure()
+
+	baseCmd := GetCommand()
+	buf := new(bytes.Buffer)
+	baseCmd.SetOut(buf)
+	baseCmd.SetErr(buf)
+	baseCmd.SetArgs([]string{})
+	assert.NoError(t, baseCmd.Execute())
+
+	var lines [][]string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		lines = append(lines, strings.Fields(line))
+	}
+	assert.Equal(t, [][]string{
+		{"NAME", "SUBNET", "RANGE", "GATEWAY", "USED", "FREE"},
+		{"----", "------", "-----", "-------", "----", "----"},
+		{"bmc", "10.1.0.0/24", "10.1.0.0-10.1.0.255", "--", "1", "253"},
+		{"cluster", "10.0.0.0/24", "10.0.0.10-10.0.0.19", "10.0.0.1", "2", "8"},
+	}, lines)
+}
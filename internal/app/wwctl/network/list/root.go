@@ -0,0 +1,23 @@
+package list
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "list [OPTIONS]",
+		Short:                 "List address pools",
+		Long: "This command lists the address pools defined in warewulf.conf, along with the\n" +
+			"number of addresses in each pool that are assigned to nodes and that remain free.",
+		RunE:    CobraRunE,
+		Args:    cobra.NoArgs,
+		Aliases: []string{"ls"},
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
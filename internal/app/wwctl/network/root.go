@@ -0,0 +1,27 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/conflicts"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network/list"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "network COMMAND [OPTIONS]",
+		Short:                 "Warewulf address pools",
+		Long:                  "Inspect the address pools defined in warewulf.conf and the IP addresses assigned to nodes",
+		Args:                  cobra.NoArgs,
+	}
+)
+
+func init() {
+	baseCmd.AddCommand(list.GetCommand())
+	baseCmd.AddCommand(conflicts.GetCommand())
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -6,13 +6,55 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/ipam"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
+// prepare moves the values of flags that apply to a named network
+// device, disk, partition, or file system from their "UNDEF" placeholders
+// to the given name.
+func (vars *variables) prepare() error {
+	// remove the UNDEF network as all network values are assigned
+	// to this network
+	if !node.ObjectIsEmpty(vars.nodeConf.NetDevs["UNDEF"]) {
+		netDev := *vars.nodeConf.NetDevs["UNDEF"]
+		vars.nodeConf.NetDevs[vars.nodeAdd.Net] = &netDev
+		vars.nodeConf.NetDevs[vars.nodeAdd.Net].Tags = vars.nodeAdd.NetTagsAdd
+
+	}
+	delete(vars.nodeConf.NetDevs, "UNDEF")
+	if vars.nodeAdd.FsName != "" {
+		if !strings.HasPrefix(vars.nodeAdd.FsName, "/dev") {
+			if vars.nodeAdd.FsName == vars.nodeAdd.PartName {
+				vars.nodeAdd.FsName = "/dev/disk/by-partlabel/" + vars.nodeAdd.PartName
+			} else {
+				return fmt.Errorf("filesystems need to have a underlying blockdev")
+			}
+		}
+		fs := *vars.nodeConf.FileSystems["UNDEF"]
+		vars.nodeConf.FileSystems[vars.nodeAdd.FsName] = &fs
+	}
+	delete(vars.nodeConf.FileSystems, "UNDEF")
+	if vars.nodeAdd.DiskName != "" && vars.nodeAdd.PartName != "" {
+		prt := *vars.nodeConf.Disks["UNDEF"].Partitions["UNDEF"]
+		vars.nodeConf.Disks["UNDEF"].Partitions[vars.nodeAdd.PartName] = &prt
+		delete(vars.nodeConf.Disks["UNDEF"].Partitions, "UNDEF")
+		dsk := *vars.nodeConf.Disks["UNDEF"]
+		vars.nodeConf.Disks[vars.nodeAdd.DiskName] = &dsk
+	}
+	if (vars.nodeAdd.DiskName != "") != (vars.nodeAdd.PartName != "") {
+		return fmt.Errorf("partition and disk must be specified")
+	}
+	delete(vars.nodeConf.Disks, "UNDEF")
+	vars.nodeConf.Ipmi.Tags = vars.nodeAdd.IpmiTagsAdd
+	return nil
+}
+
 /*
 RunE needs a function of type func(*cobraCommand,[]string) err, but
 in order to avoid global variables which mess up testing a function of
@@ -20,44 +62,14 @@ the required type is returned
 */
 func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		// remove the UNDEF network as all network values are assigned
-		// to this network
-		if !node.ObjectIsEmpty(vars.nodeConf.NetDevs["UNDEF"]) {
-			netDev := *vars.nodeConf.NetDevs["UNDEF"]
-			vars.nodeConf.NetDevs[vars.nodeAdd.Net] = &netDev
-			vars.nodeConf.NetDevs[vars.nodeAdd.Net].Tags = vars.nodeAdd.NetTagsAdd
-
+		templated := templatedFlags(cmd)
+		if err := vars.prepare(); err != nil {
+			return err
 		}
-		delete(vars.nodeConf.NetDevs, "UNDEF")
-		if vars.nodeAdd.FsName != "" {
-			if !strings.HasPrefix(vars.nodeAdd.FsName, "/dev") {
-				if vars.nodeAdd.FsName == vars.nodeAdd.PartName {
-					vars.nodeAdd.FsName = "/dev/disk/by-partlabel/" + vars.nodeAdd.PartName
-				} else {
-					return fmt.Errorf("filesystems need to have a underlying blockdev")
-				}
-			}
-			fs := *vars.nodeConf.FileSystems["UNDEF"]
-			vars.nodeConf.FileSystems[vars.nodeAdd.FsName] = &fs
-		}
-		delete(vars.nodeConf.FileSystems, "UNDEF")
-		if vars.nodeAdd.DiskName != "" && vars.nodeAdd.PartName != "" {
-			prt := *vars.nodeConf.Disks["UNDEF"].Partitions["UNDEF"]
-			vars.nodeConf.Disks["UNDEF"].Partitions[vars.nodeAdd.PartName] = &prt
-			delete(vars.nodeConf.Disks["UNDEF"].Partitions, "UNDEF")
-			dsk := *vars.nodeConf.Disks["UNDEF"]
-			vars.nodeConf.Disks[vars.nodeAdd.DiskName] = &dsk
-		}
-		if (vars.nodeAdd.DiskName != "") != (vars.nodeAdd.PartName != "") {
-			return fmt.Errorf("partition and disk must be specified")
-		}
-		delete(vars.nodeConf.Disks, "UNDEF")
-		vars.nodeConf.Ipmi.Tags = vars.nodeAdd.IpmiTagsAdd
-		if len(vars.nodeConf.Profiles) == 0 {
-			if registry, err := node.New(); err == nil {
-				if _, err := registry.GetProfile("default"); err == nil {
-					vars.nodeConf.Profiles = []string{"default"}
-				}
+		var defaultProfile []string
+		if registry, err := node.New(); err == nil {
+			if _, err := registry.GetProfile("default"); err == nil {
+				defaultProfile = []string{"default"}
 			}
 		}
 		nodeDB, err := node.New()
@@ -67,17 +79,73 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
 		nodeArgs := hostlist.Expand(args)
 		changed := cmd.Flags().Changed
 		var ipv4, ipmiaddr net.IP
-		for _, a := range nodeArgs {
+		for i, a := range nodeArgs {
+			nodeVars := vars
+			if len(templated) > 0 {
+				if nodeVars, err = forNode(cmd, a, i); err != nil {
+					return err
+				}
+				if err := nodeVars.prepare(); err != nil {
+					return err
+				}
+			}
+			if len(nodeVars.nodeConf.Profiles) == 0 {
+				nodeVars.nodeConf.Profiles = defaultProfile
+			}
 			n, err := nodeDB.AddNode(a)
 			if err != nil {
 				return fmt.Errorf("failed to add node: %w", err)
 			}
-			n.UpdateFrom(&vars.nodeConf, changed)
-			if !changed("profile") && len(vars.nodeConf.Profiles) > 0 {
-				n.Profiles = vars.nodeConf.Profiles
+			n.UpdateFrom(&nodeVars.nodeConf, changed)
+			if !changed("profile") && len(nodeVars.nodeConf.Profiles) > 0 {
+				n.Profiles = nodeVars.nodeConf.Profiles
+			}
+			for key, val := range nodeVars.nodeAdd.TagsAdd {
+				if n.Tags == nil {
+					n.Tags = make(map[string]string)
+				}
+				n.Tags[key] = val
+			}
+			for key, val := range nodeVars.nodeAdd.AttrsAdd {
+				if n.Attributes == nil {
+					n.Attributes = make(map[string]string)
+				}
+				n.Attributes[key] = val
+			}
+			if err := n.ValidateAttributes(); err != nil {
+				return fmt.Errorf("node %s: %w", a, err)
+			}
+			if err := n.UpdateState(""); err != nil {
+				return fmt.Errorf("node %s: %w", a, err)
+			}
+			for key, val := range nodeVars.nodeAdd.IpmiTagsAdd {
+				if n.Ipmi == nil {
+					n.Ipmi = new(node.IpmiConf)
+				}
+				if n.Ipmi.Tags == nil {
+					n.Ipmi.Tags = make(map[string]string)
+				}
+				n.Ipmi.Tags[key] = val
+			}
+			for key, val := range nodeVars.nodeAdd.NetTagsAdd {
+				if n.NetDevs == nil {
+					n.NetDevs = make(map[string]*node.NetDev)
+				}
+				netDev, ok := n.NetDevs[nodeVars.nodeAdd.Net]
+				if !ok {
+					netDev = new(node.NetDev)
+					n.NetDevs[nodeVars.nodeAdd.Net] = netDev
+				}
+				if netDev.Tags == nil {
+					netDev.Tags = make(map[string]string)
+				}
+				netDev.Tags[key] = val
 			}
 			wwlog.Info("Added node: %s", a)
 			for _, dev := range n.NetDevs {
+				if templated["ipaddr"] {
+					break
+				}
 				if !ipv4.IsUnspecified() && ipv4 != nil {
 					ipv4 = util.IncrementIPv4(ipv4, 1)
 					wwlog.Verbose("Incremented IP addr to %s", ipv4)
@@ -86,7 +154,7 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
 					ipv4 = dev.Ipaddr
 				}
 			}
-			if n.Ipmi != nil {
+			if n.Ipmi != nil && !templated["ipmiaddr"] {
 				if !ipmiaddr.IsUnspecified() && ipmiaddr != nil {
 					ipmiaddr = util.IncrementIPv4(ipmiaddr, 1)
 					wwlog.Verbose("Incremented ipmi IP addr to %s", ipmiaddr)
@@ -96,9 +164,34 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) error {
 				}
 			}
 		}
+		allocations, err := ipam.Allocate(&nodeDB, nodeArgs)
+		if err != nil {
+			return fmt.Errorf("failed to allocate addresses: %w", err)
+		}
+		for _, allocation := range allocations {
+			wwlog.Info("Allocated %s from pool %s for %s %s", allocation.IP, allocation.Pool, allocation.Node, allocation.Field)
+		}
+		if conflicts, err := ipam.NodeConflicts(&nodeDB, nodeArgs); err != nil {
+			return err
+		} else if len(conflicts) > 0 {
+			for _, conflict := range conflicts {
+				for _, assignment := range conflict.Assignments {
+					wwlog.Error("%s is assigned to %s %s", conflict.IP, assignment.Node, assignment.Field)
+				}
+			}
+			return fmt.Errorf("duplicate IP addresses: %d", len(conflicts))
+		}
 		if err := nodeDB.Persist(); err != nil {
 			return fmt.Errorf("failed to persist new node: %w", err)
 		}
+		nodeChanges := make(map[string][]node.Change)
+		for _, id := range nodeArgs {
+			before := node.NewNode(id)
+			if nodePtr, err := nodeDB.GetNodeOnlyPtr(id); err == nil {
+				nodeChanges[id] = node.Diff(&before, nodePtr)
+			}
+		}
+		audit.LogCommandChanges(audit.EntityNode, "add", nodeChanges)
 		return warewulfd.DaemonReload()
 	}
 }
@@ -273,3 +273,243 @@ nodes:
 		})
 	}
 }
+
+func Test_Add_Pool(t *testing.T) {
+	warewulfConf := `
+address pools:
+  cluster:
+    subnet: 10.0.0.0/24
+    range start: 10.0.0.10
+    gateway: 10.0.0.1
+`
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		inDb    string
+		outDb   string
+	}{
+		{name: "allocate from profile pool",
+			args: []string{"--profile=default", "n[01-02]"},
+			inDb: `
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        pool: cluster
+nodes:
+  n00:
+    profiles: [default]
+    network devices:
+      default:
+        ipaddr: 10.0.0.10
+`,
+			outDb: `
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        pool: cluster
+nodes:
+  n00:
+    profiles: [default]
+    network devices:
+      default:
+        ipaddr: 10.0.0.10
+  n01:
+    profiles: [default]
+    network devices:
+      default:
+        ipaddr: 10.0.0.11
+        netmask: 255.255.255.0
+        gateway: 10.0.0.1
+  n02:
+    profiles: [default]
+    network devices:
+      default:
+        ipaddr: 10.0.0.12
+        netmask: 255.255.255.0
+        gateway: 10.0.0.1
+`},
+		{name: "undefined pool",
+			args:    []string{"--netpool=missing", "n01"},
+			wantErr: true,
+			inDb:    `nodes: {}`,
+			outDb: `
+nodeprofiles: {}
+nodes: {}`},
+		{name: "duplicate address",
+			args:    []string{"--ipaddr=10.0.0.10", "n01"},
+			wantErr: true,
+			inDb: `
+nodes:
+  n00:
+    network devices:
+      default:
+        ipaddr: 10.0.0.10
+`,
+			outDb: `
+nodeprofiles: {}
+nodes:
+  n00:
+    network devices:
+      default:
+        ipaddr: 10.0.0.10
+`},
+	}
+
+	warewulfd.SetNoDaemon()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/warewulf.conf", warewulfConf)
+			env.WriteFile("etc/warewulf/nodes.conf", tt.inDb)
+			env.Configure()
+
+			baseCmd := GetCommand()
+			baseCmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			baseCmd.SetOut(buf)
+			baseCmd.SetErr(buf)
+			err := baseCmd.Execute()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			config, configErr := node.New()
+			assert.NoError(t, configErr)
+			dumpBytes, _ := config.Dump()
+			assert.YAMLEq(t, tt.outDb, string(dumpBytes))
+		})
+	}
+}
+
+func Test_Add_Template(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		outDb   string
+	}{
+		{name: "tags",
+			args: []string{"--tagadd=rack=1", "--ipmitagadd=vendor=x", "--nettagadd=mtu=jumbo", "n01"},
+			outDb: `
+nodeprofiles: {}
+nodes:
+  n01:
+    tags:
+      rack: "1"
+    ipmi:
+      tags:
+        vendor: x
+    network devices:
+      default:
+        tags:
+          mtu: jumbo
+`},
+		{name: "templated addresses, tags, and comment",
+			args: []string{
+				"--ipaddr=10.0.1.{{index}}",
+				"--ipmiaddr=10.0.2.{{add index 100}}",
+				"--tagadd=rack={{div index 10}}",
+				"--comment={{name}} is {{position}}",
+				"n[09-11]"},
+			outDb: `
+nodeprofiles: {}
+nodes:
+  n09:
+    comment: n09 is 0
+    tags:
+      rack: "0"
+    ipmi:
+      ipaddr: 10.0.2.109
+    network devices:
+      default:
+        ipaddr: 10.0.1.9
+  n10:
+    comment: n10 is 1
+    tags:
+      rack: "1"
+    ipmi:
+      ipaddr: 10.0.2.110
+    network devices:
+      default:
+        ipaddr: 10.0.1.10
+  n11:
+    comment: n11 is 2
+    tags:
+      rack: "1"
+    ipmi:
+      ipaddr: 10.0.2.111
+    network devices:
+      default:
+        ipaddr: 10.0.1.11
+`},
+		{name: "templated and incremented addresses",
+			args: []string{"--ipaddr=10.0.1.1", "--ipmiaddr=10.0.2.{{index}}", "n[1-2]"},
+			outDb: `
+nodeprofiles: {}
+nodes:
+  n1:
+    ipmi:
+      ipaddr: 10.0.2.1
+    network devices:
+      default:
+        ipaddr: 10.0.1.1
+  n2:
+    ipmi:
+      ipaddr: 10.0.2.2
+    network devices:
+      default:
+        ipaddr: 10.0.1.2
+`},
+		{name: "invalid template",
+			args:    []string{"--ipaddr=10.0.1.{{index", "n01"},
+			wantErr: true,
+			outDb: `
+nodeprofiles: {}
+nodes: {}
+`},
+		{name: "rendered address is invalid",
+			args:    []string{"--ipaddr=10.0.1.{{add index 254}}", "n[01-02]"},
+			wantErr: true,
+			outDb: `
+nodeprofiles: {}
+nodes: {}
+`},
+		{name: "node name without index",
+			args:    []string{"--ipaddr=10.0.1.{{index}}", "head"},
+			wantErr: true,
+			outDb: `
+nodeprofiles: {}
+nodes: {}
+`},
+	}
+
+	warewulfd.SetNoDaemon()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/nodes.conf", `nodes: {}`)
+
+			baseCmd := GetCommand()
+			baseCmd.SetArgs(tt.args)
+			buf := new(bytes.Buffer)
+			baseCmd.SetOut(buf)
+			baseCmd.SetErr(buf)
+			err := baseCmd.Execute()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			config, configErr := node.New()
+			assert.NoError(t, configErr)
+			dumpBytes, _ := config.Dump()
+			assert.YAMLEq(t, tt.outDb, string(dumpBytes))
+		})
+	}
+}
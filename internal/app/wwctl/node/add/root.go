@@ -13,25 +13,41 @@ type variables struct {
 	nodeAdd  node.NodeConfAdd
 }
 
+func newVariables() *variables {
+	return &variables{nodeConf: node.NewNode("")}
+}
+
+// createFlags registers the flags that set vars on cmd.
+func (vars *variables) createFlags(cmd *cobra.Command) {
+	vars.nodeConf.CreateFlags(cmd)
+	vars.nodeAdd.CreateAddFlags(cmd)
+	flags.AddContainer(cmd, &(vars.nodeConf.ImageName))
+	flags.AddWwinit(cmd, &(vars.nodeConf.SystemOverlay))
+	flags.AddRuntime(cmd, &(vars.nodeConf.RuntimeOverlay))
+}
+
 // Returns the newly created command
 func GetCommand() *cobra.Command {
-	vars := variables{}
-	vars.nodeConf = node.NewNode("")
+	vars := newVariables()
 	baseCmd := &cobra.Command{
 		DisableFlagsInUseLine: true,
 		Use:                   "add [OPTIONS] NODENAME",
 		Short:                 "Add new node to Warewulf",
-		Long:                  "This command will add a new node named NODENAME to Warewulf.",
-		Aliases:               []string{"new", "create"},
-		RunE:                  CobraRunE(&vars),
-		Args:                  cobra.MinimumNArgs(1),
-		ValidArgsFunction:     cobra.FixedCompletions(nil, cobra.ShellCompDirectiveNoFileComp),
-	}
-	vars.nodeConf.CreateFlags(baseCmd)
-	vars.nodeAdd.CreateAddFlags(baseCmd)
-	flags.AddContainer(baseCmd, &(vars.nodeConf.ImageName))
-	flags.AddWwinit(baseCmd, &(vars.nodeConf.SystemOverlay))
-	flags.AddRuntime(baseCmd, &(vars.nodeConf.RuntimeOverlay))
+		Long: "This command will add a new node named NODENAME to Warewulf.\n\n" +
+			"NODENAME may be a hostlist, e.g. n[001-128], and the values of options may be\n" +
+			"templates which are rendered for each node. Templates may use {{index}}, the\n" +
+			"last number in the node name; {{name}}, the node name; {{position}}, the\n" +
+			"position of the node in the list, starting at 0; and the functions add, sub,\n" +
+			"mul, div, mod, and printf, e.g.\n\n" +
+			"  wwctl node add n[001-128] --ipaddr 10.0.1.{{index}} \\\n" +
+			"    --ipmiaddr 10.0.2.{{index}} --tagadd rack={{add (div (sub index 1) 32) 1}}",
+		Aliases:           []string{"new", "create"},
+		RunE:              CobraRunE(vars),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cobra.FixedCompletions(nil, cobra.ShellCompDirectiveNoFileComp),
+	}
+	vars.createFlags(baseCmd)
+	wrapTemplateFlags(baseCmd)
 	// register the command line completions
 	if err := baseCmd.RegisterFlagCompletionFunc("image", completions.Images); err != nil {
 		panic(err)
@@ -0,0 +1,138 @@
+package add
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// templateValue wraps the value of a command line flag so that its
+// arguments may be templates which are rendered separately for each node
+// being added, e.g. --ipaddr=10.0.1.{{index}}.
+type templateValue struct {
+	pflag.Value
+	values    []string
+	templated bool
+}
+
+func (v *templateValue) Set(value string) error {
+	v.values = append(v.values, value)
+	if strings.Contains(value, "{{") {
+		if _, err := parseTemplate(value); err != nil {
+			return err
+		}
+		v.templated = true
+	}
+	if v.templated {
+		return nil
+	}
+	return v.Value.Set(value)
+}
+
+// wrapTemplateFlags makes every flag of cmd accept templates.
+func wrapTemplateFlags(cmd *cobra.Command) {
+	wrap := func(f *pflag.Flag) {
+		f.Value = &templateValue{Value: f.Value}
+	}
+	cmd.Flags().VisitAll(wrap)
+	cmd.PersistentFlags().VisitAll(wrap)
+}
+
+// templatedFlags returns the names of the flags of cmd that were given a
+// template.
+func templatedFlags(cmd *cobra.Command) map[string]bool {
+	templated := make(map[string]bool)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if v, ok := f.Value.(*templateValue); ok && v.templated {
+			templated[f.Name] = true
+		}
+	})
+	return templated
+}
+
+// forNode returns new variables holding the flags of cmd with their
+// templates rendered for the node named name, which is at position in the
+// list of nodes being added.
+func forNode(cmd *cobra.Command, name string, position int) (*variables, error) {
+	nodeVars := newVariables()
+	scratch := &cobra.Command{}
+	nodeVars.createFlags(scratch)
+
+	var err error
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		v, ok := f.Value.(*templateValue)
+		if !ok || err != nil {
+			return
+		}
+		dest := scratch.Flags().Lookup(f.Name)
+		if dest == nil {
+			dest = scratch.PersistentFlags().Lookup(f.Name)
+		}
+		for _, value := range v.values {
+			var rendered string
+			if rendered, err = renderTemplate(value, name, position); err != nil {
+				err = fmt.Errorf("--%s: %w", f.Name, err)
+				return
+			}
+			if err = dest.Value.Set(rendered); err != nil {
+				err = fmt.Errorf("--%s for %s: %w", f.Name, name, err)
+				return
+			}
+		}
+	})
+	return nodeVars, err
+}
+
+var templateFuncs = template.FuncMap{
+	"index":    func() int { return 0 },
+	"name":     func() string { return "" },
+	"position": func() int { return 0 },
+	"add":      func(a, b int) int { return a + b },
+	"sub":      func(a, b int) int { return a - b },
+	"mul":      func(a, b int) int { return a * b },
+	"div":      func(a, b int) int { return a / b },
+	"mod":      func(a, b int) int { return a % b },
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("").Funcs(templateFuncs).Parse(text)
+}
+
+var lastNumber = regexp.MustCompile(`[0-9]+`)
+
+// nodeIndex returns the last number in a node name, e.g. 7 for n007.
+func nodeIndex(name string) (int, error) {
+	numbers := lastNumber.FindAllString(name, -1)
+	if len(numbers) == 0 {
+		return 0, fmt.Errorf("node name has no index: %s", name)
+	}
+	return strconv.Atoi(numbers[len(numbers)-1])
+}
+
+// renderTemplate renders text for the node named name, which is at position
+// in the list of nodes being added.
+func renderTemplate(text, name string, position int) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"index":    func() (int, error) { return nodeIndex(name) },
+		"name":     func() string { return name },
+		"position": func() int { return position },
+	})
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/bmc"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
@@ -12,6 +13,11 @@ import (
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) error {
+	if selected, err := flags.SelectNodes(args, Where); err != nil {
+		return err
+	} else {
+		args = selected
+	}
 	var returnErr error = nil
 
 	nodeDB, err := node.New()
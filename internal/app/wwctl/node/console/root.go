@@ -3,6 +3,7 @@ package console
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -11,12 +12,17 @@ var (
 		Use:                   "console [OPTIONS] NODENAME",
 		Short:                 "Connect to IPMI console",
 		Long:                  "Start a new IPMI console for NODENAME.",
-		Args:                  cobra.MinimumNArgs(1),
+		Args:                  flags.MinimumNodeArgs(1),
 		RunE:                  CobraRunE,
 		ValidArgsFunction:     completions.Nodes,
 	}
+	Where string
 )
 
+func init() {
+	flags.AddWhere(powerCmd, &Where)
+}
+
 // GetRootCommand returns the root cobra.Command for the application.
 func GetCommand() *cobra.Command {
 	return powerCmd
@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -13,6 +15,11 @@ import (
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) (err error) {
+	if selected, err := flags.SelectNodes(args, Where); err != nil {
+		return err
+	} else {
+		args = selected
+	}
 	nodeDB, err := node.New()
 	if err != nil {
 		return fmt.Errorf("failed to open node database: %w", err)
@@ -50,16 +57,21 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
+	var deleted []string
 	for _, n := range nodeList {
 		if err := nodeDB.DelNode(n.Id()); err != nil {
 			wwlog.Error("%s", err)
 		} else {
 			wwlog.Verbose("Deleting node: %s\n", n.Id())
+			deleted = append(deleted, n.Id())
 		}
 	}
 
 	if err := nodeDB.Persist(); err != nil {
 		return fmt.Errorf("failed to persist nodedb: %w", err)
 	}
+	for _, id := range deleted {
+		audit.LogCommand(audit.Entry{Entity: audit.EntityNode, ID: id, Operation: "delete"})
+	}
 	return warewulfd.DaemonReload()
 }
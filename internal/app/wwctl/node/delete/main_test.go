@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 )
@@ -15,6 +16,7 @@ func Test_Delete(t *testing.T) {
 		args    []string
 		inDB    string
 		outDB   string
+		deleted []string
 		wantErr bool
 	}{
 		{
@@ -37,6 +39,7 @@ nodes:
   n02:
     profiles:
     - default`,
+			deleted: []string{"n01"},
 		},
 		{
 			name: "delete multiple nodes",
@@ -61,6 +64,7 @@ nodes:
   n03:
     profiles:
     - default`,
+			deleted: []string{"n01", "n02"},
 		},
 		{
 			name: "delete non-existent node",
@@ -93,6 +97,7 @@ nodes:
 nodeprofiles:
   default: {}
 nodes: {}`,
+			deleted: []string{"n01", "n02"},
 		},
 	}
 
@@ -116,6 +121,14 @@ nodes: {}`,
 				content := env.ReadFile("etc/warewulf/nodes.conf")
 				assert.YAMLEq(t, tt.outDB, content)
 			}
+			entries, err := audit.Query(audit.Filter{Entity: audit.EntityNode})
+			assert.NoError(t, err)
+			var deleted []string
+			for _, entry := range entries {
+				assert.Equal(t, "delete", entry.Operation)
+				deleted = append(deleted, entry.ID)
+			}
+			assert.Equal(t, tt.deleted, deleted)
 		})
 	}
 }
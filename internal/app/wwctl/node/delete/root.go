@@ -3,6 +3,7 @@ package delete
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -11,19 +12,21 @@ var (
 		Use:                   "delete [OPTIONS] NODE [NODE ...]",
 		Short:                 "Delete a node from Warewulf",
 		Long:                  "This command will remove NODE(s) from the Warewulf node configuration.",
-		Args:                  cobra.MinimumNArgs(1),
+		Args:                  flags.MinimumNodeArgs(1),
 		RunE:                  CobraRunE,
 		Aliases:               []string{"rm", "del", "remove"},
 		ValidArgsFunction:     completions.Nodes,
 	}
 	SetYes   bool
 	SetForce bool // no hash checking, so always using force
+	Where    string
 )
 
 func init() {
 	SetForce = true
 	baseCmd.PersistentFlags().BoolVarP(&SetYes, "yes", "y", false, "Set 'yes' to all questions asked")
 
+	flags.AddWhere(baseCmd, &Where)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
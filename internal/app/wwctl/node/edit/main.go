@@ -1,12 +1,16 @@
 package edit
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -16,6 +20,11 @@ import (
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) error {
+	if selected, err := flags.SelectNodes(args, Where); err != nil {
+		return err
+	} else {
+		args = selected
+	}
 	if !node.CanWriteConfig() {
 		return fmt.Errorf("can not write to config: exiting")
 	}
@@ -44,7 +53,12 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	if tempErr != nil {
 		return fmt.Errorf("could not create temp file: %s", tempErr)
 	}
-	defer func() { _ = os.Remove(tempFile.Name()) }()
+	keepTempFile := false
+	defer func() {
+		if !keepTempFile {
+			_ = os.Remove(tempFile.Name())
+		}
+	}()
 
 	if !NoHeader {
 		yamlTemplate := node.ConfToYaml(node.Node{}, nil)
@@ -99,13 +113,23 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 					break
 				}
 			}
+			if err := checkAttributes(origNodes, editNodes); err != nil {
+				wwlog.Error("%v\n", err)
+				if util.Confirm("Invalid attributes: retry") {
+					continue
+				} else {
+					break
+				}
+			}
 
 			var added, deleted, updated int
+			var entries []audit.Entry
 			for nodeID := range origNodes {
 				if editNode, ok := editNodes[nodeID]; !ok || editNode == nil {
 					wwlog.Verbose("delete node: %s", nodeID)
 					delete(registry.Nodes, nodeID)
 					deleted += 1
+					entries = append(entries, audit.Entry{Entity: audit.EntityNode, ID: nodeID, Operation: "delete"})
 				}
 			}
 			for nodeID := range editNodes {
@@ -113,19 +137,32 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 					wwlog.Verbose("add node: %s", nodeID)
 					added += 1
 					registry.Nodes[nodeID] = editNodes[nodeID]
+					before := node.NewNode(nodeID)
+					entries = append(entries, audit.Entry{Entity: audit.EntityNode, ID: nodeID, Operation: "add",
+						Changes: node.Diff(&before, editNodes[nodeID])})
 				} else if equalYaml, err := util.EqualYaml(origNodes[nodeID], editNodes[nodeID]); err != nil {
 					return err
 				} else if !equalYaml {
 					wwlog.Verbose("update node: %s", nodeID)
 					updated += 1
 					registry.Nodes[nodeID] = editNodes[nodeID]
+					entries = append(entries, audit.Entry{Entity: audit.EntityNode, ID: nodeID, Operation: "update",
+						Changes: node.Diff(origNodes[nodeID], editNodes[nodeID])})
 				}
 			}
 
 			if util.Confirm(fmt.Sprintf("Are you sure you want to add %d, delete %d, and update %d nodes", added, deleted, updated)) {
-				if err := registry.Persist(); err != nil {
+				if err := registry.Persist(); errors.Is(err, node.ErrConflict) {
+					keepTempFile = true
+					return fmt.Errorf("node configuration was changed by another process while it was being edited; "+
+						"changes were not saved and remain in %s", tempFile.Name())
+				} else if err != nil {
 					return err
 				}
+				sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+				for _, entry := range entries {
+					audit.LogCommand(entry)
+				}
 
 				if err := warewulfd.DaemonReload(); err != nil {
 					return fmt.Errorf("failed to reload warewulf daemon: %w", err)
@@ -140,3 +177,30 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// checkAttributes validates the attributes of the nodes that were added or
+// changed in the editor against the attribute schema, as 'wwctl node set'
+// does.
+func checkAttributes(origNodes, editNodes map[string]*node.Node) error {
+	ids := make([]string, 0, len(editNodes))
+	for id := range editNodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var errs []error
+	for _, id := range ids {
+		editNode := editNodes[id]
+		if editNode == nil {
+			continue
+		}
+		if origNode, ok := origNodes[id]; ok {
+			if equalYaml, err := util.EqualYaml(origNode, editNode); err == nil && equalYaml {
+				continue
+			}
+		}
+		if err := editNode.ValidateAttributes(); err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
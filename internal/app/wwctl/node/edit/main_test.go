@@ -0,0 +1,35 @@
+package edit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_checkAttributes(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/warewulf.conf", `
+attributes:
+  rack:
+    type: int
+`)
+	env.Configure()
+
+	orig := map[string]*node.Node{
+		"n1": {Profile: node.Profile{Attributes: map[string]string{"slot": "1"}}},
+	}
+	// unchanged nodes are not checked again
+	assert.NoError(t, checkAttributes(orig, map[string]*node.Node{
+		"n1": {Profile: node.Profile{Attributes: map[string]string{"slot": "1"}}},
+		"n2": {Profile: node.Profile{Attributes: map[string]string{"rack": "12"}}},
+		"n3": nil,
+	}))
+	assert.EqualError(t, checkAttributes(orig, map[string]*node.Node{
+		"n1": {Profile: node.Profile{Attributes: map[string]string{"slot": "2"}}},
+		"n2": {Profile: node.Profile{Attributes: map[string]string{"rack": "notanint"}}},
+	}), "node n1: unknown attribute: slot\nnode n2: invalid value for attribute rack: not an integer: notanint")
+}
@@ -3,6 +3,7 @@ package edit
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -16,10 +17,12 @@ var (
 		Args:                  cobra.ArbitraryArgs,
 	}
 	NoHeader bool
+	Where    string
 )
 
 func init() {
 	baseCmd.PersistentFlags().BoolVar(&NoHeader, "noheader", false, "Do not print header")
+	flags.AddWhere(baseCmd, &Where)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -0,0 +1,96 @@
+package explain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	registry, err := node.New()
+	if err != nil {
+		return err
+	}
+	explanation, err := registry.Explain(args[0])
+	if errors.Is(err, node.ErrNotFound) {
+		return fmt.Errorf("node not found: %s", args[0])
+	} else if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		explanation.FilterFields(args[1])
+		if len(explanation.Fields) == 0 {
+			return fmt.Errorf("field not set for node %s: %s", args[0], args[1])
+		}
+	}
+
+	if ShowJson {
+		out, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+		return nil
+	}
+
+	for _, problem := range explanation.Problems {
+		wwlog.Warn("%s", problem)
+	}
+	w := cmd.OutOrStdout()
+	if len(args) == 1 {
+		fmt.Fprintf(w, "Node: %s (%s)\n", explanation.Node, explanation.File)
+		fmt.Fprintln(w, "Profiles:")
+		printProfiles(w, explanation.Profiles, 1)
+		fmt.Fprintf(w, "Merge order: %s\n\n", strings.Join(append(explanation.Order, explanation.Node), ", "))
+	}
+
+	t := table.New(w)
+	t.AddHeader("FIELD", "SOURCE", "VALUE", "NOTES")
+	for _, field := range explanation.Fields {
+		t.AddLine(table.Prep([]string{field.Field, field.Source, field.Value, notes(field)})...)
+	}
+	t.Print()
+	return nil
+}
+
+// printProfiles writes the profile graph as an indented tree.
+func printProfiles(w io.Writer, profiles []node.ProfileRef, depth int) {
+	for _, profile := range profiles {
+		label := profile.ID
+		switch {
+		case profile.Negated:
+			label = "~" + profile.ID + " (negated)"
+		case profile.Missing:
+			label += " (not found)"
+		case profile.Cycle:
+			label += " (cycle)"
+		case profile.Excluded:
+			label += " (excluded)"
+		case profile.Repeated:
+			label += " (see above)"
+		}
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), label)
+		printProfiles(w, profile.Profiles, depth+1)
+	}
+}
+
+// notes describes the overridden values and negated entries of a field.
+func notes(field node.FieldProvenance) string {
+	var notes []string
+	for _, definition := range field.Definitions {
+		if definition.Overridden {
+			notes = append(notes, fmt.Sprintf("overrides %s (%s)", definition.Source, definition.Value))
+		}
+	}
+	for _, negated := range field.Negated {
+		notes = append(notes, "negates "+negated)
+	}
+	return strings.Join(notes, "; ")
+}
@@ -0,0 +1,92 @@
+package explain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Explain(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		stdout  string
+		wantErr bool
+	}{
+		"node": {
+			args: []string{"n1"},
+			stdout: `Node: n1 (/etc/warewulf/nodes.conf)
+Profiles:
+  default
+    base
+  ~rack (negated)
+Merge order: base, default, n1
+
+FIELD          SOURCE   VALUE    NOTES
+-----          ------   -----    -----
+Profiles       n1       default  negates rack
+ImageName      n1       rocky10  overrides base (rocky8); overrides default (rocky9)
+SystemOverlay  base,n1  wwinit   negates extra
+`,
+		},
+		"field": {
+			args: []string{"n1", "imagename"},
+			stdout: `FIELD      SOURCE  VALUE    NOTES
+-----      ------  -----    -----
+ImageName  n1      rocky10  overrides base (rocky8); overrides default (rocky9)
+`,
+		},
+		"unset field": {
+			args:    []string{"n1", "comment"},
+			wantErr: true,
+		},
+		"missing node": {
+			args:    []string{"n2"},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  base:
+    image name: rocky8
+    system overlay:
+    - wwinit
+    - extra
+  default:
+    image name: rocky9
+    profiles:
+    - base
+nodes:
+  n1:
+    profiles:
+    - default
+    - ~rack
+    image name: rocky10
+    system overlay:
+    - ~extra
+`)
+
+			buf := new(bytes.Buffer)
+			baseCmd := GetCommand()
+			baseCmd.SetArgs(tt.args)
+			baseCmd.SetOut(buf)
+			baseCmd.SetErr(new(bytes.Buffer))
+			baseCmd.SilenceUsage = true
+			err := baseCmd.Execute()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			output := bytes.ReplaceAll(buf.Bytes(), []byte(env.BaseDir), nil)
+			assert.Equal(t, tt.stdout, string(output))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package explain
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "explain [OPTIONS] NODE [FIELD]",
+		Short:                 "Show where a node's settings come from",
+		Long: "This command shows the graph of profiles that NODE inherits, the order in which\n" +
+			"they are merged, and, for each effective field (or only for FIELD and the\n" +
+			"fields nested in it), the node or profile that sets it, the values that are\n" +
+			"overridden, and the list entries that are negated with \"~\". Missing profiles\n" +
+			"and profile cycles are reported as warnings.",
+		Example:           "wwctl node explain n1 SystemOverlay",
+		RunE:              CobraRunE,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completions.Nodes,
+	}
+	ShowJson bool
+)
+
+func init() {
+	baseCmd.PersistentFlags().BoolVarP(&ShowJson, "json", "j", false, "Show the explanation as JSON")
+}
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
@@ -2,6 +2,7 @@ package export
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -9,6 +10,11 @@ import (
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) error {
+	if selected, err := flags.SelectNodes(args, Where); err != nil {
+		return err
+	} else {
+		args = selected
+	}
 	registry, err := node.New()
 	if err != nil {
 		return err
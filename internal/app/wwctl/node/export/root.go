@@ -3,6 +3,7 @@ package export
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -16,8 +17,13 @@ var (
 		Args:                  cobra.ArbitraryArgs,
 	}
 	NoHeader bool
+	Where    string
 )
 
+func init() {
+	flags.AddWhere(baseCmd, &Where)
+}
+
 // GetRootCommand returns the root cobra.Command for the application.
 func GetCommand() *cobra.Command {
 	return baseCmd
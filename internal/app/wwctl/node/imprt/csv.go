@@ -0,0 +1,148 @@
+package imprt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"unicode"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+// csvNetDev is the network device configured by the hwaddr and ipaddr
+// columns, matching the default of 'wwctl node add --netname'.
+const csvNetDev = "default"
+
+var csvColumns = []string{"name", "hwaddr", "ipaddr", "ipmiaddr", "profile", "tags"}
+
+// parseCSV reads nodes from a CSV document with a header row naming its
+// columns. Every row is checked before an error is returned, so that all
+// problems with the document are reported at once.
+func parseCSV(data []byte) (map[string]*node.Node, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header row")
+	} else if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !util.InSlice(csvColumns, column) {
+			return nil, fmt.Errorf("unknown column: '%s' (valid columns: %s)", column, strings.Join(csvColumns, ", "))
+		}
+		if _, ok := columns[column]; ok {
+			return nil, fmt.Errorf("duplicate column: '%s'", column)
+		}
+		columns[column] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("missing column: 'name'")
+	}
+
+	nodes := make(map[string]*node.Node)
+	var errs []error
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rowErr := func(format string, a ...interface{}) {
+			errs = append(errs, fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, a...)))
+		}
+
+		name := field("name")
+		if name == "" {
+			rowErr("missing node name")
+			continue
+		}
+		if _, ok := nodes[name]; ok {
+			rowErr("duplicate node: %s", name)
+			continue
+		}
+		n := new(node.Node)
+
+		var netDev node.NetDev
+		if hwaddr := field("hwaddr"); hwaddr != "" {
+			if mac, err := net.ParseMAC(hwaddr); err != nil {
+				rowErr("invalid hwaddr: %s", hwaddr)
+			} else {
+				netDev.Hwaddr = mac.String()
+			}
+		}
+		if ipaddr := field("ipaddr"); ipaddr != "" {
+			if netDev.Ipaddr = net.ParseIP(ipaddr); netDev.Ipaddr == nil {
+				rowErr("invalid ipaddr: %s", ipaddr)
+			}
+		}
+		if netDev.Hwaddr != "" || netDev.Ipaddr != nil {
+			n.NetDevs = map[string]*node.NetDev{csvNetDev: &netDev}
+		}
+		if ipmiaddr := field("ipmiaddr"); ipmiaddr != "" {
+			if ip := net.ParseIP(ipmiaddr); ip == nil {
+				rowErr("invalid ipmiaddr: %s", ipmiaddr)
+			} else {
+				n.Ipmi = &node.IpmiConf{Ipaddr: ip}
+			}
+		}
+		n.Profiles = splitList(field("profile"))
+		for _, tag := range splitList(field("tags")) {
+			key, value, ok := strings.Cut(tag, "=")
+			if !ok || key == "" {
+				rowErr("invalid tag (use key=value): %s", tag)
+				continue
+			}
+			if n.Tags == nil {
+				n.Tags = make(map[string]string)
+			}
+			n.Tags[key] = value
+		}
+		nodes[name] = n
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nodes, nil
+}
+
+// setDefaultProfile assigns the default profile, if it exists, to the new
+// nodes of importMap that have no profile, as 'wwctl node add' does.
+func setDefaultProfile(nodeDB *node.NodesYaml, importMap map[string]*node.Node) {
+	if _, err := nodeDB.GetProfile("default"); err != nil {
+		return
+	}
+	for nodeName, n := range importMap {
+		if _, err := nodeDB.GetNodeOnlyPtr(nodeName); err == nil {
+			continue
+		}
+		if n != nil && len(n.Profiles) == 0 {
+			n.Profiles = []string{"default"}
+		}
+	}
+}
+
+// splitList splits a CSV field holding several values separated by ';',
+// ',', or whitespace.
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ',' || unicode.IsSpace(r)
+	})
+}
@@ -1,55 +1,200 @@
 package imprt
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/validate"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 	"gopkg.in/yaml.v3"
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) error {
-	file, err := os.Open(args[0])
+	buffer, err := os.ReadFile(args[0])
 	if err != nil {
-		return fmt.Errorf("could not open file: %s", err)
+		return fmt.Errorf("could not read: %s", err)
 	}
-	defer func() { _ = file.Close() }()
 
+	importFormat := format
+	if importFormat == "" {
+		importFormat = "yaml"
+		if strings.EqualFold(filepath.Ext(args[0]), ".csv") {
+			importFormat = "csv"
+		}
+	}
 	importMap := make(map[string]*node.Node)
-	buffer, err := io.ReadAll(file)
-	if err != nil {
-		return fmt.Errorf("could not read: %s", err)
+	switch importFormat {
+	case "yaml":
+		if err := yaml.Unmarshal(buffer, importMap); err != nil {
+			return fmt.Errorf("could not parse import file: %s", err)
+		}
+	case "csv":
+		if importMap, err = parseCSV(buffer); err != nil {
+			return fmt.Errorf("could not parse import file: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown import format: %s", importFormat)
 	}
 
-	err = yaml.Unmarshal(buffer, importMap)
+	nodeDB, err := node.New()
 	if err != nil {
-		return fmt.Errorf("could not parse import file: %s", err)
+		return fmt.Errorf("could not open NodeDB: %w", err)
+	}
+	if importFormat == "csv" {
+		setDefaultProfile(&nodeDB, importMap)
+	}
+	nodeChanges, err := applyImport(&nodeDB, importMap)
+	if err != nil {
+		return fmt.Errorf("no nodes were imported: %w", err)
+	}
+
+	summary := node.FormatChanges(nodeChanges)
+	if summary == "" {
+		wwlog.Info("No changes to apply.")
+		return nil
+	}
+	if dryRun {
+		wwlog.Output("%s", summary)
+		return checkImport(&nodeDB, importMap)
+	}
+	if err := checkImport(&nodeDB, importMap); err != nil {
+		return fmt.Errorf("no nodes were imported: %w", err)
+	}
+	if !setYes {
+		wwlog.Output("%s", summary)
+		if !util.Confirm(fmt.Sprintf("Apply these changes to %d node(s)?", len(nodeChanges))) {
+			wwlog.Info("No changes made!")
+			return nil
+		}
+	} else {
+		wwlog.Output("Applying following changes:\n%s", summary)
 	}
 
-	if setYes || util.Confirm(fmt.Sprintf("Are you sure you want to modify %d nodes", len(importMap))) {
-		nodeDB, err := node.New()
-		if err != nil {
-			return fmt.Errorf("could not open NodeDB: %w", err)
+	if err := nodeDB.Persist(); err != nil {
+		return fmt.Errorf("failed to persist nodedb: %w", err)
+	}
+	audit.LogCommandChanges(audit.EntityNode, "import", nodeChanges)
+	return warewulfd.DaemonReload()
+}
+
+// applyImport applies the imported nodes to nodeDB and returns the changes
+// made to each node. Every node is validated before an error is returned, so
+// that all problems with an import file are reported at once; nodeDB must not
+// be persisted if an error is returned.
+func applyImport(nodeDB *node.NodesYaml, importMap map[string]*node.Node) (map[string][]node.Change, error) {
+	nodeNames := make([]string, 0, len(importMap))
+	for nodeName := range importMap {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	var errs []error
+	nodeChanges := map[string][]node.Change{}
+	for _, nodeName := range nodeNames {
+		nodeData := importMap[nodeName]
+		if nodeName == "" || strings.ContainsAny(nodeName, " \t/") {
+			errs = append(errs, fmt.Errorf("invalid node name: '%s'", nodeName))
+			continue
 		}
-		for nodeName, nodeData := range importMap {
-			if _, err := nodeDB.GetNodeOnly(nodeName); err == node.ErrNotFound {
-				if _, err := nodeDB.AddNode(nodeName); err != nil {
-					return fmt.Errorf("couldn't add new node: %w", err)
-				}
-			}
-			if err := nodeDB.SetNode(nodeName, *nodeData); err != nil {
-				return fmt.Errorf("couldn't set node: %w", err)
+		if nodeData == nil {
+			nodeData = &node.Node{}
+		}
+		var before *node.Node
+		if nodePtr, err := nodeDB.GetNodeOnlyPtr(nodeName); err == nil {
+			before = nodePtr.Clone()
+		} else {
+			newNode := node.NewNode(nodeName)
+			before = &newNode
+			if _, err := nodeDB.AddNode(nodeName); err != nil {
+				errs = append(errs, fmt.Errorf("%s: couldn't add new node: %w", nodeName, err))
+				continue
 			}
 		}
-		if err := nodeDB.Persist(); err != nil {
-			return fmt.Errorf("failed to persist nodedb: %w", err)
+		before.Flatten()
+		if err := nodeDB.SetNode(nodeName, *nodeData); err != nil {
+			errs = append(errs, fmt.Errorf("%s: couldn't set node: %w", nodeName, err))
+			continue
+		}
+		nodePtr, _ := nodeDB.GetNodeOnlyPtr(nodeName)
+		if err := nodePtr.ValidateAttributes(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", nodeName, err))
+			continue
+		}
+		nodePtr.Flatten()
+		if ch := node.Diff(before, nodePtr); len(ch) > 0 {
+			nodeChanges[nodeName] = ch
 		}
-		return warewulfd.DaemonReload()
 	}
+	errs = append(errs, checkHwaddrs(nodeDB, importMap)...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nodeChanges, nil
+}
 
+// checkImport validates nodeDB with the imported nodes applied, and reports
+// the problems with the imported nodes, e.g., profiles that do not exist or
+// addresses that are already used by another node. An error is returned if
+// any of the problems is an error.
+func checkImport(nodeDB *node.NodesYaml, importMap map[string]*node.Node) error {
+	problems, err := validate.Registry(nodeDB)
+	if err != nil {
+		return fmt.Errorf("could not validate import: %w", err)
+	}
+	var imported []validate.Problem
+	for _, problem := range problems {
+		if _, ok := importMap[problem.ID]; ok && problem.Kind == "node" {
+			imported = append(imported, problem)
+			wwlog.Output("%s", problem)
+		}
+	}
+	if errCount := validate.Errors(imported); errCount > 0 {
+		return fmt.Errorf("%d errors, %d warnings in the imported nodes", errCount, len(imported)-errCount)
+	}
 	return nil
 }
+
+// checkHwaddrs returns an error for each hardware address that is used by
+// more than one node, where at least one of the nodes was imported.
+func checkHwaddrs(nodeDB *node.NodesYaml, importMap map[string]*node.Node) (errs []error) {
+	owners := make(map[string][]string)
+	for nodeName, nodePtr := range nodeDB.Nodes {
+		for _, netDev := range nodePtr.NetDevs {
+			if netDev == nil || netDev.Hwaddr == "" {
+				continue
+			}
+			hwaddr := strings.ToLower(netDev.Hwaddr)
+			if !util.InSlice(owners[hwaddr], nodeName) {
+				owners[hwaddr] = append(owners[hwaddr], nodeName)
+			}
+		}
+	}
+	hwaddrs := make([]string, 0, len(owners))
+	for hwaddr := range owners {
+		hwaddrs = append(hwaddrs, hwaddr)
+	}
+	sort.Strings(hwaddrs)
+	for _, hwaddr := range hwaddrs {
+		nodeNames := owners[hwaddr]
+		if len(nodeNames) < 2 {
+			continue
+		}
+		for _, nodeName := range nodeNames {
+			if _, ok := importMap[nodeName]; ok {
+				sort.Strings(nodeNames)
+				errs = append(errs, fmt.Errorf("hwaddr %s is used by multiple nodes: %s", hwaddr, strings.Join(nodeNames, ", ")))
+				break
+			}
+		}
+	}
+	return errs
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
 func Test_Node_Import(t *testing.T) {
@@ -17,6 +18,7 @@ func Test_Node_Import(t *testing.T) {
 		wantErr    bool
 		inDB       string
 		outDB      string
+		stdout     string
 	}{
 		"import new node": {
 			args: []string{"importFile"},
@@ -32,10 +34,12 @@ n1:
       ipaddr: 192.168.1.10`,
 			wantErr: false,
 			inDB: `
-nodeprofiles: {}
+nodeprofiles:
+  default: {}
 nodes: {}`,
 			outDB: `
-nodeprofiles: {}
+nodeprofiles:
+  default: {}
 nodes:
   n1:
     profiles:
@@ -46,6 +50,177 @@ nodes:
         hwaddr: c4:cb:e1:bb:dd:e9
         ipaddr: 192.168.1.10`,
 		},
+		"import csv": {
+			args: []string{"importFile", "--format", "csv"},
+			importFile: `name,hwaddr,ipaddr,ipmiaddr,profile,tags
+n1,C4:CB:E1:BB:DD:E9,192.168.1.10,192.168.2.10,default,rack=12;row=3
+n2,c4:cb:e1:bb:dd:ea,192.168.1.11,,,
+n3,c4:cb:e1:bb:dd:eb,192.168.1.12,,,`,
+			wantErr: false,
+			inDB: `
+nodeprofiles:
+  default: {}
+nodes:
+  n2:
+    comment: existing node`,
+			outDB: `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default
+    ipmi:
+      ipaddr: 192.168.2.10
+    network devices:
+      default:
+        hwaddr: c4:cb:e1:bb:dd:e9
+        ipaddr: 192.168.1.10
+    tags:
+      rack: "12"
+      row: "3"
+  n2:
+    comment: existing node
+    network devices:
+      default:
+        hwaddr: c4:cb:e1:bb:dd:ea
+        ipaddr: 192.168.1.11
+  n3:
+    profiles:
+    - default
+    network devices:
+      default:
+        hwaddr: c4:cb:e1:bb:dd:eb
+        ipaddr: 192.168.1.12`,
+		},
+		"dry run": {
+			args: []string{"importFile", "--dry-run"},
+			importFile: `
+n1:
+  comment: new comment`,
+			wantErr: false,
+			inDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    comment: old comment`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    comment: old comment`,
+			stdout: `n1:
+  comment: "old comment" → "new comment"`,
+		},
+		"dry run with missing profile": {
+			args: []string{"importFile", "--dry-run"},
+			importFile: `
+n2:
+  profiles:
+  - missing`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes:
+  n1: {}`,
+			stdout: "error: node n2: profile not found: missing",
+		},
+		"dry run with duplicate ipaddr": {
+			args: []string{"importFile", "--dry-run"},
+			importFile: `
+n2:
+  network devices:
+    default:
+      ipaddr: 192.168.1.10`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 192.168.1.10`,
+			stdout: "error: node n2: duplicate IP address 192.168.1.10 for node n2",
+		},
+		"import with missing profile": {
+			args: []string{"importFile"},
+			importFile: `
+n2:
+  profiles:
+  - missing`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes:
+  n1: {}`,
+			stdout: "error: node n2: profile not found: missing",
+		},
+		"import with attributes": {
+			args: []string{"importFile"},
+			importFile: `
+n1:
+  attributes:
+    rack: "12"`,
+			inDB: `
+nodeprofiles: {}
+nodes: {}`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    attributes:
+      rack: "12"`,
+		},
+		"import with undeclared attribute": {
+			args: []string{"importFile"},
+			importFile: `
+n1:
+  attributes:
+    slot: "1"`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes: {}`,
+		},
+		"import with invalid attribute value": {
+			args: []string{"importFile"},
+			importFile: `
+n1:
+  attributes:
+    rack: notanint`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes: {}`,
+		},
+		"invalid csv row": {
+			args: []string{"importFile", "--format", "csv"},
+			importFile: `name,hwaddr
+n1,c4:cb:e1:bb:dd:e9
+n2,not-a-mac`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes: {}`,
+		},
+		"duplicate hwaddr": {
+			args: []string{"importFile"},
+			importFile: `
+n2:
+  network devices:
+    eth0:
+      hwaddr: c4:cb:e1:bb:dd:e9
+n3:
+  comment: valid node`,
+			wantErr: true,
+			inDB: `
+nodeprofiles: {}
+nodes:
+  n1:
+    network devices:
+      eth0:
+        hwaddr: c4:cb:e1:bb:dd:e9`,
+		},
 	}
 
 	for name, tt := range tests {
@@ -59,23 +234,36 @@ nodes:
 			}
 			assert.NoError(t, os.Chdir(env.GetPath(".")))
 			env.WriteFile("./importFile", tt.importFile)
+			env.WriteFile("etc/warewulf/warewulf.conf", `
+attributes:
+  rack:
+    type: int
+`)
+			env.Configure()
 			env.WriteFile("etc/warewulf/nodes.conf", tt.inDB)
 			warewulfd.SetNoDaemon()
 
+			setYes, dryRun, format = false, false, ""
 			baseCmd := GetCommand()
 			args := append(tt.args, "--yes")
 			baseCmd.SetArgs(args)
 			buf := new(bytes.Buffer)
 			baseCmd.SetOut(buf)
 			baseCmd.SetErr(buf)
+			wwlog.SetLogWriterInfo(buf)
+			defer wwlog.SetLogWriterInfo(os.Stdout)
 			err := baseCmd.Execute()
+			content := env.ReadFile("etc/warewulf/nodes.conf")
 			if tt.wantErr {
 				assert.Error(t, err)
+				assert.YAMLEq(t, tt.inDB, content)
 			} else {
 				assert.NoError(t, err)
-				content := env.ReadFile("etc/warewulf/nodes.conf")
 				assert.YAMLEq(t, tt.outDB, content)
 			}
+			if tt.stdout != "" {
+				assert.Contains(t, buf.String(), tt.stdout)
+			}
 		})
 	}
 }
@@ -8,17 +8,26 @@ var (
 	baseCmd = &cobra.Command{
 		DisableFlagsInUseLine: true,
 		Use:                   "import [OPTIONS] FILE",
-		Short:                 "Import node(s) from yaml FILE",
-		Long:                  "This command imports all the nodes defined in a YAML file. It will overwrite nodes with same name.",
-		RunE:                  CobraRunE,
-		Args:                  cobra.ExactArgs(1),
-		Aliases:               []string{"import"},
+		Short:                 "Import node(s) from a YAML or CSV FILE",
+		Long: "This command imports all the nodes defined in a YAML or CSV file. It will overwrite\n" +
+			"nodes with same name. If any node in the file is invalid, no nodes are changed.\n\n" +
+			"A CSV file has a header row naming its columns, which may be any of: name (required),\n" +
+			"hwaddr, ipaddr, ipmiaddr, profile, and tags. The hwaddr and ipaddr columns configure the\n" +
+			"\"default\" network device; multiple profiles and key=value tags are separated by ';'.",
+		RunE:    CobraRunE,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"import"},
 	}
 	setYes bool
+	dryRun bool
+	format string
 )
 
 func init() {
 	baseCmd.PersistentFlags().BoolVarP(&setYes, "yes", "y", false, "Set 'yes' to all questions asked")
+	baseCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show the changes that would be made without applying them")
+	baseCmd.PersistentFlags().StringVar(&format, "format", "", "Format of FILE: yaml or csv (default: csv for *.csv files, otherwise yaml)")
+	_ = baseCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"yaml", "csv"}, cobra.ShellCompDirectiveNoFileComp))
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
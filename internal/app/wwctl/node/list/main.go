@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"gopkg.in/yaml.v3"
 
 	"github.com/warewulf/warewulf/internal/app/wwctl/table"
@@ -16,6 +17,11 @@ import (
 
 func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err error) {
 	return func(cmd *cobra.Command, args []string) (err error) {
+		if selected, err := flags.SelectNodes(args, vars.where); err != nil {
+			return err
+		} else {
+			args = selected
+		}
 		nodeDB, err := node.New()
 		if err != nil {
 			return
@@ -41,22 +47,20 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 			}
 			wwlog.Info(string(buf))
 		} else if vars.showAll {
-			t := table.New(cmd.OutOrStdout())
-			t.AddHeader("NODE", "FIELD", "PROFILE", "VALUE")
+			var rows [][]string
 			for _, n := range filtered {
 				if _, fields, err := nodeDB.MergeNode(n.Id()); err != nil {
 					wwlog.Error("unable to merge node %v: %v", n.Id(), err)
 					continue
 				} else {
 					for _, f := range fields.List(n) {
-						t.AddLine(table.Prep([]string{n.Id(), f.Field, f.Source, f.Value})...)
+						rows = append(rows, []string{n.Id(), f.Field, f.Source, f.Value})
 					}
 				}
 			}
-			t.Print()
+			printTable(cmd, vars, []interface{}{"NODE", "FIELD", "PROFILE", "VALUE"}, rows)
 		} else if vars.showIpmi {
-			t := table.New(cmd.OutOrStdout())
-			t.AddHeader("NODE", "IPMI IPADDR", "IPMI PORT", "IPMI USERNAME", "IPMI INTERFACE")
+			var rows [][]string
 			for _, n := range filtered {
 				ipaddr, port, username, iface := "", "", "", ""
 				if n.Ipmi != nil {
@@ -65,54 +69,65 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 					username = n.Ipmi.UserName
 					iface = n.Ipmi.Interface
 				}
-				t.AddLine(table.Prep([]string{n.Id(), ipaddr, port, username, iface})...)
+				rows = append(rows, []string{n.Id(), ipaddr, port, username, iface})
 			}
-			t.Print()
+			printTable(cmd, vars, []interface{}{"NODE", "IPMI IPADDR", "IPMI PORT", "IPMI USERNAME", "IPMI INTERFACE"}, rows)
 		} else if vars.showNet {
-			t := table.New(cmd.OutOrStdout())
-			t.AddHeader("NODE", "NETWORK", "HWADDR", "IPADDR", "GATEWAY", "DEVICE")
+			var rows [][]string
 			for _, n := range filtered {
 				if len(n.NetDevs) > 0 {
 					for name := range n.NetDevs {
-						t.AddLine(table.Prep([]string{n.Id(), name,
+						rows = append(rows, []string{n.Id(), name,
 							n.NetDevs[name].Hwaddr,
 							n.NetDevs[name].Ipaddr.String(),
 							n.NetDevs[name].Gateway.String(),
-							n.NetDevs[name].Device})...)
+							n.NetDevs[name].Device})
 					}
 				} else {
-					t.AddLine(table.Prep([]string{n.Id(), "", "", "", "", ""})...)
+					rows = append(rows, []string{n.Id(), "", "", "", "", ""})
 				}
 			}
-			t.Print()
+			printTable(cmd, vars, []interface{}{"NODE", "NETWORK", "HWADDR", "IPADDR", "GATEWAY", "DEVICE"}, rows)
 		} else if vars.showLong {
-			t := table.New(cmd.OutOrStdout())
-			t.AddHeader("NODE NAME", "KERNEL VERSION", "IMAGE", "OVERLAYS (S/R)")
+			var rows [][]string
 			for _, n := range filtered {
 				kernelVersion := ""
 				if n.Kernel != nil {
 					kernelVersion = n.Kernel.Version
 				}
-				t.AddLine(table.Prep([]string{n.Id(),
+				rows = append(rows, []string{n.Id(),
 					kernelVersion,
 					n.ImageName,
-					strings.Join(n.SystemOverlay, ",") + "/" + strings.Join(n.RuntimeOverlay, ",")})...)
+					strings.Join(n.SystemOverlay, ",") + "/" + strings.Join(n.RuntimeOverlay, ",")})
 			}
-			t.Print()
+			printTable(cmd, vars, []interface{}{"NODE NAME", "KERNEL VERSION", "IMAGE", "OVERLAYS (S/R)"}, rows)
 		} else {
 			// Simple (default)
-			t := table.New(cmd.OutOrStdout())
-			t.AddHeader("NODE NAME", "PROFILES", "NETWORK")
+			var rows [][]string
 			for _, n := range filtered {
 				var netNames []string
 				for k := range n.NetDevs {
 					netNames = append(netNames, k)
 				}
 				sort.Strings(netNames)
-				t.AddLine(table.Prep([]string{n.Id(), strings.Join(n.Profiles, ","), strings.Join(netNames, ", ")})...)
+				rows = append(rows, []string{n.Id(), strings.Join(n.Profiles, ","), strings.Join(netNames, ", ")})
 			}
-			t.Print()
+			printTable(cmd, vars, []interface{}{"NODE NAME", "PROFILES", "NETWORK"}, rows)
 		}
 		return
 	}
 }
+
+// printTable prints rows under header, merging rows that differ only by
+// node name if requested.
+func printTable(cmd *cobra.Command, vars *variables, header []interface{}, rows [][]string) {
+	if vars.compress {
+		rows = table.CompressRows(rows)
+	}
+	t := table.New(cmd.OutOrStdout())
+	t.AddHeader(header...)
+	for _, row := range rows {
+		t.AddLine(table.Prep(row)...)
+	}
+	t.Print()
+}
@@ -546,6 +546,104 @@ nodes:
       default:
         hwaddr: aa:bb:cc:dd:ee:ff
         ipaddr: 1.1.1.1
+`,
+		},
+		{
+			name:    "node list where",
+			args:    []string{"--where", "profile=gpu"},
+			wantErr: false,
+			stdout: `
+NODE NAME  PROFILES     NETWORK
+---------  --------     -------
+n02        default,gpu  --
+n03        default,gpu  --
+`,
+			inDb: `nodeprofiles:
+  default: {}
+  gpu: {}
+nodes:
+  n01:
+    profiles:
+    - default
+    tags:
+      rack: "12"
+  n02:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "12"
+  n03:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "13"
+`,
+		},
+		{
+			name:    "node list where with pattern",
+			args:    []string{"--where", "tag.rack=12", "n0[2-3]"},
+			wantErr: false,
+			stdout: `
+NODE NAME  PROFILES     NETWORK
+---------  --------     -------
+n02        default,gpu  --
+`,
+			inDb: `nodeprofiles:
+  default: {}
+  gpu: {}
+nodes:
+  n01:
+    profiles:
+    - default
+    tags:
+      rack: "12"
+  n02:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "12"
+  n03:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "13"
+`,
+		},
+		{
+			name:    "node list compress",
+			args:    []string{"--compress"},
+			wantErr: false,
+			stdout: `
+NODE NAME  PROFILES     NETWORK
+---------  --------     -------
+n01        default      --
+n[02-03]   default,gpu  --
+`,
+			inDb: `nodeprofiles:
+  default: {}
+  gpu: {}
+nodes:
+  n01:
+    profiles:
+    - default
+    tags:
+      rack: "12"
+  n02:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "12"
+  n03:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "13"
 `,
 		},
 	}
@@ -3,6 +3,7 @@ package list
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 )
 
@@ -13,6 +14,8 @@ type variables struct {
 	showLong bool
 	showYaml bool
 	showJson bool
+	compress bool
+	where    string
 }
 
 func GetCommand() *cobra.Command {
@@ -22,7 +25,7 @@ func GetCommand() *cobra.Command {
 		Use:                   "list [OPTIONS] [PATTERN]",
 		Short:                 "List nodes",
 		Long: "This command lists all configured nodes. Optionally, it will list only\n" +
-			"nodes matching a PATTERN.\n" + hostlist.Docstring,
+			"nodes matching a PATTERN.\n" + hostlist.Docstring + "\n" + flags.WhereDocstring,
 		RunE:              CobraRunE(&vars),
 		Aliases:           []string{"ls"},
 		ValidArgsFunction: completions.Nodes,
@@ -34,6 +37,8 @@ func GetCommand() *cobra.Command {
 	baseCmd.PersistentFlags().BoolVarP(&vars.showLong, "long", "l", false, "Show long or wide format")
 	baseCmd.PersistentFlags().BoolVarP(&vars.showYaml, "yaml", "y", false, "Show yaml format")
 	baseCmd.PersistentFlags().BoolVarP(&vars.showJson, "json", "j", false, "Show json format")
+	baseCmd.PersistentFlags().BoolVarP(&vars.compress, "compress", "c", false, "Merge nodes with identical output into a node range")
+	flags.AddWhere(baseCmd, &vars.where)
 
 	return baseCmd
 }
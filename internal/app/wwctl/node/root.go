@@ -6,6 +6,7 @@ import (
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/console"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/delete"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/edit"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/explain"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/export"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/imprt"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/list"
@@ -13,6 +14,7 @@ import (
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/set"
 	nodestatus "github.com/warewulf/warewulf/internal/app/wwctl/node/status"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node/unset"
+	"github.com/warewulf/warewulf/internal/app/wwctl/node/validate"
 )
 
 var (
@@ -40,6 +42,8 @@ func init() {
 	baseCmd.AddCommand(edit.GetCommand())
 	baseCmd.AddCommand(imprt.GetCommand())
 	baseCmd.AddCommand(export.GetCommand())
+	baseCmd.AddCommand(validate.GetCommand())
+	baseCmd.AddCommand(explain.GetCommand())
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/batch"
 	"github.com/warewulf/warewulf/internal/pkg/bmc"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
@@ -15,6 +16,11 @@ import (
 
 func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err error) {
 	return func(cmd *cobra.Command, args []string) error {
+		if selected, err := flags.SelectNodes(args, vars.Where); err != nil {
+			return err
+		} else {
+			args = selected
+		}
 		var returnErr error = nil
 
 		nodeDB, err := node.New()
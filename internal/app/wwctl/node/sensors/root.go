@@ -3,6 +3,7 @@ package sensors
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 )
 
@@ -10,6 +11,7 @@ type variables struct {
 	Showcmd bool
 	Full    bool
 	Fanout  int
+	Where   string
 }
 
 func GetCommand() *cobra.Command {
@@ -20,12 +22,13 @@ func GetCommand() *cobra.Command {
 		Use:                   "sensors [OPTIONS] PATTERN",
 		Short:                 "Show node IPMI sensor information",
 		Long:                  "Show IPMI sensor information for nodes matching PATTERN.\n" + hostlist.Docstring,
-		Args:                  cobra.MinimumNArgs(1),
+		Args:                  flags.MinimumNodeArgs(1),
 		RunE:                  CobraRunE(&vars),
 		ValidArgsFunction:     completions.Nodes,
 	}
 	powerCmd.PersistentFlags().BoolVarP(&vars.Full, "full", "F", false, "show detailed output.")
 	powerCmd.PersistentFlags().BoolVarP(&vars.Showcmd, "show", "s", false, "only show command which will be executed")
 	powerCmd.PersistentFlags().IntVar(&vars.Fanout, "fanout", 50, "how many command should be executed in parallel")
+	flags.AddWhere(powerCmd, &vars.Where)
 	return powerCmd
 }
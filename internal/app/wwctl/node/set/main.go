@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -14,6 +16,11 @@ import (
 
 func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err error) {
 	return func(cmd *cobra.Command, args []string) error {
+		if selected, err := flags.SelectNodes(args, vars.where); err != nil {
+			return err
+		} else {
+			args = selected
+		}
 		// remove the default network as the all network values are assigned
 		// to this network
 		if !node.ObjectIsEmpty(vars.nodeConf.NetDevs["UNDEF"]) || len(vars.nodeAdd.NetTagsAdd) > 0 {
@@ -133,6 +140,18 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 				}
 				nodePtr.Tags[key] = val
 			}
+			for _, key := range vars.nodeDel.AttrsDel {
+				delete(nodePtr.Attributes, key)
+			}
+			for key, val := range vars.nodeAdd.AttrsAdd {
+				if nodePtr.Attributes == nil {
+					nodePtr.Attributes = make(map[string]string)
+				}
+				nodePtr.Attributes[key] = val
+			}
+			if err := nodePtr.ValidateAttributes(); err != nil {
+				return fmt.Errorf("node %s: %w", nId, err)
+			}
 			for key, val := range vars.nodeAdd.IpmiTagsAdd {
 				if nodePtr.Ipmi.Tags == nil {
 					nodePtr.Ipmi.Tags = make(map[string]string)
@@ -154,6 +173,9 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 				}
 			}
 			nodePtr.Flatten()
+			if err := nodePtr.UpdateState(before.State); err != nil {
+				return fmt.Errorf("node %s: %w", nId, err)
+			}
 			if before != nil {
 				if ch := node.Diff(before, nodePtr); len(ch) > 0 {
 					nodeChanges[nId] = ch
@@ -179,6 +201,7 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 		if err := nodeDB.Persist(); err != nil {
 			return err
 		}
+		audit.LogCommandChanges(audit.EntityNode, "set", nodeChanges)
 		return warewulfd.DaemonReload()
 	}
 }
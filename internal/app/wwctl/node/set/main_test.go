@@ -736,6 +736,93 @@ nodes:
     tags:
       email: node
       newtag: newval`,
+		},
+		"--attr": {
+			args: []string{"--attr=rack=12", "--attr=role=compute", "n01"},
+			inDB: `
+nodes:
+  n01:
+    attributes:
+      gpus: "2"`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n01:
+    attributes:
+      gpus: "2"
+      rack: "12"
+      role: compute`,
+		},
+		"--attrdel": {
+			args: []string{"--attrdel=gpus", "n01"},
+			inDB: `
+nodes:
+  n01:
+    attributes:
+      gpus: "2"
+      rack: "12"`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n01:
+    attributes:
+      rack: "12"`,
+		},
+		"--attr with invalid value": {
+			args:    []string{"--attr=rack=twelve", "n01"},
+			wantErr: true,
+			inDB: `
+nodes:
+  n01: {}`,
+		},
+		"--attr with disallowed value": {
+			args:    []string{"--attr=role=storage", "n01"},
+			wantErr: true,
+			inDB: `
+nodes:
+  n01: {}`,
+		},
+		"--attr undeclared": {
+			args:    []string{"--attr=color=blue", "n01"},
+			wantErr: true,
+			inDB: `
+nodes:
+  n01: {}`,
+		},
+		"--reason keeps the state timestamp": {
+			args: []string{"--reason=replacing DIMM", "n01"},
+			inDB: `
+nodes:
+  n01:
+    state: maintenance
+    state changed: "2026-01-02T03:04:05Z"`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n01:
+    state: maintenance
+    state reason: replacing DIMM
+    state changed: "2026-01-02T03:04:05Z"`,
+		},
+		"--state=UNSET": {
+			args: []string{"--state=UNSET", "n01"},
+			inDB: `
+nodes:
+  n01:
+    state: maintenance
+    state reason: replacing DIMM
+    state changed: "2026-01-02T03:04:05Z"`,
+			outDB: `
+nodeprofiles: {}
+nodes:
+  n01: {}`,
+		},
+		"--state invalid": {
+			args:    []string{"--state=broken", "n01"},
+			wantErr: true,
+			inDB: `
+nodes:
+  n01: {}`,
 		},
 		"--image=UNDEF": {
 			args:    []string{"--image=UNDEF", "n1"},
@@ -822,6 +909,16 @@ nodes:
 		t.Run(name, func(t *testing.T) {
 			env := testenv.New(t)
 			defer env.RemoveAll()
+			env.WriteFile("etc/warewulf/warewulf.conf", `
+attributes:
+  rack:
+    type: int
+  gpus:
+    type: int
+  role:
+    values: [compute, login]
+`)
+			env.Configure()
 			env.WriteFile("etc/warewulf/nodes.conf", tt.inDB)
 			warewulfd.SetNoDaemon()
 
@@ -12,6 +12,7 @@ type variables struct {
 	setNodeAll bool
 	setYes     bool
 	setForce   bool
+	where      string
 	nodeConf   node.Node
 	nodeDel    node.NodeConfDel
 	nodeAdd    node.NodeConfAdd
@@ -37,6 +38,7 @@ func GetCommand() *cobra.Command {
 	flags.AddContainer(baseCmd, &(vars.nodeConf.ImageName))
 	flags.AddWwinit(baseCmd, &(vars.nodeConf.SystemOverlay))
 	flags.AddRuntime(baseCmd, &(vars.nodeConf.RuntimeOverlay))
+	flags.AddWhere(baseCmd, &vars.where)
 	baseCmd.PersistentFlags().BoolVarP(&vars.setNodeAll, "all", "a", false, "Set all nodes")
 	baseCmd.PersistentFlags().BoolVarP(&vars.setYes, "yes", "y", false, "Set 'yes' to all questions asked")
 	baseCmd.PersistentFlags().BoolVarP(&vars.setForce, "force", "f", false, "Force configuration (even on error)")
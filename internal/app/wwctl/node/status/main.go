@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -26,6 +28,22 @@ type nodeStatus struct {
 	Lastseen int64  `json:"last seen"`
 }
 
+type nodeEvent struct {
+	Time     int64  `json:"time"`
+	Stage    string `json:"stage"`
+	Sent     string `json:"sent"`
+	Bytes    int64  `json:"bytes"`
+	Duration int64  `json:"duration ms"`
+	Status   int    `json:"status"`
+	Ipaddr   string `json:"ipaddr"`
+	Error    string `json:"error"`
+}
+
+type nodeHistory struct {
+	nodeStatus
+	Events []nodeEvent `json:"events"`
+}
+
 func displayStage(stage string) string {
 	switch stage {
 	case "efiboot":
@@ -84,6 +102,11 @@ func statusURL(controller *warewulfconf.WarewulfYaml) (string, error) {
 }
 
 func CobraRunE(cmd *cobra.Command, args []string) (err error) {
+	if selected, err := flags.SelectNodes(args, SetWhere); err != nil {
+		return err
+	} else {
+		args = selected
+	}
 	controller := warewulfconf.Get()
 
 	endpoint, err := statusURL(controller)
@@ -93,119 +116,231 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 
 	client := &http.Client{Timeout: statusHTTPTimeout}
 
-	for {
-		var elipsis bool
-		var height int
-		var count int
-		rightnow := time.Now().Unix()
+	if SetHistory {
+		if len(args) == 0 {
+			return fmt.Errorf("--history requires at least one node name")
+		}
+		return showHistory(client, endpoint, hostlist.Expand(args))
+	}
 
-		wwlog.Verbose("Connecting to: %s", endpoint)
+	if SetWatch {
+		err = watchStream(endpoint, args, controller)
+		if err != errStreamUnsupported {
+			return err
+		}
+		wwlog.Verbose("Status stream not available, polling %s", endpoint)
+	}
 
-		resp, err := client.Get(endpoint)
+	for {
+		wwlog.Verbose("Connecting to: %s", endpoint)
+		nodes, err := fetchStatus(client, endpoint)
 		if err != nil {
-			return fmt.Errorf("could not connect to Warewulf server: %w", err)
+			return err
 		}
 
-		var wwNodeStatus struct {
-			Nodes map[string]*nodeStatus `json:"nodes"`
+		printStatus(nodes, args, controller)
+
+		if !SetWatch {
+			break
 		}
-		err = json.NewDecoder(resp.Body).Decode(&wwNodeStatus)
-		_ = resp.Body.Close()
+		time.Sleep(time.Duration(SetUpdate) * time.Millisecond)
+	}
+	return nil
+}
+
+// fetchStatus retrieves the status of all nodes from warewulfd.
+func fetchStatus(client *http.Client, endpoint string) (map[string]*nodeStatus, error) {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to Warewulf server: %w", err)
+	}
+
+	var wwNodeStatus struct {
+		Nodes map[string]*nodeStatus `json:"nodes"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&wwNodeStatus)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode JSON: %w", err)
+	}
+	return wwNodeStatus.Nodes, nil
+}
+
+// printStatus displays the status of the selected nodes. In watch mode the
+// screen is cleared first and output is limited to the terminal height.
+func printStatus(nodes map[string]*nodeStatus, args []string, controller *warewulfconf.WarewulfYaml) {
+	var elipsis bool
+	var height int
+	var count int
+	var err error
+	rightnow := time.Now().Unix()
+
+	if SetWatch {
+		fmt.Print("\033[H\033[2J")
+		_, height, err = term.GetSize(0)
 		if err != nil {
-			return fmt.Errorf("could not decode JSON: %w", err)
+			wwlog.Warn("Could not get terminal height, using 24")
+			height = 24
 		}
+	}
 
-		if SetWatch {
-			fmt.Print("\033[H\033[2J")
-			_, height, err = term.GetSize(0)
-			if err != nil {
-				wwlog.Warn("Could not get terminal height, using 24")
-				height = 24
+	fmt.Printf("%-20s %-20s %-25s %-10s\n", "NODENAME", "STAGE", "SENT", "LASTSEEN (s)")
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+
+	wwlog.Verbose("Building sort index")
+	var statuses []*nodeStatus
+	if len(args) > 0 {
+		nodeList := hostlist.Expand(args)
+		for _, v := range nodes {
+			for _, name := range nodeList {
+				if v.NodeName == name {
+					statuses = append(statuses, v)
+					break
+				}
 			}
 		}
+	} else {
+		for _, v := range nodes {
+			statuses = append(statuses, v)
+		}
+	}
+
+	wwlog.Verbose("Sorting index")
+	if SetSortLast {
+		sort.Slice(statuses, func(i, j int) bool {
+			if statuses[i].Lastseen > statuses[j].Lastseen {
+				return true
+			} else if statuses[i].Lastseen < statuses[j].Lastseen {
+				return false
+			} else {
+				return statuses[i].NodeName < statuses[j].NodeName
+			}
+		})
+	} else if SetSortReverse {
+		wwlog.Verbose("Reversing sort order")
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].NodeName > statuses[j].NodeName
+		})
 
-		fmt.Printf("%-20s %-20s %-25s %-10s\n", "NODENAME", "STAGE", "SENT", "LASTSEEN (s)")
-		fmt.Printf("%s\n", strings.Repeat("=", 80))
+	} else {
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].NodeName < statuses[j].NodeName
+		})
+	}
 
-		wwlog.Verbose("Building sort index")
-		var statuses []*nodeStatus
-		if len(args) > 0 {
-			nodeList := hostlist.Expand(args)
-			for _, v := range wwNodeStatus.Nodes {
-				for _, name := range nodeList {
-					if v.NodeName == name {
-						statuses = append(statuses, v)
-						break
-					}
-				}
+	var shown []*nodeStatus
+	for _, o := range statuses {
+		if SetTime > 0 && o.Lastseen < SetTime {
+			continue
+		}
+		if o.Lastseen > 0 && SetUnknown {
+			continue
+		}
+		shown = append(shown, o)
+	}
+	if SetCompress {
+		shown = compressStatus(shown)
+	}
+	statuses = shown
+
+	wwlog.Verbose("Printing results")
+	for i := 0; i < len(statuses); i++ {
+		o := statuses[i]
+		if o.Lastseen > 0 {
+			if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval*2) {
+				color.Red("%-20s %-20s %-25s %-10d\n", o.NodeName, displayStage(o.Stage), o.Sent, rightnow-o.Lastseen)
+			} else if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval+5) {
+				color.Yellow("%-20s %-20s %-25s %-10d\n", o.NodeName, displayStage(o.Stage), o.Sent, rightnow-o.Lastseen)
+			} else {
+				fmt.Printf("%-20s %-20s %-25s %-10d\n", o.NodeName, displayStage(o.Stage), o.Sent, rightnow-o.Lastseen)
 			}
 		} else {
-			for _, v := range wwNodeStatus.Nodes {
-				statuses = append(statuses, v)
+			color.HiBlack("%-20s %-20s %-25s %-10s\n", o.NodeName, "--", "--", "--")
+		}
+		if count+4 >= height && SetWatch {
+			if count+1 != len(statuses) {
+				elipsis = true
 			}
+			break
 		}
+		count++
+	}
 
-		wwlog.Verbose("Sorting index")
-		if SetSortLast {
-			sort.Slice(statuses, func(i, j int) bool {
-				if statuses[i].Lastseen > statuses[j].Lastseen {
-					return true
-				} else if statuses[i].Lastseen < statuses[j].Lastseen {
-					return false
-				} else {
-					return statuses[i].NodeName < statuses[j].NodeName
-				}
-			})
-		} else if SetSortReverse {
-			wwlog.Verbose("Reversing sort order")
-			sort.Slice(statuses, func(i, j int) bool {
-				return statuses[i].NodeName > statuses[j].NodeName
-			})
+	if SetWatch && elipsis {
+		fmt.Printf("... ")
+	}
+}
 
-		} else {
-			sort.Slice(statuses, func(i, j int) bool {
-				return statuses[i].NodeName < statuses[j].NodeName
-			})
+// compressStatus merges nodes at the same stage that were last sent the
+// same file into a single entry named by their node range. The entry is
+// last seen when the least recently seen of its nodes was.
+func compressStatus(statuses []*nodeStatus) []*nodeStatus {
+	var keys []string
+	names := make(map[string][]string)
+	merged := make(map[string]*nodeStatus)
+	for _, o := range statuses {
+		key := fmt.Sprintf("%s\x00%s\x00%t", o.Stage, o.Sent, o.Lastseen > 0)
+		if entry, ok := merged[key]; !ok {
+			entry := *o
+			merged[key] = &entry
+			keys = append(keys, key)
+		} else if o.Lastseen < entry.Lastseen {
+			entry.Lastseen = o.Lastseen
 		}
+		names[key] = append(names[key], o.NodeName)
+	}
+	compressed := make([]*nodeStatus, 0, len(keys))
+	for _, key := range keys {
+		merged[key].NodeName = hostlist.Compress(names[key])
+		compressed = append(compressed, merged[key])
+	}
+	return compressed
+}
 
-		wwlog.Verbose("Printing results")
-		for i := 0; i < len(statuses); i++ {
-			o := statuses[i]
-			if SetTime > 0 && o.Lastseen < SetTime {
-				continue
-			}
+// showHistory prints the provisioning event history recorded by warewulfd
+// for each of the given nodes.
+func showHistory(client *http.Client, endpoint string, nodes []string) error {
+	for i, name := range nodes {
+		historyURL := endpoint + "/" + url.PathEscape(name)
+		wwlog.Verbose("Connecting to: %s", historyURL)
 
-			if o.Lastseen > 0 {
-				if SetUnknown {
-					continue
-				}
-				if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval*2) {
-					color.Red("%-20s %-20s %-25s %-10d\n", o.NodeName, displayStage(o.Stage), o.Sent, rightnow-o.Lastseen)
-				} else if rightnow-o.Lastseen >= int64(controller.Warewulf.UpdateInterval+5) {
-					color.Yellow("%-20s %-20s %-25s %-10d\n", o.NodeName, displayStage(o.Stage), o.Sent, rightnow-o.Lastseen)
-				} else {
-					fmt.Printf("%-20s %-20s %-25s %-10d\n", o.NodeName, displayStage(o.Stage), o.Sent, rightnow-o.Lastseen)
-				}
-			} else {
-				color.HiBlack("%-20s %-20s %-25s %-10s\n", o.NodeName, "--", "--", "--")
-			}
-			if count+4 >= height && SetWatch {
-				if count+1 != len(statuses) {
-					elipsis = true
-				}
-				break
-			}
-			count++
+		resp, err := client.Get(historyURL)
+		if err != nil {
+			return fmt.Errorf("could not connect to Warewulf server: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			_ = resp.Body.Close()
+			wwlog.Warn("No status recorded for node: %s", name)
+			continue
+		}
+		var history nodeHistory
+		err = json.NewDecoder(resp.Body).Decode(&history)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("could not decode JSON: %w", err)
 		}
 
-		if SetWatch {
-			if elipsis {
-				fmt.Printf("... ")
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s: %s\n", name, displayStage(history.Stage))
+		fmt.Printf("%-20s %-16s %-25s %12s %10s %-7s %s\n", "TIME", "STAGE", "SENT", "BYTES", "DURATION", "STATUS", "IPADDR")
+		fmt.Printf("%s\n", strings.Repeat("=", 104))
+		for _, ev := range history.Events {
+			line := fmt.Sprintf("%-20s %-16s %-25s %12d %10s %-7d %s",
+				time.Unix(ev.Time, 0).Format("2006-01-02 15:04:05"),
+				displayStage(ev.Stage),
+				ev.Sent,
+				ev.Bytes,
+				(time.Duration(ev.Duration) * time.Millisecond).String(),
+				ev.Status,
+				ev.Ipaddr)
+			if ev.Error != "" {
+				color.Red("%s (%s)\n", line, ev.Error)
+			} else {
+				fmt.Println(line)
 			}
-			time.Sleep(time.Duration(SetUpdate) * time.Millisecond)
-		} else {
-			break
 		}
 	}
-	return
+	return nil
 }
@@ -59,3 +59,23 @@ func TestStatusURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCompressStatus(t *testing.T) {
+	statuses := []*nodeStatus{
+		{NodeName: "n01", Stage: "runtime", Sent: "__RUNTIME__.img.gz", Lastseen: 100},
+		{NodeName: "n02", Stage: "runtime", Sent: "__RUNTIME__.img.gz", Lastseen: 90},
+		{NodeName: "n03", Stage: "kernel", Sent: "vmlinuz", Lastseen: 95},
+		{NodeName: "n04", Stage: "runtime", Sent: "__RUNTIME__.img.gz", Lastseen: 110},
+		{NodeName: "n05"},
+		{NodeName: "n06"},
+	}
+	compressed := compressStatus(statuses)
+	if assert.Len(t, compressed, 3) {
+		assert.Equal(t, "n[01-02,04]", compressed[0].NodeName)
+		assert.Equal(t, int64(90), compressed[0].Lastseen)
+		assert.Equal(t, "n03", compressed[1].NodeName)
+		assert.Equal(t, "n[05-06]", compressed[2].NodeName)
+		assert.Equal(t, int64(0), compressed[2].Lastseen)
+	}
+	assert.Equal(t, "n01", statuses[0].NodeName)
+}
@@ -3,6 +3,7 @@ package nodestatus
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -21,6 +22,9 @@ var (
 	SetSortLast    bool
 	SetSortReverse bool
 	SetUnknown     bool
+	SetHistory     bool
+	SetCompress    bool
+	SetWhere       string
 )
 
 func init() {
@@ -30,6 +34,9 @@ func init() {
 	baseCmd.PersistentFlags().BoolVarP(&SetSortLast, "last", "l", false, "Sort by the last check-in time")
 	baseCmd.PersistentFlags().BoolVarP(&SetSortReverse, "reverse", "r", false, "Reverse the sort order")
 	baseCmd.PersistentFlags().BoolVarP(&SetUnknown, "unknown", "u", false, "Only show nodes of unknown status")
+	baseCmd.PersistentFlags().BoolVar(&SetHistory, "history", false, "Show the recent provisioning events of the given nodes")
+	baseCmd.PersistentFlags().BoolVarP(&SetCompress, "compress", "c", false, "Merge nodes at the same stage into a node range")
+	flags.AddWhere(baseCmd, &SetWhere)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -0,0 +1,118 @@
+package nodestatus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// errStreamUnsupported is returned by watchStream when the server does not
+// provide a status stream, e.g., because it predates it.
+var errStreamUnsupported = errors.New("status stream not supported by server")
+
+// streamReconnectDelay is how long to wait before reconnecting to a
+// status stream that was closed by the server.
+const streamReconnectDelay = time.Second
+
+// openStream requests the warewulfd status as a Server-Sent Events stream.
+func openStream(endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream is long-lived, so only bound the wait for the response
+	// headers rather than the whole request.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = statusHTTPTimeout
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to Warewulf server: %w", err)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		_ = resp.Body.Close()
+		return nil, errStreamUnsupported
+	}
+	return resp, nil
+}
+
+// readStream parses status events from a Server-Sent Events stream and
+// sends them to updates until the stream ends.
+func readStream(body io.Reader, updates chan<- *nodeStatus) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	event := ""
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if (event == "" || event == "status") && len(data) > 0 {
+				status := new(nodeStatus)
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), status); err != nil {
+					return fmt.Errorf("could not decode JSON: %w", err)
+				}
+				updates <- status
+			}
+			event = ""
+			data = nil
+		case strings.HasPrefix(line, ":"):
+			// comment or keepalive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// watchStream displays node status continuously from the warewulfd status
+// stream, redrawing at most every SetUpdate milliseconds. It returns
+// errStreamUnsupported if the server does not provide a stream.
+func watchStream(endpoint string, args []string, controller *warewulfconf.WarewulfYaml) error {
+	wwlog.Verbose("Connecting to: %s", endpoint)
+	resp, err := openStream(endpoint)
+	if err != nil {
+		return err
+	}
+
+	nodes := make(map[string]*nodeStatus)
+	updates := make(chan *nodeStatus)
+	done := make(chan error, 1)
+	go func() { done <- readStream(resp.Body, updates) }()
+
+	ticker := time.NewTicker(time.Duration(SetUpdate) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case status := <-updates:
+			nodes[status.NodeName] = status
+		case err := <-done:
+			_ = resp.Body.Close()
+			wwlog.Verbose("Status stream closed (%s), reconnecting", err)
+			time.Sleep(streamReconnectDelay)
+			if resp, err = openStream(endpoint); err != nil {
+				return err
+			}
+			nodes = make(map[string]*nodeStatus)
+			go func() { done <- readStream(resp.Body, updates) }()
+		case <-ticker.C:
+			printStatus(nodes, args, controller)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package nodestatus
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadStream(t *testing.T) {
+	stream := `event: status
+data: {"node name":"n1","stage":"ipxe","sent":"default.ipxe","ipaddr":"10.0.0.1","last seen":1712345678}
+
+: keepalive
+
+event: other
+data: {"node name":"ignored"}
+
+data: {"node name":"n2","stage":"kernel","last seen":1712345679}
+
+`
+	updates := make(chan *nodeStatus, 10)
+	err := readStream(strings.NewReader(stream), updates)
+	assert.ErrorIs(t, err, io.EOF)
+	close(updates)
+
+	var received []*nodeStatus
+	for status := range updates {
+		received = append(received, status)
+	}
+	assert.Equal(t, []*nodeStatus{
+		{NodeName: "n1", Stage: "ipxe", Sent: "default.ipxe", Ipaddr: "10.0.0.1", Lastseen: 1712345678},
+		{NodeName: "n2", Stage: "kernel", Lastseen: 1712345679},
+	}, received)
+}
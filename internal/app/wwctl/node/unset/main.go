@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	wwctlunset "github.com/warewulf/warewulf/internal/app/wwctl/unset"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -14,6 +16,11 @@ import (
 
 func CobraRunE(vars *wwctlunset.Vars) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		if selected, err := flags.SelectNodes(args, vars.Where); err != nil {
+			return err
+		} else {
+			args = selected
+		}
 		// Check if any fields were specified
 		vars.NetnameChanged = cmd.Flags().Changed("netname")
 		anyFieldSet := false
@@ -94,6 +101,7 @@ func CobraRunE(vars *wwctlunset.Vars) func(cmd *cobra.Command, args []string) er
 		if err := nodeDB.Persist(); err != nil {
 			return fmt.Errorf("failed to persist changes: %w", err)
 		}
+		audit.LogCommandChanges(audit.EntityNode, "unset", nodeChanges)
 
 		if err := warewulfd.DaemonReload(); err != nil {
 			wwlog.Warn("failed to reload daemon: %v", err)
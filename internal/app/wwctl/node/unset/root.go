@@ -3,6 +3,7 @@ package unset
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	wwctlunset "github.com/warewulf/warewulf/internal/app/wwctl/unset"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
@@ -17,7 +18,7 @@ func GetCommand() *cobra.Command {
 		Use:                   "unset [OPTIONS] PATTERN",
 		Short:                 "Unset/clear node properties",
 		Long:                  "Unsets configuration properties for nodes matching PATTERN.\n\n" + hostlist.Docstring,
-		Args:                  cobra.MinimumNArgs(1),
+		Args:                  flags.MinimumNodeArgs(1),
 		RunE:                  CobraRunE(&vars),
 		ValidArgsFunction:     completions.Nodes,
 	}
@@ -30,6 +31,7 @@ func GetCommand() *cobra.Command {
 	baseCmd.PersistentFlags().StringVar(&vars.Diskname, "diskname", "", "disk to modify")
 	baseCmd.PersistentFlags().StringVar(&vars.Partname, "partname", "", "partition to modify (requires --diskname)")
 	baseCmd.PersistentFlags().StringVar(&vars.Fsname, "fsname", "", "filesystem to modify")
+	flags.AddWhere(baseCmd, &vars.Where)
 
 	// Add tag deletion flags
 	baseCmd.PersistentFlags().StringSliceVar(&vars.Tags, "tag", []string{}, "Unset tags")
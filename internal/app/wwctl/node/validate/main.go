@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/validate"
+)
+
+func CobraRunE(cmd *cobra.Command, args []string) error {
+	nodesConf := warewulfconf.Get().Paths.NodesConf()
+	if len(args) > 0 {
+		nodesConf = args[0]
+	}
+	problems, err := validate.File(nodesConf)
+	if err != nil {
+		return fmt.Errorf("%s: %w", nodesConf, err)
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n", problem)
+	}
+	if errors := validate.Errors(problems); errors > 0 {
+		return fmt.Errorf("%s: %d errors, %d warnings", nodesConf, errors, len(problems)-errors)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package validate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Validate(t *testing.T) {
+	tests := map[string]struct {
+		nodesConf string
+		args      []string
+		stdout    string
+		wantErr   bool
+	}{
+		"valid": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+`,
+			stdout: "",
+		},
+		"invalid": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+        mtu: "big"
+  n2:
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+`,
+			stdout: `:6: error: node n1: duplicate IP address 10.0.0.1 for node n1
+:7: error: node n1: invalid MTU for default: big
+:11: error: node n2: duplicate IP address 10.0.0.1 for node n2
+`,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("/etc/warewulf/nodes.conf", tt.nodesConf)
+
+			buf := new(bytes.Buffer)
+			baseCmd := GetCommand()
+			baseCmd.SetArgs(tt.args)
+			baseCmd.SetOut(buf)
+			baseCmd.SetErr(new(bytes.Buffer))
+			baseCmd.SilenceUsage = true
+			err := baseCmd.Execute()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			nodesConf := env.GetPath("/etc/warewulf/nodes.conf")
+			assert.Equal(t, tt.stdout, string(bytes.ReplaceAll(buf.Bytes(), []byte(nodesConf), nil)))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	baseCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "validate [OPTIONS] [FILE]",
+		Short:                 "Check the node configuration for errors",
+		Long: "This command checks nodes.conf, or the given FILE, for errors that would otherwise\n" +
+			"only be discovered when a node boots or its overlays are built: references to\n" +
+			"missing profiles, images, overlays, or kernels; duplicate IP and hardware\n" +
+			"addresses; and malformed values such as partition GUIDs and MTUs. Each problem\n" +
+			"is reported with the line on which it is defined. The command exits with an\n" +
+			"error if any errors are found; warnings alone do not cause it to fail.",
+		RunE:    CobraRunE,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"lint"},
+	}
+)
+
+// GetRootCommand returns the root cobra.Command for the application.
+func GetCommand() *cobra.Command {
+	return baseCmd
+}
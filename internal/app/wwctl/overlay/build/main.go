@@ -7,12 +7,18 @@ import (
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) error {
+	if selected, err := flags.SelectNodes(args, Where); err != nil {
+		return err
+	} else {
+		args = selected
+	}
 	nodeDB, err := node.New()
 	if err != nil {
 		return fmt.Errorf("could not open node configuration: %s", err)
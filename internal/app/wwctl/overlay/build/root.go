@@ -3,6 +3,7 @@ package build
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 )
 
 var (
@@ -16,10 +17,12 @@ var (
 		Args:                  cobra.ArbitraryArgs,
 	}
 	Workers int
+	Where   string
 )
 
 func init() {
 	baseCmd.PersistentFlags().IntVar(&Workers, "workers", 0, "The number of parallel workers building overlays (<=0 indicates 1 worker per CPU)")
+	flags.AddWhere(baseCmd, &Where)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
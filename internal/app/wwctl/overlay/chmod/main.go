@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 )
 
@@ -19,5 +21,10 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("could not convert requested mode: %s", err)
 	}
-	return myOverlay.Chmod(path, permissionMode)
+	if err := myOverlay.Chmod(path, permissionMode); err != nil {
+		return err
+	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: myOverlay.Name(), Operation: "chmod", File: path,
+		Changes: []node.Change{{Path: "mode", After: args[2]}}})
+	return nil
 }
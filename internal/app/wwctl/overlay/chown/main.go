@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 )
 
@@ -41,5 +43,10 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	return myOverlay.Chown(fileName, uid, gid)
+	if err := myOverlay.Chown(fileName, uid, gid); err != nil {
+		return err
+	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: myOverlay.Name(), Operation: "chown", File: fileName,
+		Changes: []node.Change{{Path: "owner", After: chownSpec}}})
+	return nil
 }
@@ -2,10 +2,14 @@ package create
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 )
 
 func CobraRunE(cmd *cobra.Command, args []string) (err error) {
-	_, err = overlay.Create(args[0])
-	return err
+	if _, err = overlay.Create(args[0]); err != nil {
+		return err
+	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: args[0], Operation: "create"})
+	return nil
 }
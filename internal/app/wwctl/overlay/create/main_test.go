@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 )
@@ -58,6 +59,13 @@ func Test_OverlayCreate(t *testing.T) {
 				_, err := os.Stat(overlayDir)
 				assert.NoError(t, err, "overlay directory should have been created")
 			}
+			entries, err := audit.Query(audit.Filter{Entity: audit.EntityOverlay, ID: tt.overlayName})
+			assert.NoError(t, err)
+			if tt.wantErr {
+				assert.Empty(t, entries)
+			} else if assert.Len(t, entries, 1) {
+				assert.Equal(t, "create", entries[0].Operation)
+			}
 		})
 	}
 }
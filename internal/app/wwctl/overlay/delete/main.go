@@ -2,6 +2,7 @@ package delete
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 )
 
@@ -18,8 +19,15 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	}
 
 	if fileName == "" {
-		return myOverlay.Delete(Force)
+		if err := myOverlay.Delete(Force); err != nil {
+			return err
+		}
+		audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: myOverlay.Name(), Operation: "delete"})
 	} else {
-		return myOverlay.DeleteFile(fileName, Force, Parents)
+		if err := myOverlay.DeleteFile(fileName, Force, Parents); err != nil {
+			return err
+		}
+		audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: myOverlay.Name(), Operation: "delete-file", File: fileName})
 	}
+	return nil
 }
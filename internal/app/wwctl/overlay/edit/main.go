@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+	"github.com/warewulf/warewulf/internal/pkg/history"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -120,11 +122,21 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
+	var before []byte
+	if util.IsFile(overlayFile) {
+		if before, err = os.ReadFile(overlayFile); err != nil {
+			return err
+		}
+	}
 	// using CopyFile preserves target file permissions
 	cerr := util.CopyFile(tempFile.Name(), overlayFile)
 	if cerr != nil {
 		return fmt.Errorf("unable to copy data from temp file: %s to target file: %s, err: %s", tempFile.Name(), overlayFile, err)
 	}
+	if after, err := os.ReadFile(overlayFile); err == nil {
+		history.RecordOverlayFile(myOverlay.Name(), fileName, before, after)
+	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: myOverlay.Name(), Operation: "edit", File: fileName})
 
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -62,6 +63,7 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return fmt.Errorf("could not copy file into overlay: %w", err)
 	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: overlay_.Name(), Operation: "add-file", File: dest})
 
 	return nil
 }
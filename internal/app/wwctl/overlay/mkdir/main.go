@@ -2,6 +2,7 @@ package mkdir
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 )
 
@@ -11,5 +12,9 @@ func CobraRunE(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return err
 	}
-	return myOverlay.Mkdir(args[1], PermMode)
+	if err := myOverlay.Mkdir(args[1], PermMode); err != nil {
+		return err
+	}
+	audit.LogCommand(audit.Entry{Entity: audit.EntityOverlay, ID: myOverlay.Name(), Operation: "mkdir", File: args[1]})
+	return nil
 }
@@ -36,7 +36,7 @@ nodes:
 	}{
 		"power cycle": {
 			args:     []string{"--show", "n01"},
-			expected: `10.10.10.10: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis power cycle`,
+			expected: `n01: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis power cycle`,
 		},
 	}
 
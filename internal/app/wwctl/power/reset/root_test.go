@@ -36,7 +36,7 @@ nodes:
 	}{
 		"power reset": {
 			args:     []string{"--show", "n01"},
-			expected: `10.10.10.10: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis power reset`,
+			expected: `n01: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis power reset`,
 		},
 	}
 	for name, tt := range tests {
@@ -0,0 +1,97 @@
+// Package run runs a BMC power command for the nodes selected on the command
+// line and reports the result for each node.
+package run
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
+	"github.com/warewulf/warewulf/internal/app/wwctl/table"
+	"github.com/warewulf/warewulf/internal/pkg/bmc"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// Variables holds the flags shared by the power commands.
+type Variables struct {
+	Showcmd  bool
+	Fanout   int
+	Compress bool
+	Where    string
+}
+
+// AddFlags adds the flags shared by the power commands to powerCmd.
+func AddFlags(powerCmd *cobra.Command, vars *Variables) {
+	powerCmd.PersistentFlags().BoolVarP(&vars.Showcmd, "show", "s", false, "only show command which will be executed")
+	powerCmd.PersistentFlags().IntVar(&vars.Fanout, "fanout", 50, "how many command should be executed in parallel")
+	powerCmd.PersistentFlags().BoolVarP(&vars.Compress, "compress", "c", false, "Merge nodes with identical results into a node range")
+	flags.AddWhere(powerCmd, &vars.Where)
+}
+
+// CobraRunE returns a cobra RunE function that runs the BMC command
+// bmcCmd (e.g., "PowerStatus") for the selected nodes.
+func CobraRunE(vars *Variables, bmcCmd string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if selected, err := flags.SelectNodes(args, vars.Where); err != nil {
+			return err
+		} else {
+			args = selected
+		}
+
+		nodeDB, err := node.New()
+		if err != nil {
+			return fmt.Errorf("could not open node configuration: %s", err)
+		}
+
+		nodes, err := nodeDB.FindAllNodes()
+		if err != nil {
+			return fmt.Errorf("could not get node list: %s", err)
+		}
+
+		if len(args) > 0 {
+			nodes = node.FilterNodeListByName(nodes, hostlist.Expand(args))
+		} else {
+			//nolint:errcheck
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		if len(nodes) == 0 {
+			return fmt.Errorf("no nodes found")
+		}
+
+		return Report(bmc.RunAll(nodes, bmcCmd, vars.Fanout, vars.Showcmd), vars.Compress)
+	}
+}
+
+// Report logs the result for each node, merging nodes with identical
+// results into a node range if compress is set. The last error is returned.
+func Report(results []bmc.NodeResult, compress bool) (returnErr error) {
+	var rows [][]string
+	for _, result := range results {
+		if result.Err != nil {
+			out := result.Output
+			if out == "" {
+				out = result.Err.Error()
+			}
+			rows = append(rows, []string{result.Node, out, "error"})
+			returnErr = result.Err
+			continue
+		}
+		rows = append(rows, []string{result.Node, result.Output, ""})
+	}
+	if compress {
+		rows = table.CompressRows(rows)
+	}
+	for _, row := range rows {
+		if row[2] != "" {
+			wwlog.Error("%s: %s", row[0], row[1])
+		} else {
+			wwlog.Info("%s: %s", row[0], row[1])
+		}
+	}
+	return returnErr
+}
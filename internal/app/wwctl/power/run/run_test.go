@@ -0,0 +1,51 @@
+package run
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/bmc"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+func Test_Report(t *testing.T) {
+	results := []bmc.NodeResult{
+		{Node: "n01", Output: "Chassis Power is on"},
+		{Node: "n02", Output: "Chassis Power is on"},
+		{Node: "n03", Err: errors.New("no IPMI IP address")},
+		{Node: "n04", Output: "Chassis Power is off"},
+	}
+
+	tests := map[string]struct {
+		compress bool
+		expected string
+	}{
+		"by node": {
+			compress: false,
+			expected: "n01: Chassis Power is on\n" +
+				"n02: Chassis Power is on\n" +
+				"ERROR  : n03: no IPMI IP address\n" +
+				"n04: Chassis Power is off\n",
+		},
+		"compressed": {
+			compress: true,
+			expected: "n[01-02]: Chassis Power is on\n" +
+				"ERROR  : n03: no IPMI IP address\n" +
+				"n04: Chassis Power is off\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			wwlog.SetLogWriter(buf)
+			defer wwlog.SetLogWriter(os.Stdout)
+			err := Report(results, tt.compress)
+			assert.EqualError(t, err, "no IPMI IP address")
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
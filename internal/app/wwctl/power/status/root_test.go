@@ -36,7 +36,7 @@ nodes:
 	}{
 		"sensors": {
 			args:     []string{"--show", "n01"},
-			expected: `10.10.10.10: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis power status`,
+			expected: `n01: ipmitool -H 10.10.10.10 -U "admin" -P "admin" chassis power status`,
 		},
 	}
 
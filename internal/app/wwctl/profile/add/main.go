@@ -7,6 +7,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 )
@@ -44,6 +45,10 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 		}
 		delete(vars.profileConf.Disks, "UNDEF")
 		vars.profileConf.Ipmi.Tags = vars.profileAdd.IpmiTagsAdd
+		vars.profileConf.Attributes = vars.profileAdd.AttrsAdd
+		if err := vars.profileConf.ValidateAttributes(); err != nil {
+			return err
+		}
 		buffer, err := yaml.Marshal(vars.profileConf)
 		if err != nil {
 			return fmt.Errorf("can not marshall nodeInfo: %w", err)
@@ -52,6 +57,7 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 		if err != nil {
 			return fmt.Errorf("could not open database: %w", err)
 		}
+		profileChanges := make(map[string][]node.Change)
 		for _, p := range args {
 			if util.InSlice(nodeDB.ListAllProfiles(), p) {
 				return fmt.Errorf("profile with name %s already exists", p)
@@ -64,7 +70,13 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 			if err != nil {
 				return fmt.Errorf("failed to add profile: %w", err)
 			}
+			before := node.NewProfile(p)
+			profileChanges[p] = node.Diff(&before, pNew)
+		}
+		if err := nodeDB.Persist(); err != nil {
+			return err
 		}
-		return nodeDB.Persist()
+		audit.LogCommandChanges(audit.EntityProfile, "add", profileChanges)
+		return nil
 	}
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -44,6 +45,7 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var deleted []string
 	for _, r := range args {
 		var found bool
 		for _, p := range profiles {
@@ -53,6 +55,8 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 				err := nodeDB.DelProfile(r)
 				if err != nil {
 					wwlog.Error("%s", err)
+				} else {
+					deleted = append(deleted, r)
 				}
 			}
 		}
@@ -71,6 +75,7 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to persist nodedb: %w", err)
 		}
+		logDeleted(deleted)
 	} else {
 		prompt := promptui.Prompt{
 			Label:     fmt.Sprintf("Are you sure you want to delete %d profile(s)", count),
@@ -84,8 +89,16 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 			if err != nil {
 				return fmt.Errorf("failed to persist nodedb: %w", err)
 			}
+			logDeleted(deleted)
 		}
 	}
 
 	return nil
 }
+
+// logDeleted records the deleted profiles in the audit log.
+func logDeleted(profileIDs []string) {
+	for _, id := range profileIDs {
+		audit.LogCommand(audit.Entry{Entity: audit.EntityProfile, ID: id, Operation: "delete"})
+	}
+}
@@ -1,12 +1,15 @@
 package edit
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
@@ -41,7 +44,12 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 	if tempErr != nil {
 		return fmt.Errorf("could not create temp file: %s", tempErr)
 	}
-	defer func() { _ = os.Remove(tempFile.Name()) }()
+	keepTempFile := false
+	defer func() {
+		if !keepTempFile {
+			_ = os.Remove(tempFile.Name())
+		}
+	}()
 
 	if !NoHeader {
 		yamlTemplate := node.ConfToYaml(node.Profile{}, nil)
@@ -96,13 +104,23 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 					break
 				}
 			}
+			if err := checkAttributes(origProfiles, editProfiles); err != nil {
+				wwlog.Error("%v\n", err)
+				if util.Confirm("Invalid attributes: retry") {
+					continue
+				} else {
+					break
+				}
+			}
 
 			var added, deleted, updated int
+			var entries []audit.Entry
 			for profileID := range origProfiles {
 				if editProfile, ok := editProfiles[profileID]; !ok || editProfile == nil {
 					wwlog.Verbose("delete profile: %s", profileID)
 					delete(registry.NodeProfiles, profileID)
 					deleted += 1
+					entries = append(entries, audit.Entry{Entity: audit.EntityProfile, ID: profileID, Operation: "delete"})
 				}
 			}
 			for profileID := range editProfiles {
@@ -110,19 +128,32 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 					wwlog.Verbose("add profile: %s", profileID)
 					added += 1
 					registry.NodeProfiles[profileID] = editProfiles[profileID]
+					before := node.NewProfile(profileID)
+					entries = append(entries, audit.Entry{Entity: audit.EntityProfile, ID: profileID, Operation: "add",
+						Changes: node.Diff(&before, editProfiles[profileID])})
 				} else if equalYaml, err := util.EqualYaml(origProfiles[profileID], editProfiles[profileID]); err != nil {
 					return err
 				} else if !equalYaml {
 					wwlog.Verbose("update profile: %s", profileID)
 					updated += 1
 					registry.NodeProfiles[profileID] = editProfiles[profileID]
+					entries = append(entries, audit.Entry{Entity: audit.EntityProfile, ID: profileID, Operation: "update",
+						Changes: node.Diff(origProfiles[profileID], editProfiles[profileID])})
 				}
 			}
 
 			if util.Confirm(fmt.Sprintf("Are you sure you want to add %d, delete %d, and update %d profiles", added, deleted, updated)) {
-				if err := registry.Persist(); err != nil {
+				if err := registry.Persist(); errors.Is(err, node.ErrConflict) {
+					keepTempFile = true
+					return fmt.Errorf("node configuration was changed by another process while it was being edited; "+
+						"changes were not saved and remain in %s", tempFile.Name())
+				} else if err != nil {
 					return err
 				}
+				sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+				for _, entry := range entries {
+					audit.LogCommand(entry)
+				}
 
 				if err := warewulfd.DaemonReload(); err != nil {
 					return fmt.Errorf("failed to reload warewulf daemon: %w", err)
@@ -137,3 +168,30 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// checkAttributes validates the attributes of the profiles that were added or
+// changed in the editor against the attribute schema, as 'wwctl profile set'
+// does.
+func checkAttributes(origProfiles, editProfiles map[string]*node.Profile) error {
+	ids := make([]string, 0, len(editProfiles))
+	for id := range editProfiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var errs []error
+	for _, id := range ids {
+		editProfile := editProfiles[id]
+		if editProfile == nil {
+			continue
+		}
+		if origProfile, ok := origProfiles[id]; ok {
+			if equalYaml, err := util.EqualYaml(origProfile, editProfile); err == nil && equalYaml {
+				continue
+			}
+		}
+		if err := editProfile.ValidateAttributes(); err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package edit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_checkAttributes(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/warewulf.conf", `
+attributes:
+  rack:
+    type: int
+`)
+	env.Configure()
+
+	orig := map[string]*node.Profile{
+		"p1": {Attributes: map[string]string{"slot": "1"}},
+	}
+	// unchanged profiles are not checked again
+	assert.NoError(t, checkAttributes(orig, map[string]*node.Profile{
+		"p1": {Attributes: map[string]string{"slot": "1"}},
+		"p2": {Attributes: map[string]string{"rack": "12"}},
+		"p3": nil,
+	}))
+	assert.EqualError(t, checkAttributes(orig, map[string]*node.Profile{
+		"p1": {Attributes: map[string]string{"slot": "2"}},
+		"p2": {Attributes: map[string]string{"rack": "notanint"}},
+	}), "profile p1: unknown attribute: slot\nprofile p2: invalid value for attribute rack: not an integer: notanint")
+}
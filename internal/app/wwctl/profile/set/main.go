@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
@@ -128,6 +129,18 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 				}
 				profilePtr.Tags[key] = val
 			}
+			for _, key := range vars.profileDel.AttrsDel {
+				delete(profilePtr.Attributes, key)
+			}
+			for key, val := range vars.profileAdd.AttrsAdd {
+				if profilePtr.Attributes == nil {
+					profilePtr.Attributes = make(map[string]string)
+				}
+				profilePtr.Attributes[key] = val
+			}
+			if err := profilePtr.ValidateAttributes(); err != nil {
+				return fmt.Errorf("profile %s: %w", profileId, err)
+			}
 			for key, val := range vars.profileAdd.IpmiTagsAdd {
 				if profilePtr.Ipmi.Tags == nil {
 					profilePtr.Ipmi.Tags = make(map[string]string)
@@ -176,6 +189,7 @@ func CobraRunE(vars *variables) func(cmd *cobra.Command, args []string) (err err
 		if err := nodeDB.Persist(); err != nil {
 			return err
 		}
+		audit.LogCommandChanges(audit.EntityProfile, "set", profileChanges)
 		return warewulfd.DaemonReload()
 	}
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	wwctlunset "github.com/warewulf/warewulf/internal/app/wwctl/unset"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
@@ -90,6 +91,7 @@ func CobraRunE(vars *wwctlunset.Vars) func(cmd *cobra.Command, args []string) er
 		if err := nodeDB.Persist(); err != nil {
 			return fmt.Errorf("failed to persist changes: %w", err)
 		}
+		audit.LogCommandChanges(audit.EntityProfile, "unset", profileChanges)
 
 		if err := warewulfd.DaemonReload(); err != nil {
 			wwlog.Warn("failed to reload daemon: %v", err)
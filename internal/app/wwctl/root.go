@@ -4,10 +4,15 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/api"
+	"github.com/warewulf/warewulf/internal/app/wwctl/audit"
 	"github.com/warewulf/warewulf/internal/app/wwctl/clean"
 	"github.com/warewulf/warewulf/internal/app/wwctl/configure"
 	"github.com/warewulf/warewulf/internal/app/wwctl/genconf"
+	"github.com/warewulf/warewulf/internal/app/wwctl/history"
 	"github.com/warewulf/warewulf/internal/app/wwctl/image"
+	"github.com/warewulf/warewulf/internal/app/wwctl/job"
+	"github.com/warewulf/warewulf/internal/app/wwctl/network"
 	"github.com/warewulf/warewulf/internal/app/wwctl/node"
 	"github.com/warewulf/warewulf/internal/app/wwctl/overlay"
 	"github.com/warewulf/warewulf/internal/app/wwctl/power"
@@ -62,6 +67,11 @@ func init() {
 	rootCmd.AddCommand(genconf.GetCommand())
 	rootCmd.AddCommand(clean.GetCommand())
 	rootCmd.AddCommand(upgrade.GetCommand())
+	rootCmd.AddCommand(api.GetCommand())
+	rootCmd.AddCommand(audit.GetCommand())
+	rootCmd.AddCommand(job.GetCommand())
+	rootCmd.AddCommand(network.GetCommand())
+	rootCmd.AddCommand(history.GetCommand())
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/batch"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 	"github.com/warewulf/warewulf/internal/pkg/node"
@@ -31,8 +32,16 @@ func CobraRunE(cmd *cobra.Command, args []string) error {
 		os.Exit(1)
 	}
 
+	if Where != "" {
+		// with --where, the node pattern is omitted
+		selected, err := flags.SelectNodes(nil, Where)
+		if err != nil {
+			return err
+		}
+		args = append([]string{strings.Join(selected, ",")}, args...)
+	}
 	if len(args) > 0 {
-		nodes = node.FilterNodeListByName(nodes, hostlist.Expand(args))
+		nodes = node.FilterNodeListByName(nodes, hostlist.Expand(args[:1]))
 	} else {
 		//nolint:errcheck
 		cmd.Usage()
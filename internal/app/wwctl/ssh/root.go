@@ -3,6 +3,7 @@ package ssh
 import (
 	"github.com/spf13/cobra"
 	"github.com/warewulf/warewulf/internal/app/wwctl/completions"
+	"github.com/warewulf/warewulf/internal/app/wwctl/flags"
 	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 )
 
@@ -11,9 +12,10 @@ var (
 		DisableFlagsInUseLine: true,
 		Use:                   "ssh [OPTIONS] NODE_PATTERN COMMAND",
 		Short:                 "SSH into configured nodes in parallel",
-		Long:                  "Easily ssh into nodes in parallel to run non-interactive commands\n" + hostlist.Docstring,
-		RunE:                  CobraRunE,
-		Args:                  cobra.MinimumNArgs(2),
+		Long: "Easily ssh into nodes in parallel to run non-interactive commands\n" + hostlist.Docstring + "\n" +
+			"With --where, NODE_PATTERN is omitted and nodes are selected by attribute alone.",
+		RunE: CobraRunE,
+		Args: flags.MinimumNodeArgs(2),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
 				return completions.Nodes(cmd, args, toComplete)
@@ -25,6 +27,7 @@ var (
 	FanOut  int
 	Sleep   int
 	SshPath string
+	Where   string
 )
 
 func init() {
@@ -32,6 +35,7 @@ func init() {
 	baseCmd.PersistentFlags().IntVarP(&FanOut, "fanout", "f", 32, "How many connections to run in parallel")
 	baseCmd.PersistentFlags().IntVarP(&Sleep, "sleep", "s", 0, "Seconds to sleep inbetween processes")
 	baseCmd.PersistentFlags().StringVar(&SshPath, "rsh", "/usr/bin/ssh", "Path to use for RSH/SSH command")
+	flags.AddWhere(baseCmd, &Where)
 }
 
 // GetRootCommand returns the root cobra.Command for the application.
@@ -2,9 +2,11 @@ package table
 
 import (
 	"io"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/cheynewallace/tabby"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
 )
 
 func Prep(parts []string) []interface{} {
@@ -22,3 +24,30 @@ func Prep(parts []string) []interface{} {
 func New(writer io.Writer) *tabby.Tabby {
 	return tabby.NewCustom(tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0))
 }
+
+// CompressRows merges rows that are identical apart from their first
+// column, which holds a node name, into a single row whose first column is
+// the hostlist of the merged nodes. Rows are kept in the order in which
+// they first appear.
+func CompressRows(rows [][]string) [][]string {
+	var keys []string
+	names := make(map[string][]string)
+	merged := make(map[string][]string)
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		key := strings.Join(row[1:], "\x00")
+		if _, ok := merged[key]; !ok {
+			keys = append(keys, key)
+			merged[key] = row
+		}
+		names[key] = append(names[key], row[0])
+	}
+	compressed := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		row := append([]string{hostlist.Compress(names[key])}, merged[key][1:]...)
+		compressed = append(compressed, row)
+	}
+	return compressed
+}
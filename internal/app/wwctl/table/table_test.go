@@ -0,0 +1,20 @@
+package table
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CompressRows(t *testing.T) {
+	assert.Equal(t, [][]string{
+		{"n[01-02,04],head", "default", "eth0"},
+		{"n03", "gpu", "eth0"},
+	}, CompressRows([][]string{
+		{"n01", "default", "eth0"},
+		{"n02", "default", "eth0"},
+		{"n03", "gpu", "eth0"},
+		{"head", "default", "eth0"},
+		{"n04", "default", "eth0"},
+	}))
+}
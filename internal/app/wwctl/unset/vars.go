@@ -15,4 +15,5 @@ type Vars struct {
 	Tags           []string
 	IpmiTags       []string
 	NetTags        []string
+	Where          string
 }
@@ -0,0 +1,149 @@
+// Package audit records changes made to the Warewulf configuration through
+// the REST API and wwctl in an append-only log of JSON lines.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// Entity types recorded in the audit log.
+const (
+	EntityNode    = "node"
+	EntityProfile = "profile"
+	EntityImage   = "image"
+	EntityOverlay = "overlay"
+)
+
+// SourceWwctl is the source recorded for changes made with wwctl.
+const SourceWwctl = "wwctl"
+
+// Entry is a single audit log record.
+type Entry struct {
+	Time      time.Time     `json:"time"`
+	User      string        `json:"user"`
+	Source    string        `json:"source"`
+	Entity    string        `json:"entity"`
+	ID        string        `json:"id"`
+	Operation string        `json:"operation"`
+	File      string        `json:"file,omitempty"`
+	Changes   []node.Change `json:"changes,omitempty"`
+}
+
+var logLock sync.Mutex
+
+// Record appends entry to the audit log, setting its time if unset.
+func Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	logFile := config.Get().Paths.AuditLog()
+	logLock.Lock()
+	defer logLock.Unlock()
+	if err := os.MkdirAll(path.Dir(logFile), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	// a single write to a file opened for append is not interleaved with
+	// writes from other processes
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Log records entry, warning rather than failing if it cannot be written:
+// the change it describes has already been made.
+func Log(entry Entry) {
+	if err := Record(entry); err != nil {
+		wwlog.Warn("Could not write audit log: %s", err)
+	}
+}
+
+// LogCommand records a change made with wwctl, attributing it to the user
+// running the command.
+func LogCommand(entry Entry) {
+	entry.User = util.CurrentUser()
+	entry.Source = SourceWwctl
+	Log(entry)
+}
+
+// LogCommandChanges records the changes made with wwctl to each of several
+// entities of the same type.
+func LogCommandChanges(entity, operation string, changes map[string][]node.Change) {
+	ids := make([]string, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		LogCommand(Entry{Entity: entity, ID: id, Operation: operation, Changes: changes[id]})
+	}
+}
+
+// Filter selects audit log entries. Empty fields match every entry.
+type Filter struct {
+	Entity string
+	ID     string
+	User   string
+	Since  time.Time
+}
+
+func (filter Filter) match(entry Entry) bool {
+	return (filter.Entity == "" || filter.Entity == entry.Entity) &&
+		(filter.ID == "" || filter.ID == entry.ID) &&
+		(filter.User == "" || filter.User == entry.User) &&
+		(filter.Since.IsZero() || !entry.Time.Before(filter.Since))
+}
+
+// Query returns the audit log entries matching filter, oldest first. A
+// missing audit log has no entries.
+func Query(filter Filter) ([]Entry, error) {
+	var entries []Entry
+	f, err := os.Open(config.Get().Paths.AuditLog())
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			wwlog.Warn("Skipping invalid audit log entry on line %d: %s", line, err)
+			continue
+		}
+		if filter.match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
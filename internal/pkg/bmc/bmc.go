@@ -11,6 +11,7 @@ import (
 
 	"github.com/Masterminds/sprig/v3"
 
+	"github.com/warewulf/warewulf/internal/pkg/batch"
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -134,3 +135,33 @@ func (tstruct *TemplateStruct) SensorList() (string, error) {
 func (tstruct *TemplateStruct) Console() error {
 	return tstruct.InteractiveCommand("Console")
 }
+
+// NodeResult is the result of a BMC command run for a single node.
+type NodeResult struct {
+	Node   string
+	Output string
+	Err    error
+}
+
+// RunAll runs the BMC command cmd (e.g., "PowerStatus") for each node, at
+// most fanout at a time, and returns the results in the order of nodes.
+func RunAll(nodes []node.Node, cmd string, fanout int, showOnly bool) []NodeResult {
+	results := make([]NodeResult, len(nodes))
+	batchpool := batch.New(fanout)
+	for i, n := range nodes {
+		results[i].Node = n.Id()
+		if n.Ipmi == nil || n.Ipmi.Ipaddr == nil || n.Ipmi.Ipaddr.IsUnspecified() {
+			results[i].Err = fmt.Errorf("no IPMI IP address")
+			continue
+		}
+		ipmiCmd := TemplateStruct{
+			IpmiConf: *n.Ipmi,
+			ShowOnly: showOnly,
+		}
+		batchpool.Submit(func() {
+			results[i].Output, results[i].Err = ipmiCmd.Command(cmd)
+		})
+	}
+	batchpool.Run()
+	return results
+}
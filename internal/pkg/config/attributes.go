@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Attribute types
+const (
+	AttributeString = "string"
+	AttributeInt    = "int"
+	AttributeBool   = "bool"
+	AttributeList   = "list"
+	AttributeIP     = "IP"
+	AttributeMAC    = "MAC"
+)
+
+// An Attribute declares a custom node attribute that nodes and profiles may
+// set with "wwctl node set --attr". Type is one of string (the default),
+// int, bool, list (comma-separated), IP, or MAC. If Values is set, the
+// attribute (or, for a list, each of its entries) must be one of them.
+// Default is used for nodes that do not set the attribute.
+type Attribute struct {
+	Type        string   `yaml:"type,omitempty"`
+	Default     string   `yaml:"default,omitempty"`
+	Values      []string `yaml:"values,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+// Parse checks value against the attribute's type and allowed values and
+// returns it as a string, int, bool, []string, net.IP, or
+// net.HardwareAddr.
+func (attr *Attribute) Parse(value string) (interface{}, error) {
+	var typed interface{}
+	entries := []string{value}
+	switch attr.Type {
+	case "", AttributeString:
+		typed = value
+	case AttributeInt:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("not an integer: %s", value)
+		}
+		typed = i
+	case AttributeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a boolean: %s", value)
+		}
+		typed = b
+	case AttributeList:
+		entries = strings.Split(value, ",")
+		typed = entries
+	case AttributeIP:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("not an IP address: %s", value)
+		}
+		typed = ip
+	case AttributeMAC:
+		mac, err := net.ParseMAC(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a hardware address: %s", value)
+		}
+		typed = mac
+	default:
+		return nil, fmt.Errorf("unknown attribute type: %s", attr.Type)
+	}
+	if len(attr.Values) > 0 {
+		for _, entry := range entries {
+			allowed := false
+			for _, v := range attr.Values {
+				if entry == v {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return nil, fmt.Errorf("%s is not one of %s", entry, strings.Join(attr.Values, ", "))
+			}
+		}
+	}
+	return typed, nil
+}
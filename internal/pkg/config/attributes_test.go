@@ -0,0 +1,45 @@
+package config
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AttributeParse(t *testing.T) {
+	var tests = map[string]struct {
+		attr  Attribute
+		value string
+		typed interface{}
+		err   bool
+	}{
+		"string":           {attr: Attribute{}, value: "a", typed: "a"},
+		"int":              {attr: Attribute{Type: "int"}, value: "12", typed: 12},
+		"invalid int":      {attr: Attribute{Type: "int"}, value: "twelve", err: true},
+		"bool":             {attr: Attribute{Type: "bool"}, value: "true", typed: true},
+		"invalid bool":     {attr: Attribute{Type: "bool"}, value: "maybe", err: true},
+		"list":             {attr: Attribute{Type: "list"}, value: "a,b", typed: []string{"a", "b"}},
+		"IP":               {attr: Attribute{Type: "IP"}, value: "10.0.0.1", typed: net.ParseIP("10.0.0.1")},
+		"invalid IP":       {attr: Attribute{Type: "IP"}, value: "10.0.0", err: true},
+		"MAC":              {attr: Attribute{Type: "MAC"}, value: "00:00:00:00:00:01", typed: net.HardwareAddr{0, 0, 0, 0, 0, 1}},
+		"invalid MAC":      {attr: Attribute{Type: "MAC"}, value: "00:00", err: true},
+		"unknown type":     {attr: Attribute{Type: "float"}, value: "1.0", err: true},
+		"allowed value":    {attr: Attribute{Values: []string{"a", "b"}}, value: "b", typed: "b"},
+		"disallowed value": {attr: Attribute{Values: []string{"a", "b"}}, value: "c", err: true},
+		"allowed entries":  {attr: Attribute{Type: "list", Values: []string{"a", "b"}}, value: "b,a", typed: []string{"b", "a"}},
+		"disallowed entry": {attr: Attribute{Type: "list", Values: []string{"a", "b"}}, value: "a,c", err: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			typed, err := tt.attr.Parse(tt.value)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.typed, typed)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -11,14 +13,65 @@ import (
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
+// Role determines which REST API operations a user may perform. Roles are
+// ordered: each role may perform every operation of the roles below it.
+type Role string
+
+const (
+	// RoleReadOnly may read nodes, profiles, images, and overlays.
+	RoleReadOnly Role = "read-only"
+	// RoleNodeOperator may additionally update nodes and build overlays.
+	RoleNodeOperator Role = "node-operator"
+	// RoleAdmin may perform every operation. Users without an explicit
+	// role are administrators.
+	RoleAdmin Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleReadOnly:     1,
+	RoleNodeOperator: 2,
+	RoleAdmin:        3,
+}
+
+// Valid returns true if role is a known role or empty.
+func (role Role) Valid() bool {
+	if role == "" {
+		return true
+	}
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Allows returns true if role grants the permissions of required.
+func (role Role) Allows(required Role) bool {
+	if role == "" {
+		role = RoleAdmin
+	}
+	return roleRank[role] >= roleRank[required]
+}
+
 type User struct {
-	Name         string `json:"name"          yaml:"name"`
-	PasswordHash string `json:"password hash" yaml:"password hash"`
+	Name         string `json:"name"           yaml:"name"`
+	PasswordHash string `json:"password hash"  yaml:"password hash"`
+	Role         Role   `json:"role,omitempty" yaml:"role,omitempty"`
+}
+
+// GetRole returns the role of the user, defaulting to [RoleAdmin].
+func (user User) GetRole() Role {
+	if user.Role == "" {
+		return RoleAdmin
+	}
+	return user.Role
 }
 
 type Authentication struct {
 	Users   []User          `json:"users" yaml:"users"`
 	userMap map[string]User `json:"-"     yaml:"-"`
+
+	tokensFile    string
+	tokens        *APITokens
+	tokensModTime time.Time
+	tokensLock    sync.Mutex
 }
 
 func NewAuthentication() *Authentication {
@@ -39,6 +92,9 @@ func (auth *Authentication) ParseFromRaw(data []byte) error {
 		if _, ok := auth.userMap[user.Name]; ok {
 			return fmt.Errorf("duplicated user names")
 		}
+		if !user.Role.Valid() {
+			return fmt.Errorf("invalid role for user %s: %s", user.Name, user.Role)
+		}
 		auth.userMap[user.Name] = user
 	}
 	return nil
@@ -70,3 +126,54 @@ func (auth *Authentication) Authenticate(name, pass string) (*User, error) {
 		return &user, nil
 	}
 }
+
+// ReadTokens loads API tokens from fileName. The file is read again when it
+// changes, so that tokens created or revoked with wwctl take effect without
+// restarting the server.
+func (auth *Authentication) ReadTokens(fileName string) error {
+	auth.tokensLock.Lock()
+	defer auth.tokensLock.Unlock()
+	auth.tokensFile = fileName
+	return auth.loadTokens()
+}
+
+// loadTokens reads the tokens file if it has changed since it was last
+// read. The caller must hold tokensLock.
+func (auth *Authentication) loadTokens() error {
+	var modTime time.Time
+	if info, err := os.Stat(auth.tokensFile); err == nil {
+		modTime = info.ModTime()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if auth.tokens != nil && modTime.Equal(auth.tokensModTime) {
+		return nil
+	}
+	tokens, err := ReadAPITokens(auth.tokensFile)
+	if err != nil {
+		return err
+	}
+	auth.tokens = tokens
+	auth.tokensModTime = modTime
+	return nil
+}
+
+// AuthenticateToken returns the user for a bearer token secret.
+func (auth *Authentication) AuthenticateToken(secret string) (*User, error) {
+	auth.tokensLock.Lock()
+	defer auth.tokensLock.Unlock()
+	if auth.tokensFile == "" {
+		return nil, UnauthorizedError
+	}
+	if err := auth.loadTokens(); err != nil {
+		wwlog.Warn("Could not read API tokens: %s", err)
+	}
+	if auth.tokens == nil {
+		return nil, UnauthorizedError
+	}
+	token, err := auth.tokens.Authenticate(secret)
+	if err != nil {
+		return nil, err
+	}
+	return token.User(), nil
+}
@@ -0,0 +1,14 @@
+package config
+
+// An AddressPool is a named range of IP addresses from which addresses are
+// allocated to node network devices and IPMI interfaces that refer to it.
+// RangeStart and RangeEnd default to the first and last usable addresses
+// of Subnet. Exclude lists addresses, address ranges (start-end), and
+// subnets that are never allocated; the gateway is always excluded.
+type AddressPool struct {
+	Subnet     string   `yaml:"subnet"`
+	RangeStart string   `yaml:"range start,omitempty"`
+	RangeEnd   string   `yaml:"range end,omitempty"`
+	Gateway    string   `yaml:"gateway,omitempty"`
+	Exclude    []string `yaml:"exclude,omitempty"`
+}
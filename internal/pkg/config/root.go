@@ -25,22 +25,24 @@ var cachedConf WarewulfYaml
 // some information about the Warewulf server locally, and has
 // [WarewulfConf], [DHCPConf], [TFTPConf], and [NFSConf] sub-sections.
 type WarewulfYaml struct {
-	Comment     string        `yaml:"comment,omitempty"`
-	Ipaddr      string        `yaml:"ipaddr,omitempty"`
-	Netmask     string        `yaml:"netmask,omitempty"`
-	Network     string        `yaml:"network,omitempty"`
-	Fqdn        string        `yaml:"fqdn,omitempty"`
-	Ipaddr6     string        `yaml:"ipaddr6,omitempty"`
-	PrefixLen6  string        `yaml:"prefixlen6,omitempty"`
-	Warewulf    *WarewulfConf `yaml:"warewulf,omitempty"`
-	API         *APIConf      `yaml:"api,omitempty"`
-	DHCP        *DHCPConf     `yaml:"dhcp,omitempty"`
-	TFTP        *TFTPConf     `yaml:"tftp,omitempty"`
-	NFS         *NFSConf      `yaml:"nfs,omitempty"`
-	SSH         *SSHConf      `yaml:"ssh,omitempty"`
-	MountsImage []*MountEntry `yaml:"image mounts,omitempty" default:"[{\"source\": \"/etc/resolv.conf\", \"dest\": \"/etc/resolv.conf\"}]"`
-	Paths       *BuildConfig  `yaml:"paths,omitempty"`
-	WWClient    *WWClientConf `yaml:"wwclient,omitempty"`
+	Comment     string                  `yaml:"comment,omitempty"`
+	Ipaddr      string                  `yaml:"ipaddr,omitempty"`
+	Netmask     string                  `yaml:"netmask,omitempty"`
+	Network     string                  `yaml:"network,omitempty"`
+	Fqdn        string                  `yaml:"fqdn,omitempty"`
+	Ipaddr6     string                  `yaml:"ipaddr6,omitempty"`
+	PrefixLen6  string                  `yaml:"prefixlen6,omitempty"`
+	Warewulf    *WarewulfConf           `yaml:"warewulf,omitempty"`
+	API         *APIConf                `yaml:"api,omitempty"`
+	DHCP        *DHCPConf               `yaml:"dhcp,omitempty"`
+	TFTP        *TFTPConf               `yaml:"tftp,omitempty"`
+	NFS         *NFSConf                `yaml:"nfs,omitempty"`
+	SSH         *SSHConf                `yaml:"ssh,omitempty"`
+	MountsImage []*MountEntry           `yaml:"image mounts,omitempty" default:"[{\"source\": \"/etc/resolv.conf\", \"dest\": \"/etc/resolv.conf\"}]"`
+	Paths       *BuildConfig            `yaml:"paths,omitempty"`
+	WWClient    *WWClientConf           `yaml:"wwclient,omitempty"`
+	Pools       map[string]*AddressPool `yaml:"address pools,omitempty"`
+	Attributes  map[string]*Attribute   `yaml:"attributes,omitempty"`
 
 	warewulfconf string
 	autodetected bool
@@ -18,8 +18,11 @@ func TestParse(t *testing.T) {
 warewulf:
   autobuild overlays: true
   grubboot: false
+  history: false
+  history limit: 100
   host overlay: true
   port: 9873
+  prebuild overlays: false
   secure: true
   tls port: 9874
   update interval: 60
@@ -64,8 +67,11 @@ netmask: 255.255.255.0
 warewulf:
   autobuild overlays: true
   grubboot: false
+  history: false
+  history limit: 100
   host overlay: true
   port: 9873
+  prebuild overlays: false
   secure: true
   tls port: 9874
   update interval: 60
@@ -112,8 +118,11 @@ netmask: 255.255.0.0
 warewulf:
   autobuild overlays: true
   grubboot: false
+  history: false
+  history limit: 100
   host overlay: true
   port: 9873
+  prebuild overlays: false
   secure: true
   tls port: 9874
   update interval: 60
@@ -157,8 +166,11 @@ prefixlen6: "64"
 warewulf:
   autobuild overlays: true
   grubboot: false
+  history: false
+  history limit: 100
   host overlay: true
   port: 9873
+  prebuild overlays: false
   secure: true
   tls port: 9874
   update interval: 60
@@ -231,8 +243,11 @@ network: 192.168.200.0
 warewulf:
   autobuild overlays: true
   grubboot: false
+  history: false
+  history limit: 100
   host overlay: true
   port: 9873
+  prebuild overlays: false
   secure: false
   tls port: 9874
   update interval: 60
@@ -475,3 +490,31 @@ func TestIpCIDR6(t *testing.T) {
 		})
 	}
 }
+
+func TestCompressionFormats(t *testing.T) {
+	tests := map[string]struct {
+		compression []string
+		formats     []string
+	}{
+		"default": {
+			compression: nil,
+			formats:     []string{"gz"},
+		},
+		"zstd": {
+			compression: []string{"zstd"},
+			formats:     []string{"gz", "zstd"},
+		},
+		"gz listed": {
+			compression: []string{"xz", "gz"},
+			formats:     []string{"gz", "xz"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf := New()
+			conf.Warewulf.Compression = tt.compression
+			assert.Equal(t, tt.formats, conf.Warewulf.CompressionFormats())
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// apiTokenPrefix identifies Warewulf API tokens, e.g., for secret scanners.
+const apiTokenPrefix = "wwt_"
+
+// APIToken is a named bearer token for the REST API. Only a SHA-256 hash of
+// the token secret is stored: tokens are long random strings, so a slow
+// password hash is not needed to protect them.
+type APIToken struct {
+	Name    string `json:"name"              yaml:"name"`
+	Hash    string `json:"hash"              yaml:"hash"`
+	Scope   Role   `json:"scope,omitempty"   yaml:"scope,omitempty"`
+	Created int64  `json:"created"           yaml:"created"`
+	Expires int64  `json:"expires,omitempty" yaml:"expires,omitempty"`
+}
+
+// Expired returns true if the token has an expiry time before now.
+func (token APIToken) Expired(now time.Time) bool {
+	return token.Expires != 0 && now.Unix() >= token.Expires
+}
+
+// GetScope returns the scope of the token, defaulting to [RoleAdmin].
+func (token APIToken) GetScope() Role {
+	if token.Scope == "" {
+		return RoleAdmin
+	}
+	return token.Scope
+}
+
+// User returns the API user that requests authenticated with the token act
+// as. The user's role is the token's scope.
+func (token APIToken) User() *User {
+	return &User{Name: "token:" + token.Name, Role: token.Scope}
+}
+
+// APITokens is the set of API tokens stored in tokens.conf.
+type APITokens struct {
+	Tokens []APIToken `json:"tokens" yaml:"tokens"`
+}
+
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ReadAPITokens reads API tokens from fileName. A missing file holds no
+// tokens.
+func ReadAPITokens(fileName string) (*APITokens, error) {
+	tokens := new(APITokens)
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, tokens); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", fileName, err)
+	}
+	for _, token := range tokens.Tokens {
+		if !token.Scope.Valid() {
+			return nil, fmt.Errorf("invalid scope for token %s: %s", token.Name, token.Scope)
+		}
+	}
+	return tokens, nil
+}
+
+// Write stores the tokens in fileName, readable only by its owner.
+func (tokens *APITokens) Write(fileName string) error {
+	data, err := yaml.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(path.Dir(fileName), ".tokens.conf-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), fileName)
+}
+
+// Get returns the token with the given name.
+func (tokens *APITokens) Get(name string) (APIToken, bool) {
+	for _, token := range tokens.Tokens {
+		if token.Name == name {
+			return token, true
+		}
+	}
+	return APIToken{}, false
+}
+
+// Create adds a new token and returns its secret, which is not stored and
+// cannot be recovered later. A zero expires creates a token that does not
+// expire.
+func (tokens *APITokens) Create(name string, scope Role, expires time.Time) (string, error) {
+	if name == "" || strings.ContainsAny(name, " \t\n:") {
+		return "", fmt.Errorf("invalid token name: %q", name)
+	}
+	if !scope.Valid() {
+		return "", fmt.Errorf("invalid token scope: %s", scope)
+	}
+	if _, ok := tokens.Get(name); ok {
+		return "", fmt.Errorf("token already exists: %s", name)
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	secret := apiTokenPrefix + hex.EncodeToString(buf)
+	token := APIToken{
+		Name:    name,
+		Hash:    hashAPIToken(secret),
+		Scope:   scope,
+		Created: time.Now().Unix(),
+	}
+	if !expires.IsZero() {
+		token.Expires = expires.Unix()
+	}
+	tokens.Tokens = append(tokens.Tokens, token)
+	return secret, nil
+}
+
+// Revoke removes the token with the given name.
+func (tokens *APITokens) Revoke(name string) error {
+	for i, token := range tokens.Tokens {
+		if token.Name == name {
+			tokens.Tokens = append(tokens.Tokens[:i], tokens.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("token not found: %s", name)
+}
+
+// Authenticate returns the unexpired token matching secret.
+func (tokens *APITokens) Authenticate(secret string) (*APIToken, error) {
+	hash := hashAPIToken(secret)
+	now := time.Now()
+	for _, token := range tokens.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hash)) == 1 {
+			if token.Expired(now) {
+				return nil, UnauthorizedError
+			}
+			return &token, nil
+		}
+	}
+	return nil, UnauthorizedError
+}
@@ -0,0 +1,304 @@
+package cpio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Options control how WriteFiles archives files.
+type Options struct {
+	// Mtime, if not zero, replaces the modification time of every file,
+	// so that archives of the same files are identical.
+	Mtime time.Time
+	// XattrsFile, if set, is the name of an entry that lists the extended
+	// attributes of the archived files, which newc archives cannot hold, in
+	// the format of "getfattr --dump", to be restored with
+	// "setfattr --restore". SELinux labels are not listed. The entry is
+	// only written if a file has extended attributes.
+	XattrsFile string
+	// OnFile, if set, is called with each entry of the archive, including
+	// the XattrsFile entry. The links of a hard-linked file are reported
+	// together, once its content is written.
+	OnFile func(File)
+}
+
+// A File is an entry of an archive written by WriteFiles.
+type File struct {
+	Header
+	// Digest is the SHA-256 digest of the content of a regular file, or of
+	// the target of a symbolic link.
+	Digest []byte
+}
+
+// linkKey identifies a file with hard links.
+type linkKey struct {
+	dev uint64
+	ino uint64
+}
+
+type archiveFile struct {
+	name string
+	stat unix.Stat_t
+}
+
+/*
+WriteFiles writes the files, given relative to rootdir, to a newc archive in
+w, sorted by name. Inode numbers are assigned in order, so that they do not
+depend on, or collide because of, the file system the files are on. Hard
+links within the archive share an inode number, and the content of a
+hard-linked file is written with its last link, as GNU cpio does.
+*/
+func WriteFiles(w io.Writer, rootdir string, files []string, opts Options) error {
+	archiveFiles := make([]archiveFile, 0, len(files))
+	for _, name := range files {
+		name = strings.TrimPrefix(filepath.Clean("/"+name), "/")
+		if name == "" {
+			continue
+		}
+		file := archiveFile{name: name}
+		if err := unix.Lstat(filepath.Join(rootdir, name), &file.stat); err != nil {
+			return fmt.Errorf("could not stat %s: %w", name, err)
+		}
+		archiveFiles = append(archiveFiles, file)
+	}
+	sort.Slice(archiveFiles, func(i, j int) bool {
+		return archiveFiles[i].name < archiveFiles[j].name
+	})
+
+	// hard links are only counted within the archive
+	links := make(map[linkKey]uint32)
+	var newest int64
+	for _, file := range archiveFiles {
+		if file.stat.Mode&unix.S_IFMT == unix.S_IFREG && file.stat.Nlink > 1 {
+			links[linkKey{uint64(file.stat.Dev), file.stat.Ino}]++
+		}
+		newest = max(newest, int64(file.stat.Mtim.Sec))
+	}
+
+	cw := NewWriter(w)
+	var xattrs bytes.Buffer
+	inodes := make(map[linkKey]uint32)
+	written := make(map[linkKey]uint32)
+	pending := make(map[linkKey][]Header)
+	report := func(hdr Header, digest []byte) {
+		if opts.OnFile != nil {
+			opts.OnFile(File{Header: hdr, Digest: digest})
+		}
+	}
+	nextIno := uint32(1)
+	for _, file := range archiveFiles {
+		fullPath := filepath.Join(rootdir, file.name)
+		hdr := &Header{
+			Name:      file.name,
+			Mode:      file.stat.Mode,
+			UID:       file.stat.Uid,
+			GID:       file.stat.Gid,
+			Nlink:     1,
+			Mtime:     int64(file.stat.Mtim.Sec),
+			Rdevmajor: unix.Major(uint64(file.stat.Rdev)),
+			Rdevminor: unix.Minor(uint64(file.stat.Rdev)),
+		}
+		if !opts.Mtime.IsZero() {
+			hdr.Mtime = opts.Mtime.Unix()
+		}
+		key := linkKey{uint64(file.stat.Dev), file.stat.Ino}
+		if count, ok := links[key]; ok {
+			hdr.Nlink = count
+			if _, ok := inodes[key]; !ok {
+				inodes[key] = nextIno
+				nextIno++
+			}
+			hdr.Ino = inodes[key]
+			written[key]++
+		} else {
+			hdr.Ino = nextIno
+			nextIno++
+		}
+
+		if opts.XattrsFile != "" {
+			if err := dumpXattrs(&xattrs, fullPath, file.name); err != nil {
+				return err
+			}
+		}
+
+		switch file.stat.Mode & unix.S_IFMT {
+		case unix.S_IFLNK:
+			target, err := os.Readlink(fullPath)
+			if err != nil {
+				return fmt.Errorf("could not read link %s: %w", file.name, err)
+			}
+			hdr.Size = int64(len(target))
+			if err := cw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(cw, target); err != nil {
+				return err
+			}
+			digest := sha256.Sum256([]byte(target))
+			report(*hdr, digest[:])
+		case unix.S_IFREG:
+			if hdr.Nlink > 1 && written[key] < hdr.Nlink {
+				// the content follows with the last link
+				if err := cw.WriteHeader(hdr); err != nil {
+					return err
+				}
+				link := *hdr
+				link.Size = file.stat.Size
+				pending[key] = append(pending[key], link)
+				continue
+			}
+			hdr.Size = file.stat.Size
+			digest := sha256.New()
+			if err := writeFile(cw, hdr, fullPath, digest); err != nil {
+				return err
+			}
+			for _, link := range pending[key] {
+				report(link, digest.Sum(nil))
+			}
+			delete(pending, key)
+			report(*hdr, digest.Sum(nil))
+		default:
+			if err := cw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			report(*hdr, nil)
+		}
+	}
+
+	if xattrs.Len() > 0 {
+		hdr := &Header{
+			Name:  opts.XattrsFile,
+			Ino:   nextIno,
+			Mode:  TypeReg | 0o600,
+			Nlink: 1,
+			Mtime: newest,
+			Size:  int64(xattrs.Len()),
+		}
+		if !opts.Mtime.IsZero() {
+			hdr.Mtime = opts.Mtime.Unix()
+		}
+		if err := cw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		digest := sha256.Sum256(xattrs.Bytes())
+		if _, err := xattrs.WriteTo(cw); err != nil {
+			return err
+		}
+		report(*hdr, digest[:])
+	}
+	return cw.Close()
+}
+
+// writeFile writes the file at fullPath as the entry hdr, and its content to
+// digest.
+func writeFile(cw *Writer, hdr *Header, fullPath string, digest hash.Hash) error {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	if err := cw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	written, err := io.Copy(io.MultiWriter(cw, digest), file)
+	if err != nil {
+		return fmt.Errorf("could not archive %s: %w", hdr.Name, err)
+	}
+	if written != hdr.Size {
+		return fmt.Errorf("could not archive %s: file changed size while it was archived", hdr.Name)
+	}
+	return nil
+}
+
+// dumpXattrs writes the extended attributes of fullPath, other than its
+// SELinux label, to buf in the format of "getfattr --dump".
+func dumpXattrs(buf *bytes.Buffer, fullPath string, name string) error {
+	names, err := listXattrs(fullPath)
+	if err != nil {
+		if errors.Is(err, unix.ENOTSUP) {
+			return nil
+		}
+		return fmt.Errorf("could not list extended attributes of %s: %w", name, err)
+	}
+	sort.Strings(names)
+	var attrs []string
+	for _, attr := range names {
+		if attr == "security.selinux" {
+			// labelled on the node
+			continue
+		}
+		value, err := getXattr(fullPath, attr)
+		if err != nil {
+			return fmt.Errorf("could not read extended attribute %s of %s: %w", attr, name, err)
+		}
+		attrs = append(attrs, fmt.Sprintf("%s=0x%s\n", attr, hex.EncodeToString(value)))
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	fmt.Fprintf(buf, "# file: %s\n", escapeName(name))
+	for _, attr := range attrs {
+		buf.WriteString(attr)
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+func listXattrs(fullPath string) ([]string, error) {
+	size, err := unix.Llistxattr(fullPath, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(fullPath, buf)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, attr := range bytes.Split(buf[:size], []byte{0}) {
+		if len(attr) > 0 {
+			names = append(names, string(attr))
+		}
+	}
+	return names, nil
+}
+
+func getXattr(fullPath string, attr string) ([]byte, error) {
+	size, err := unix.Lgetxattr(fullPath, attr, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Lgetxattr(fullPath, attr, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+// escapeName escapes a file name as getfattr does: control characters,
+// backslashes, and non-ASCII bytes are written as octal escapes.
+func escapeName(name string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < 0x20 || c == '\\' || c >= 0x7f {
+			fmt.Fprintf(&escaped, "\\%03o", c)
+		} else {
+			escaped.WriteByte(c)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,182 @@
+/*
+Package cpio writes cpio archives in the newc ("new ASCII") format, which
+the Linux kernel reads as an initramfs and GNU cpio extracts with "cpio -i".
+*/
+package cpio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// File type bits of Header.Mode, as in stat(2).
+const (
+	TypeMask    = 0o170000
+	TypeSocket  = 0o140000
+	TypeSymlink = 0o120000
+	TypeReg     = 0o100000
+	TypeBlock   = 0o060000
+	TypeDir     = 0o040000
+	TypeChar    = 0o020000
+	TypeFifo    = 0o010000
+)
+
+const (
+	magic   = "070701"
+	trailer = "TRAILER!!!"
+	// headerSize is the length of the magic and the 13 header fields
+	headerSize = 6 + 13*8
+	// blockSize is the size of the blocks that archives are padded to, as
+	// by GNU cpio
+	blockSize = 512
+)
+
+var (
+	// ErrWriteTooLong is returned if more data is written to an entry than
+	// its header declares.
+	ErrWriteTooLong = errors.New("cpio: write too long")
+	// ErrWriteAfterClose is returned if an archive is written after it is
+	// closed.
+	ErrWriteAfterClose = errors.New("cpio: write after close")
+)
+
+// Header is the header of an entry in a newc archive. All numbers are
+// limited to 32 bits.
+type Header struct {
+	Name string
+	Ino  uint32
+	// Mode holds the file type and permission bits.
+	Mode      uint32
+	UID       uint32
+	GID       uint32
+	Nlink     uint32
+	Mtime     int64
+	Size      int64
+	Devmajor  uint32
+	Devminor  uint32
+	Rdevmajor uint32
+	Rdevminor uint32
+}
+
+// Writer writes a newc archive. Each entry is started with WriteHeader,
+// followed by exactly Size bytes of data written with Write.
+type Writer struct {
+	w         io.Writer
+	written   int64
+	remaining int64
+	pad       int64
+	closed    bool
+}
+
+// NewWriter returns a Writer that writes an archive to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteHeader finishes the current entry and starts a new one.
+func (cw *Writer) WriteHeader(hdr *Header) error {
+	if cw.closed {
+		return ErrWriteAfterClose
+	}
+	if err := cw.finishEntry(); err != nil {
+		return err
+	}
+	if hdr.Size < 0 || hdr.Size > math.MaxUint32 {
+		return fmt.Errorf("cpio: %s: size %d does not fit the newc format", hdr.Name, hdr.Size)
+	}
+	if hdr.Mtime < 0 || hdr.Mtime > math.MaxUint32 {
+		return fmt.Errorf("cpio: %s: modification time %d does not fit the newc format", hdr.Name, hdr.Mtime)
+	}
+	nameSize := len(hdr.Name) + 1
+	header := fmt.Sprintf("%s%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+		magic,
+		hdr.Ino,
+		hdr.Mode,
+		hdr.UID,
+		hdr.GID,
+		hdr.Nlink,
+		hdr.Mtime,
+		hdr.Size,
+		hdr.Devmajor,
+		hdr.Devminor,
+		hdr.Rdevmajor,
+		hdr.Rdevminor,
+		nameSize,
+		0) // check, only used by the crc format
+	if err := cw.write([]byte(header + hdr.Name + "\x00")); err != nil {
+		return err
+	}
+	if err := cw.writePadding(padding(headerSize + int64(nameSize))); err != nil {
+		return err
+	}
+	cw.remaining = hdr.Size
+	cw.pad = padding(hdr.Size)
+	return nil
+}
+
+// Write writes data of the current entry.
+func (cw *Writer) Write(p []byte) (n int, err error) {
+	if cw.closed {
+		return 0, ErrWriteAfterClose
+	}
+	tooLong := false
+	if int64(len(p)) > cw.remaining {
+		p = p[:cw.remaining]
+		tooLong = true
+	}
+	n, err = cw.w.Write(p)
+	cw.written += int64(n)
+	cw.remaining -= int64(n)
+	if err == nil && tooLong {
+		err = ErrWriteTooLong
+	}
+	return n, err
+}
+
+// Close finishes the current entry and writes the trailer of the archive,
+// padded to a multiple of 512 bytes. It does not close the underlying
+// writer.
+func (cw *Writer) Close() error {
+	if cw.closed {
+		return nil
+	}
+	if err := cw.WriteHeader(&Header{Name: trailer, Nlink: 1}); err != nil {
+		return err
+	}
+	if err := cw.writePadding((blockSize - cw.written%blockSize) % blockSize); err != nil {
+		return err
+	}
+	cw.closed = true
+	return nil
+}
+
+func (cw *Writer) finishEntry() error {
+	if cw.remaining > 0 {
+		return fmt.Errorf("cpio: missing %d bytes of entry data", cw.remaining)
+	}
+	if err := cw.writePadding(cw.pad); err != nil {
+		return err
+	}
+	cw.pad = 0
+	return nil
+}
+
+func (cw *Writer) writePadding(n int64) error {
+	if n == 0 {
+		return nil
+	}
+	return cw.write(make([]byte, n))
+}
+
+func (cw *Writer) write(p []byte) error {
+	n, err := cw.w.Write(p)
+	cw.written += int64(n)
+	return err
+}
+
+// padding returns the number of bytes that pad size to a multiple of four.
+func padding(size int64) int64 {
+	return (4 - size%4) % 4
+}
@@ -0,0 +1,238 @@
+package cpio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+type entry struct {
+	Header
+	data string
+}
+
+// readArchive parses a newc archive, up to and excluding its trailer.
+func readArchive(t *testing.T, archive []byte) (entries []entry) {
+	must := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := bytes.NewReader(archive)
+	offset := int64(0)
+	skip := func(n int64) {
+		_, err := r.Seek(n, io.SeekCurrent)
+		must(err)
+		offset += n
+	}
+	for {
+		raw := make([]byte, headerSize)
+		_, err := io.ReadFull(r, raw)
+		must(err)
+		offset += headerSize
+		if string(raw[:6]) != magic {
+			t.Fatalf("bad magic at offset %d: %q", offset-headerSize, raw[:6])
+		}
+		field := func(i int) uint32 {
+			value, err := strconv.ParseUint(string(raw[6+i*8:6+(i+1)*8]), 16, 32)
+			must(err)
+			return uint32(value)
+		}
+		hdr := Header{
+			Ino:       field(0),
+			Mode:      field(1),
+			UID:       field(2),
+			GID:       field(3),
+			Nlink:     field(4),
+			Mtime:     int64(field(5)),
+			Size:      int64(field(6)),
+			Devmajor:  field(7),
+			Devminor:  field(8),
+			Rdevmajor: field(9),
+			Rdevminor: field(10),
+		}
+		name := make([]byte, field(11))
+		_, err = io.ReadFull(r, name)
+		must(err)
+		offset += int64(len(name))
+		hdr.Name = string(name[:len(name)-1])
+		skip(padding(offset))
+		data := make([]byte, hdr.Size)
+		_, err = io.ReadFull(r, data)
+		must(err)
+		offset += hdr.Size
+		skip(padding(offset))
+		if hdr.Name == trailer {
+			assert.Equal(t, 0, len(archive)%blockSize)
+			rest, err := io.ReadAll(r)
+			must(err)
+			assert.Equal(t, make([]byte, len(rest)), rest)
+			return entries
+		}
+		entries = append(entries, entry{Header: hdr, data: string(data)})
+	}
+}
+
+func Test_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	cw := NewWriter(&buf)
+	assert.NoError(t, cw.WriteHeader(&Header{Name: "dir", Mode: TypeDir | 0o755, Nlink: 2, Ino: 1}))
+	assert.NoError(t, cw.WriteHeader(&Header{Name: "dir/file", Mode: TypeReg | 0o644, Nlink: 1, Ino: 2, Size: 5, Mtime: 1700000000}))
+	_, err := cw.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, cw.Close())
+	assert.Equal(t, blockSize, buf.Len())
+
+	entries := readArchive(t, buf.Bytes())
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "dir", entries[0].Name)
+	assert.Equal(t, uint32(TypeDir|0o755), entries[0].Mode)
+	assert.Equal(t, "dir/file", entries[1].Name)
+	assert.Equal(t, int64(1700000000), entries[1].Mtime)
+	assert.Equal(t, "hello", entries[1].data)
+
+	_, err = cw.Write([]byte("more"))
+	assert.ErrorIs(t, err, ErrWriteAfterClose)
+}
+
+func Test_Writer_Errors(t *testing.T) {
+	cw := NewWriter(io.Discard)
+	assert.NoError(t, cw.WriteHeader(&Header{Name: "file", Mode: TypeReg, Size: 2}))
+	n, err := cw.Write([]byte("abc"))
+	assert.Equal(t, 2, n)
+	assert.ErrorIs(t, err, ErrWriteTooLong)
+
+	cw = NewWriter(io.Discard)
+	assert.NoError(t, cw.WriteHeader(&Header{Name: "file", Mode: TypeReg, Size: 2}))
+	assert.ErrorContains(t, cw.WriteHeader(&Header{Name: "next"}), "missing 2 bytes")
+
+	cw = NewWriter(io.Discard)
+	assert.ErrorContains(t, cw.WriteHeader(&Header{Name: "big", Size: 1 << 32}), "does not fit the newc format")
+}
+
+func Test_WriteFiles(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "etc/hosts"), []byte("127.0.0.1 localhost\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "a"), []byte("linked"), 0o600))
+	assert.NoError(t, os.Link(filepath.Join(root, "a"), filepath.Join(root, "b")))
+	assert.NoError(t, os.Link(filepath.Join(root, "a"), filepath.Join(root, "excluded")))
+	assert.NoError(t, os.Symlink("etc/hosts", filepath.Join(root, "hosts")))
+	assert.NoError(t, unix.Mkfifo(filepath.Join(root, "fifo"), 0o600))
+
+	var buf bytes.Buffer
+	mtime := time.Unix(1700000000, 0)
+	files := []string{"hosts", "etc/hosts", "etc", "b", "a", "fifo"}
+	assert.NoError(t, WriteFiles(&buf, root, files, Options{Mtime: mtime}))
+	entries := readArchive(t, buf.Bytes())
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+		assert.Equal(t, mtime.Unix(), e.Mtime)
+		assert.Equal(t, uint32(os.Getuid()), e.UID)
+	}
+	assert.Equal(t, []string{"a", "b", "etc", "etc/hosts", "fifo", "hosts"}, names)
+
+	// hard links share an inode, and the content is written with the last
+	// link; links outside the archive are not counted
+	assert.Equal(t, entries[0].Ino, entries[1].Ino)
+	assert.Equal(t, uint32(2), entries[0].Nlink)
+	assert.Equal(t, uint32(2), entries[1].Nlink)
+	assert.Equal(t, "", entries[0].data)
+	assert.Equal(t, "linked", entries[1].data)
+	assert.Equal(t, uint32(TypeReg|0o600), entries[1].Mode)
+
+	assert.Equal(t, uint32(TypeDir|0o755), entries[2].Mode)
+	assert.Equal(t, "127.0.0.1 localhost\n", entries[3].data)
+	assert.Equal(t, uint32(TypeFifo), entries[4].Mode&TypeMask)
+	assert.Equal(t, uint32(TypeSymlink), entries[5].Mode&TypeMask)
+	assert.Equal(t, "etc/hosts", entries[5].data)
+
+	// inodes are numbered in order
+	assert.Equal(t, []uint32{1, 1, 2, 3, 4, 5},
+		[]uint32{entries[0].Ino, entries[1].Ino, entries[2].Ino, entries[3].Ino, entries[4].Ino, entries[5].Ino})
+
+	// the archive is reproducible
+	var again bytes.Buffer
+	assert.NoError(t, WriteFiles(&again, root, []string{"fifo", "a", "b", "etc", "etc/hosts", "hosts"}, Options{Mtime: mtime}))
+	assert.Equal(t, buf.Bytes(), again.Bytes())
+}
+
+func Test_WriteFiles_OnFile(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "a"), []byte("linked"), 0o600))
+	assert.NoError(t, os.Link(filepath.Join(root, "a"), filepath.Join(root, "b")))
+	assert.NoError(t, os.Symlink("a", filepath.Join(root, "c")))
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "d"), 0o755))
+
+	files := make(map[string]File)
+	assert.NoError(t, WriteFiles(io.Discard, root, []string{"a", "b", "c", "d"}, Options{
+		OnFile: func(file File) { files[file.Name] = file },
+	}))
+	assert.Len(t, files, 4)
+
+	// both links report the size and digest of the content
+	linked := sha256.Sum256([]byte("linked"))
+	assert.Equal(t, int64(6), files["a"].Size)
+	assert.Equal(t, linked[:], files["a"].Digest)
+	assert.Equal(t, int64(6), files["b"].Size)
+	assert.Equal(t, linked[:], files["b"].Digest)
+
+	target := sha256.Sum256([]byte("a"))
+	assert.Equal(t, target[:], files["c"].Digest)
+	assert.Equal(t, uint32(TypeDir|0o755), files["d"].Mode)
+	assert.Nil(t, files["d"].Digest)
+}
+
+func Test_WriteFiles_Device(t *testing.T) {
+	root := t.TempDir()
+	if err := unix.Mknod(filepath.Join(root, "null"), unix.S_IFCHR|0o666, int(unix.Mkdev(1, 3))); err != nil {
+		t.Skipf("cannot create device node: %s", err)
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteFiles(&buf, root, []string{"null"}, Options{}))
+	entries := readArchive(t, buf.Bytes())
+	assert.Len(t, entries, 1)
+	assert.Equal(t, uint32(TypeChar), entries[0].Mode&TypeMask)
+	assert.Equal(t, uint32(1), entries[0].Rdevmajor)
+	assert.Equal(t, uint32(3), entries[0].Rdevminor)
+}
+
+func Test_WriteFiles_Xattrs(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "ping"), []byte("binary"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "plain"), []byte("file"), 0o644))
+	if err := unix.Setxattr(filepath.Join(root, "ping"), "user.test", []byte("value"), 0); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EPERM) {
+			t.Skipf("cannot set extended attributes: %s", err)
+		}
+		assert.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteFiles(&buf, root, []string{"ping", "plain"}, Options{XattrsFile: ".xattrs"}))
+	entries := readArchive(t, buf.Bytes())
+	assert.Len(t, entries, 3)
+	assert.Equal(t, ".xattrs", entries[2].Name)
+	assert.Equal(t, "# file: ping\nuser.test=0x76616c7565\n\n", entries[2].data)
+
+	// without extended attributes, no entry is written
+	buf.Reset()
+	assert.NoError(t, WriteFiles(&buf, root, []string{"plain"}, Options{XattrsFile: ".xattrs"}))
+	assert.Len(t, readArchive(t, buf.Bytes()), 1)
+}
+
+func Test_escapeName(t *testing.T) {
+	assert.Equal(t, "usr/bin/ping", escapeName("usr/bin/ping"))
+	assert.Equal(t, "a\\012b\\134c\\303\\251", escapeName("a\nb\\cé"))
+}
@@ -0,0 +1,253 @@
+// Package history keeps snapshots of nodes.conf and of the files in site
+// overlays each time they are changed, so that changes may be reviewed and
+// rolled back. History is kept only if "history" is enabled in
+// warewulf.conf.
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// NodesConf is the File of versions of nodes.conf.
+const NodesConf = "nodes.conf"
+
+// InitialCommand is the Command of the version recorded for the content a
+// file had before its first recorded change.
+const InitialCommand = "(initial)"
+
+// A Version is a snapshot of nodes.conf, of a fragment it includes, or of a
+// file in an overlay, after a change.
+type Version struct {
+	ID      int       `json:"id"`
+	Time    time.Time `json:"time"`
+	User    string    `json:"user,omitempty"`
+	Command string    `json:"command"`
+	Overlay string    `json:"overlay,omitempty"`
+	File    string    `json:"file"`
+}
+
+// Target identifies the file of which v is a version: "nodes.conf", a
+// fragment such as "nodes.conf.d/rack1.conf", or OVERLAY:FILE.
+func (v Version) Target() string {
+	if v.Overlay != "" {
+		return v.Overlay + ":" + v.File
+	}
+	return v.File
+}
+
+// Content returns the content of the file at version v.
+func (v Version) Content() ([]byte, error) {
+	return os.ReadFile(path.Join(versionDir(v.ID), "content"))
+}
+
+// Enabled returns true if history is enabled in warewulf.conf.
+func Enabled() bool {
+	return config.Get().Warewulf.History()
+}
+
+func versionDir(id int) string {
+	return path.Join(config.Get().Paths.HistoryDir(), fmt.Sprintf("%08d", id))
+}
+
+// RecordNodesConf records a change to nodes.conf from before to after. A nil
+// before indicates that the file did not exist.
+func RecordNodesConf(before, after []byte) {
+	record(Version{File: NodesConf}, before, after)
+}
+
+// RecordNodesFragment records a change to a fragment of the node
+// configuration included by nodes.conf, named relative to the directory of
+// nodes.conf.
+func RecordNodesFragment(file string, before, after []byte) {
+	record(Version{File: file}, before, after)
+}
+
+// RecordOverlayFile records a change to file in overlay from before to
+// after. A nil before indicates that the file did not exist.
+func RecordOverlayFile(overlay, file string, before, after []byte) {
+	record(Version{Overlay: overlay, File: path.Clean("/" + file)}, before, after)
+}
+
+// record records a version, warning rather than failing if it cannot be
+// written: the change it describes has already been made.
+func record(v Version, before, after []byte) {
+	if !Enabled() {
+		return
+	}
+	if err := Record(v, before, after); err != nil {
+		wwlog.Warn("Could not record history of %s: %s", v.Target(), err)
+	}
+}
+
+// Record records the change of the file identified by v from before to
+// after, setting the ID, time, user, and command of v if unset. If the file
+// has no history yet, its content before the change is recorded as well, so
+// that the change may be rolled back. Nothing is recorded if the content is
+// unchanged since the file's last version.
+func Record(v Version, before, after []byte) error {
+	historyDir := config.Get().Paths.HistoryDir()
+	if err := os.MkdirAll(historyDir, 0700); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(path.Join(historyDir, ".lock"), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	// the lock is released when the file is closed
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+
+	versions, err := List()
+	if err != nil {
+		return err
+	}
+	nextID := 1
+	if len(versions) > 0 {
+		nextID = versions[len(versions)-1].ID + 1
+	}
+	var latest *Version
+	for i := range versions {
+		if versions[i].Target() == v.Target() {
+			latest = &versions[i]
+		}
+	}
+	if latest == nil && before != nil && !bytes.Equal(before, after) {
+		initial := v
+		initial.ID = nextID
+		initial.Time = time.Now()
+		initial.User = ""
+		initial.Command = InitialCommand
+		if err := write(initial, before); err != nil {
+			return err
+		}
+		nextID++
+	} else if latest != nil {
+		if content, err := latest.Content(); err == nil && bytes.Equal(content, after) {
+			return nil
+		}
+	}
+
+	v.ID = nextID
+	if v.Time.IsZero() {
+		v.Time = time.Now()
+	}
+	if v.User == "" {
+		v.User = util.CurrentUser()
+	}
+	if v.Command == "" {
+		v.Command = strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " ")
+	}
+	if err := write(v, after); err != nil {
+		return err
+	}
+	return prune()
+}
+
+func write(v Version, content []byte) error {
+	dir := versionDir(v.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path.Join(dir, "content"), content, 0600); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, "version.json"), data, 0600)
+}
+
+// prune removes the oldest versions beyond the "history limit" configured in
+// warewulf.conf.
+func prune() error {
+	limit := config.Get().Warewulf.HistoryLimit
+	if limit <= 0 {
+		return nil
+	}
+	versions, err := List()
+	if err != nil {
+		return err
+	}
+	for len(versions) > limit {
+		if err := os.RemoveAll(versionDir(versions[0].ID)); err != nil {
+			return err
+		}
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// List returns every recorded version, oldest first.
+func List() ([]Version, error) {
+	var versions []Version
+	entries, err := os.ReadDir(config.Get().Paths.HistoryDir())
+	if os.IsNotExist(err) {
+		return versions, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		id, err := strconv.Atoi(entry.Name())
+		if !entry.IsDir() || err != nil {
+			continue
+		}
+		v, err := Get(id)
+		if err != nil {
+			wwlog.Warn("Skipping invalid history version %s: %s", entry.Name(), err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID < versions[j].ID })
+	return versions, nil
+}
+
+// Get returns the version with the given ID.
+func Get(id int) (v Version, err error) {
+	data, err := os.ReadFile(path.Join(versionDir(id), "version.json"))
+	if os.IsNotExist(err) {
+		return v, fmt.Errorf("version not found: %d", id)
+	} else if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// Previous returns the version of the same file that precedes v, or nil if v
+// is the file's first version.
+func Previous(v Version) (*Version, error) {
+	versions, err := List()
+	if err != nil {
+		return nil, err
+	}
+	var previous *Version
+	for i := range versions {
+		if versions[i].ID >= v.ID {
+			break
+		}
+		if versions[i].Target() == v.Target() {
+			previous = &versions[i]
+		}
+	}
+	return previous, nil
+}
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func versionContents(t *testing.T) (contents []string) {
+	versions, err := List()
+	assert.NoError(t, err)
+	for _, v := range versions {
+		content, err := v.Content()
+		assert.NoError(t, err)
+		contents = append(contents, v.Target()+"="+string(content))
+	}
+	return contents
+}
+
+func Test_Record_Disabled(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	RecordNodesConf([]byte("a"), []byte("b"))
+	assert.Empty(t, versionContents(t))
+}
+
+func Test_Record(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	enabled := true
+	conf := env.Configure()
+	conf.Warewulf.HistoryP = &enabled
+
+	RecordNodesConf([]byte("a"), []byte("b"))
+	assert.Equal(t, []string{"nodes.conf=a", "nodes.conf=b"}, versionContents(t))
+
+	// unchanged content is not recorded again
+	RecordNodesConf([]byte("b"), []byte("b"))
+	assert.Equal(t, []string{"nodes.conf=a", "nodes.conf=b"}, versionContents(t))
+
+	RecordNodesConf([]byte("b"), []byte("c"))
+	RecordOverlayFile("site", "etc/motd", nil, []byte("hello"))
+	RecordOverlayFile("site", "/etc/motd", []byte("hello"), []byte("goodbye"))
+	assert.Equal(t, []string{
+		"nodes.conf=a",
+		"nodes.conf=b",
+		"nodes.conf=c",
+		"site:/etc/motd=hello",
+		"site:/etc/motd=goodbye",
+	}, versionContents(t))
+
+	initial, err := Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, InitialCommand, initial.Command)
+	v, err := Get(3)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, v.User)
+	assert.NotEmpty(t, v.Command)
+
+	previous, err := Previous(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, previous.ID)
+	v, err = Get(4)
+	assert.NoError(t, err)
+	previous, err = Previous(v)
+	assert.NoError(t, err)
+	assert.Nil(t, previous)
+
+	_, err = Get(6)
+	assert.Error(t, err)
+}
+
+func Test_Record_Limit(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	enabled := true
+	conf := env.Configure()
+	conf.Warewulf.HistoryP = &enabled
+	conf.Warewulf.HistoryLimit = 2
+
+	RecordNodesConf([]byte("a"), []byte("b"))
+	RecordNodesConf([]byte("b"), []byte("c"))
+	assert.Equal(t, []string{"nodes.conf=b", "nodes.conf=c"}, versionContents(t))
+	versions, err := List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, versions[0].ID)
+}
@@ -105,6 +105,9 @@ func splitTopLevel(s string) []string {
 // Compress is the inverse of Expand: it returns a hostlist-style string in
 // which numeric suffixes sharing a prefix and zero-pad width are collapsed
 // into bracket notation. E.g. ["n01","n02","n03","n05"] -> "n[01-03,05]".
+// Numbers without leading zeros join a padded group of their own width, if
+// there is one, and otherwise an unpadded group, so that, e.g., n9 and n10
+// compress to "n[9-10]" and n09 and n10 to "n[09-10]", as Expand produces.
 // Names without a trailing digit run are emitted as-is.
 func Compress(ids []string) string {
 	type group struct {
@@ -114,33 +117,53 @@ func Compress(ids []string) string {
 		single   string
 		firstIdx int
 	}
-	groups := map[string]*group{}
-	var order []string
-	for idx, id := range ids {
+	split := func(id string) (prefix, digits string) {
 		i := len(id)
 		for i > 0 && id[i-1] >= '0' && id[i-1] <= '9' {
 			i--
 		}
+		return id[:i], id[i:]
+	}
+	padded := func(digits string) bool {
+		return len(digits) > 1 && digits[0] == '0'
+	}
+	paddedWidths := map[string]bool{}
+	for _, id := range ids {
+		if prefix, digits := split(id); padded(digits) {
+			paddedWidths[prefix+"\x00"+strconv.Itoa(len(digits))] = true
+		}
+	}
+
+	groups := map[string]*group{}
+	var order []string
+	for idx, id := range ids {
+		prefix, digits := split(id)
 		var key string
-		if i == len(id) {
+		width := 0
+		if digits == "" {
 			key = "\x00" + id
 		} else {
-			key = id[:i] + "\x00" + strconv.Itoa(len(id)-i)
+			width = len(digits)
+			key = prefix + "\x00" + strconv.Itoa(width)
+			if !padded(digits) && !paddedWidths[key] {
+				width = 1
+				key = prefix + "\x00" + strconv.Itoa(width)
+			}
 		}
 		g, ok := groups[key]
 		if !ok {
 			g = &group{firstIdx: idx}
-			if i == len(id) {
+			if digits == "" {
 				g.single = id
 			} else {
-				g.prefix = id[:i]
-				g.width = len(id) - i
+				g.prefix = prefix
+				g.width = width
 			}
 			groups[key] = g
 			order = append(order, key)
 		}
 		if g.width > 0 {
-			n, _ := strconv.Atoi(id[i:])
+			n, _ := strconv.Atoi(digits)
 			g.nums = append(g.nums, n)
 		}
 	}
@@ -87,10 +87,16 @@ func TestCompress(t *testing.T) {
 		"no digits":         {input: []string{"head", "login"}, output: "head,login"},
 		"cross-zero rollup": {input: []string{"n09", "n10", "n11"}, output: "n[09-11]"},
 		"out of order":      {input: []string{"n03", "n01", "n02"}, output: "n[01-03]"},
+		"unpadded rollup":   {input: []string{"n8", "n9", "n10", "n11"}, output: "n[8-11]"},
+		"unpadded and wide": {input: []string{"n1", "n10", "n100"}, output: "n[1,10,100]"},
+		"padded and wide":   {input: []string{"n098", "n099", "n100", "n1000"}, output: "n[098-100],n1000"},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			assert.Equal(t, tt.output, Compress(tt.input))
+			if tt.output != "" {
+				assert.ElementsMatch(t, tt.input, Expand([]string{tt.output}))
+			}
 		})
 	}
 }
@@ -1,14 +1,28 @@
 package image
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path"
+	"time"
 
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/cpio"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
+// XattrsFile is the file in which the extended attributes of an image's
+// files are recorded, to be restored by the wwinit overlay.
+const XattrsFile = ".warewulf-xattrs"
+
 func Build(name string, buildForce bool) error {
+	return BuildContext(context.Background(), name, buildForce)
+}
+
+// BuildContext builds an image, stopping early if ctx is cancelled.
+func BuildContext(ctx context.Context, name string, buildForce bool) error {
 	wwlog.Info("Building image: %s", name)
 
 	rootfsPath := RootFsDir(name)
@@ -36,7 +50,21 @@ func Build(name string, buildForce bool) error {
 		}
 	}
 
-	err := util.BuildFsImage(
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// the manifest of the previous build would not match the new image
+	if err := os.Remove(ManifestFile(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed removing manifest: %w", err)
+	}
+
+	// the patterns are normalized while the image is built
+	excludes := append([]string{}, ignore...)
+	builder := new(manifestBuilder)
+	buildTime := time.Now().Unix()
+	imageFiles, err := util.BuildFsImageContext(
+		ctx,
 		"Image "+name,
 		rootfsPath,
 		imagePath,
@@ -44,7 +72,15 @@ func Build(name string, buildForce bool) error {
 		ignore,
 		// ignore cross-device files
 		true,
-		"newc")
+		warewulfconf.Get().Warewulf.CompressionFormats(),
+		cpio.Options{XattrsFile: XattrsFile, OnFile: builder.add})
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := writeManifest(builder.manifest(name, buildTime, excludes, imageFiles)); err != nil {
+		return fmt.Errorf("failed writing manifest: %w", err)
+	}
+	wwlog.Verbose("Wrote manifest for image %s: %s", name, ManifestFile(name))
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"path"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/util"
 )
 
 func SourceParentDir() string {
@@ -32,6 +33,10 @@ func ImageFile(name string) string {
 	return path.Join(ImageParentDir(), name+".img")
 }
 
+func ManifestFile(name string) string {
+	return ImageFile(name) + ".manifest.json"
+}
+
 func CompressedImageFile(name string) string {
-	return ImageFile(name) + ".gz"
+	return util.CompressedFile(ImageFile(name), util.CompressGz)
 }
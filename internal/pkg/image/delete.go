@@ -64,13 +64,16 @@ func DeleteImage(name string) error {
 	if util.IsFile(imageFile) {
 		wwlog.Verbose("removing %s for image %s", imageFile, name)
 		errImg := os.Remove(imageFile)
-		wwlog.Verbose("removing %s for image %s", imageFile+".gz", name)
-		errGz := os.Remove(imageFile + ".gz")
+		wwlog.Verbose("removing compressed copies of %s for image %s", imageFile, name)
+		errCompressed := util.RemoveCompressedFiles(imageFile, nil)
+		if err := os.Remove(ManifestFile(name)); err != nil && !os.IsNotExist(err) {
+			wwlog.Warn("could not remove manifest of image %s: %s", name, err)
+		}
 		if errImg != nil {
 			return fmt.Errorf("problem deleting %s for image %s: %s", imageFile, name, errImg)
 		}
-		if errGz != nil {
-			return fmt.Errorf("problem deleting %s for image %s: %s", imageFile+".gz", name, errGz)
+		if errCompressed != nil {
+			return fmt.Errorf("problem deleting compressed copies of %s for image %s: %s", imageFile, name, errCompressed)
 		}
 		return nil
 	}
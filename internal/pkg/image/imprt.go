@@ -17,6 +17,15 @@ import (
 )
 
 func ImportDocker(uri string, name string, sCtx *types.SystemContext) error {
+	return ImportDockerContext(context.Background(), uri, name, sCtx)
+}
+
+// ImportDockerContext imports an image from an OCI registry, stopping early
+// if ctx is cancelled.
+func ImportDockerContext(ctx context.Context, uri string, name string, sCtx *types.SystemContext) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	OciBlobCacheDir := warewulfconf.Get().Paths.OciBlobCachedir()
 
 	err := os.MkdirAll(OciBlobCacheDir, 0755)
@@ -43,11 +52,14 @@ func ImportDocker(uri string, name string, sCtx *types.SystemContext) error {
 		return err
 	}
 
-	if _, err := p.GenerateID(context.Background(), uri); err != nil {
+	if _, err := p.GenerateID(ctx, uri); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	if err := p.Pull(context.Background(), uri, fullPath); err != nil {
+	if err := p.Pull(ctx, uri, fullPath); err != nil {
 		return err
 	}
 
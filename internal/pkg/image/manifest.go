@@ -0,0 +1,143 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/warewulf/warewulf/internal/pkg/cpio"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+/*
+A Manifest records how an image was built: the files it contains, the
+digests of the image and its compressed copies, and a digest of the source
+files. The source digest covers the names, modes, owners, and content of the
+files, but not their modification times, so that it only changes when the
+content of the image does.
+*/
+type Manifest struct {
+	Name         string          `json:"name"`
+	BuildTime    int64           `json:"buildtime"`
+	SourceDigest string          `json:"sourcedigest"`
+	Excludes     []string        `json:"excludes"`
+	Images       []ManifestImage `json:"images"`
+	Files        []ManifestEntry `json:"files"`
+}
+
+// A ManifestImage is the image file, or one of its compressed copies.
+type ManifestImage struct {
+	File        string `json:"file"`
+	Compression string `json:"compression,omitempty"`
+	Size        int64  `json:"size"`
+	Digest      string `json:"digest"`
+}
+
+// A ManifestEntry is a file in an image. Mode is the octal file type and
+// permissions, as in stat(2).
+type ManifestEntry struct {
+	Name   string `json:"name"`
+	Mode   string `json:"mode"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// ReadManifest reads the manifest of the image name, which only exists once
+// the image has been built.
+func ReadManifest(name string) (*Manifest, error) {
+	data, err := os.ReadFile(ManifestFile(name))
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(Manifest)
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("could not parse manifest of image %s: %w", name, err)
+	}
+	return manifest, nil
+}
+
+// Digest returns the digest of the uncompressed image, or the empty string
+// if it is not recorded.
+func (manifest *Manifest) Digest() string {
+	for _, image := range manifest.Images {
+		if image.Compression == "" {
+			return image.Digest
+		}
+	}
+	return ""
+}
+
+// Digests returns the digests of the image and its compressed copies, by
+// path.
+func (manifest *Manifest) Digests() map[string]string {
+	digests := make(map[string]string)
+	for _, image := range manifest.Images {
+		digests[path.Join(ImageParentDir(), image.File)] = image.Digest
+	}
+	return digests
+}
+
+// manifestBuilder collects the files of an image as it is written.
+type manifestBuilder struct {
+	files []cpio.File
+}
+
+func (builder *manifestBuilder) add(file cpio.File) {
+	builder.files = append(builder.files, file)
+}
+
+// manifest returns the manifest of an image built from the collected files
+// into imageFiles.
+func (builder *manifestBuilder) manifest(name string, buildTime int64, excludes []string, imageFiles []util.ImageFile) *Manifest {
+	manifest := &Manifest{
+		Name:      name,
+		BuildTime: buildTime,
+		Excludes:  excludes,
+		Images:    []ManifestImage{},
+		Files:     []ManifestEntry{},
+	}
+	if manifest.Excludes == nil {
+		manifest.Excludes = []string{}
+	}
+	for _, imageFile := range imageFiles {
+		manifest.Images = append(manifest.Images, ManifestImage{
+			File:        path.Base(imageFile.Path),
+			Compression: imageFile.Compression,
+			Size:        imageFile.Size,
+			Digest:      imageFile.Digest,
+		})
+	}
+
+	// the links of hard-linked files are reported together
+	sort.Slice(builder.files, func(i, j int) bool {
+		return builder.files[i].Name < builder.files[j].Name
+	})
+	source := sha256.New()
+	for _, file := range builder.files {
+		entry := ManifestEntry{
+			Name: file.Name,
+			Mode: fmt.Sprintf("%o", file.Mode),
+			Size: file.Size,
+		}
+		if file.Digest != nil {
+			entry.Digest = "sha256:" + hex.EncodeToString(file.Digest)
+		}
+		manifest.Files = append(manifest.Files, entry)
+		fmt.Fprintf(source, "%s %o %d %d %d:%d %s\n",
+			file.Name, file.Mode, file.UID, file.GID, file.Rdevmajor, file.Rdevminor, entry.Digest)
+	}
+	manifest.SourceDigest = "sha256:" + hex.EncodeToString(source.Sum(nil))
+	return manifest
+}
+
+func writeManifest(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ManifestFile(manifest.Name), append(data, '\n'), 0o644)
+}
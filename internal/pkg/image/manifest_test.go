@@ -0,0 +1,77 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Manifest(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/chroots/test-image/rootfs/etc/hosts", "127.0.0.1 localhost\n")
+	env.WriteFile("/var/lib/warewulf/chroots/test-image/rootfs/etc/warewulf/excludes", "/boot/\n")
+	env.WriteFile("/var/lib/warewulf/chroots/test-image/rootfs/boot/vmlinuz", "kernel")
+
+	assert.NoError(t, Build("test-image", true))
+	manifest, err := ReadManifest("test-image")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-image", manifest.Name)
+	assert.NotZero(t, manifest.BuildTime)
+	assert.Equal(t, []string{"/boot/"}, manifest.Excludes)
+
+	// the digests match the files
+	assert.NotEmpty(t, manifest.Images)
+	assert.Equal(t, "test-image.img", manifest.Images[0].File)
+	assert.Equal(t, manifest.Images[0].Digest, manifest.Digest())
+	for _, image := range manifest.Images {
+		content, err := os.ReadFile(path.Join(ImageParentDir(), image.File))
+		assert.NoError(t, err)
+		sum := sha256.Sum256(content)
+		assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), image.Digest)
+		assert.Equal(t, int64(len(content)), image.Size)
+		assert.Equal(t, image.Digest, manifest.Digests()[path.Join(ImageParentDir(), image.File)])
+	}
+
+	var names []string
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+		if file.Name == "etc/hosts" {
+			sum := sha256.Sum256([]byte("127.0.0.1 localhost\n"))
+			assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), file.Digest)
+			assert.Equal(t, int64(20), file.Size)
+			assert.Equal(t, "100644", file.Mode)
+		}
+	}
+	assert.Equal(t, []string{"etc", "etc/hosts", "etc/warewulf", "etc/warewulf/excludes"}, names)
+
+	// the source digest does not depend on modification times
+	mtime := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(env.GetPath("/var/lib/warewulf/chroots/test-image/rootfs/etc/hosts"), mtime, mtime))
+	assert.NoError(t, Build("test-image", true))
+	rebuilt, err := ReadManifest("test-image")
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.SourceDigest, rebuilt.SourceDigest)
+	assert.NotEqual(t, manifest.Digest(), rebuilt.Digest())
+
+	// and the image is reproducible with SOURCE_DATE_EPOCH
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	assert.NoError(t, Build("test-image", true))
+	manifest, err = ReadManifest("test-image")
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chtimes(env.GetPath("/var/lib/warewulf/chroots/test-image/rootfs/etc/hosts"), mtime.Add(time.Hour), mtime.Add(time.Hour)))
+	assert.NoError(t, Build("test-image", true))
+	rebuilt, err = ReadManifest("test-image")
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Images, rebuilt.Images)
+
+	assert.NoError(t, DeleteImage("test-image"))
+	assert.NoFileExists(t, ManifestFile("test-image"))
+}
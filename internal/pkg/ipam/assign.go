@@ -0,0 +1,246 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+)
+
+// An Assignment is an IP address configured for a node, including addresses
+// inherited from its profiles.
+type Assignment struct {
+	Node  string `json:"node"`
+	Field string `json:"field"`
+	IP    net.IP `json:"ip"`
+}
+
+// A Conflict is an IP address that is assigned more than once.
+type Conflict struct {
+	IP          net.IP       `json:"ip"`
+	Assignments []Assignment `json:"assignments"`
+}
+
+// An Allocation is an IP address allocated from a pool by [Allocate].
+type Allocation struct {
+	Assignment
+	Pool string `json:"pool"`
+}
+
+func isSet(ip net.IP) bool {
+	return len(ip) > 0 && !ip.IsUnspecified()
+}
+
+// Assignments returns the IP addresses of the network devices and IPMI
+// interfaces of every node in registry, ordered by node and field.
+func Assignments(registry *node.NodesYaml) ([]Assignment, error) {
+	nodes, err := registry.FindAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	var assignments []Assignment
+	for _, n := range nodes {
+		assignments = append(assignments, nodeAssignments(n)...)
+	}
+	sort.SliceStable(assignments, func(i, j int) bool {
+		if assignments[i].Node != assignments[j].Node {
+			return assignments[i].Node < assignments[j].Node
+		}
+		return assignments[i].Field < assignments[j].Field
+	})
+	return assignments, nil
+}
+
+func nodeAssignments(n node.Node) (assignments []Assignment) {
+	for name, netDev := range n.NetDevs {
+		if netDev == nil {
+			continue
+		}
+		if isSet(netDev.Ipaddr) {
+			assignments = append(assignments, Assignment{n.Id(), "NetDevs[" + name + "].Ipaddr", netDev.Ipaddr})
+		}
+		if isSet(netDev.Ipaddr6) {
+			assignments = append(assignments, Assignment{n.Id(), "NetDevs[" + name + "].Ipaddr6", netDev.Ipaddr6})
+		}
+	}
+	if n.Ipmi != nil && isSet(n.Ipmi.Ipaddr) {
+		assignments = append(assignments, Assignment{n.Id(), "Ipmi.Ipaddr", n.Ipmi.Ipaddr})
+	}
+	return assignments
+}
+
+// Conflicts returns the addresses in assignments that are assigned more
+// than once, ordered by address.
+func Conflicts(assignments []Assignment) []Conflict {
+	byIP := make(map[string][]Assignment)
+	for _, assignment := range assignments {
+		key := assignment.IP.String()
+		byIP[key] = append(byIP[key], assignment)
+	}
+	var conflicts []Conflict
+	for _, holders := range byIP {
+		if len(holders) > 1 {
+			conflicts = append(conflicts, Conflict{IP: holders[0].IP, Assignments: holders})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return ipToInt(conflicts[i].IP).Cmp(ipToInt(conflicts[j].IP)) < 0
+	})
+	return conflicts
+}
+
+// NodeConflicts returns the conflicts in registry that involve any of the
+// given nodes.
+func NodeConflicts(registry *node.NodesYaml, nodeIDs []string) ([]Conflict, error) {
+	assignments, err := Assignments(registry)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool)
+	for _, nodeID := range nodeIDs {
+		selected[nodeID] = true
+	}
+	var conflicts []Conflict
+	for _, conflict := range Conflicts(assignments) {
+		for _, assignment := range conflict.Assignments {
+			if selected[assignment.Node] {
+				conflicts = append(conflicts, conflict)
+				break
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// Allocate assigns addresses from their pools to the network devices and
+// IPMI interfaces of the given nodes that refer to a pool, either directly
+// or through a profile, and do not yet have an address. The addresses are
+// set on the nodes themselves, along with the netmask (or IPv6 prefix
+// length) and gateway of the pool if the node does not already have them.
+// Addresses already assigned to any node in registry are not allocated.
+func Allocate(registry *node.NodesYaml, nodeIDs []string) ([]Allocation, error) {
+	var pools map[string]*Pool
+	var used map[string]bool
+	getPool := func(name string) (*Pool, error) {
+		if pools == nil {
+			var err error
+			if pools, err = Pools(); err != nil {
+				return nil, err
+			}
+			assignments, err := Assignments(registry)
+			if err != nil {
+				return nil, err
+			}
+			used = make(map[string]bool)
+			for _, assignment := range assignments {
+				used[assignment.IP.String()] = true
+			}
+		}
+		pool, ok := pools[name]
+		if !ok {
+			return nil, fmt.Errorf("address pool not defined: %s", name)
+		}
+		return pool, nil
+	}
+
+	var allocations []Allocation
+	for _, nodeID := range nodeIDs {
+		merged, err := registry.GetNode(nodeID)
+		if err != nil {
+			return allocations, err
+		}
+		nodePtr, err := registry.GetNodeOnlyPtr(nodeID)
+		if err != nil {
+			return allocations, err
+		}
+
+		netDevNames := make([]string, 0, len(merged.NetDevs))
+		for name := range merged.NetDevs {
+			netDevNames = append(netDevNames, name)
+		}
+		sort.Strings(netDevNames)
+		for _, name := range netDevNames {
+			mergedDev := merged.NetDevs[name]
+			if mergedDev == nil || mergedDev.Pool == "" {
+				continue
+			}
+			pool, err := getPool(mergedDev.Pool)
+			if err != nil {
+				return allocations, fmt.Errorf("%s: network device %s: %w", nodeID, name, err)
+			}
+			if (pool.IPv6() && isSet(mergedDev.Ipaddr6)) || (!pool.IPv6() && isSet(mergedDev.Ipaddr)) {
+				continue
+			}
+			ip, err := pool.Next(used)
+			if err != nil {
+				return allocations, fmt.Errorf("%s: network device %s: %w", nodeID, name, err)
+			}
+			used[ip.String()] = true
+			if nodePtr.NetDevs == nil {
+				nodePtr.NetDevs = make(map[string]*node.NetDev)
+			}
+			netDev := nodePtr.NetDevs[name]
+			if netDev == nil {
+				netDev = new(node.NetDev)
+				nodePtr.NetDevs[name] = netDev
+			}
+			field := "NetDevs[" + name + "].Ipaddr"
+			if pool.IPv6() {
+				field += "6"
+				netDev.Ipaddr6 = ip
+				if mergedDev.PrefixLen6 == "" {
+					ones, _ := pool.Subnet.Mask.Size()
+					netDev.PrefixLen6 = strconv.Itoa(ones)
+				}
+				if !isSet(mergedDev.Gateway6) && pool.Gateway != nil {
+					netDev.Gateway6 = pool.Gateway
+				}
+			} else {
+				netDev.Ipaddr = ip
+				if !isSet(mergedDev.Netmask) {
+					netDev.Netmask = net.IP(pool.Subnet.Mask)
+				}
+				if !isSet(mergedDev.Gateway) && pool.Gateway != nil {
+					netDev.Gateway = pool.Gateway
+				}
+			}
+			allocations = append(allocations, Allocation{Assignment{nodeID, field, ip}, pool.Name})
+		}
+
+		if merged.Ipmi != nil && merged.Ipmi.Pool != "" && !isSet(merged.Ipmi.Ipaddr) {
+			pool, err := getPool(merged.Ipmi.Pool)
+			if err != nil {
+				return allocations, fmt.Errorf("%s: ipmi: %w", nodeID, err)
+			}
+			ip, err := pool.Next(used)
+			if err != nil {
+				return allocations, fmt.Errorf("%s: ipmi: %w", nodeID, err)
+			}
+			used[ip.String()] = true
+			if nodePtr.Ipmi == nil {
+				nodePtr.Ipmi = new(node.IpmiConf)
+			}
+			nodePtr.Ipmi.Ipaddr = ip
+			if !isSet(merged.Ipmi.Netmask) && !pool.IPv6() {
+				nodePtr.Ipmi.Netmask = net.IP(pool.Subnet.Mask)
+			}
+			if !isSet(merged.Ipmi.Gateway) && pool.Gateway != nil {
+				nodePtr.Ipmi.Gateway = pool.Gateway
+			}
+			allocations = append(allocations, Allocation{Assignment{nodeID, "Ipmi.Ipaddr", ip}, pool.Name})
+		}
+	}
+	return allocations, nil
+}
+
+// Assigned returns the assignments whose address is allocatable from pool.
+func (pool *Pool) Assigned(assignments []Assignment) (assigned []Assignment) {
+	for _, assignment := range assignments {
+		if pool.Contains(assignment.IP) {
+			assigned = append(assigned, assignment)
+		}
+	}
+	return assigned
+}
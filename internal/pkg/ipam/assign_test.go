@@ -0,0 +1,148 @@
+package ipam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Allocate(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/warewulf.conf", `
+address pools:
+  cluster:
+    subnet: 10.0.0.0/24
+    range start: 10.0.0.10
+    gateway: 10.0.0.1
+  bmc:
+    subnet: 10.1.0.0/24
+    range start: 10.1.0.10
+  v6:
+    subnet: fd00::/64
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        pool: cluster
+      v6:
+        pool: v6
+    ipmi:
+      pool: bmc
+nodes:
+  n1:
+    profiles: [default]
+    network devices:
+      default:
+        ipaddr: 10.0.0.10
+  n2:
+    profiles: [default]
+  n3:
+    profiles: [default]
+    network devices:
+      default:
+        netmask: 255.255.0.0
+`)
+	env.Configure()
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	allocations, err := Allocate(&registry, []string{"n2", "n3"})
+	assert.NoError(t, err)
+	var allocated []string
+	for _, allocation := range allocations {
+		allocated = append(allocated, allocation.Node+" "+allocation.Field+" "+allocation.IP.String())
+	}
+	assert.Equal(t, []string{
+		"n2 NetDevs[default].Ipaddr 10.0.0.11",
+		"n2 NetDevs[v6].Ipaddr6 fd00::1",
+		"n2 Ipmi.Ipaddr 10.1.0.10",
+		"n3 NetDevs[default].Ipaddr 10.0.0.12",
+		"n3 NetDevs[v6].Ipaddr6 fd00::2",
+		"n3 Ipmi.Ipaddr 10.1.0.11",
+	}, allocated)
+
+	n2, err := registry.GetNode("n2")
+	assert.NoError(t, err)
+	assert.Equal(t, "255.255.255.0", n2.NetDevs["default"].Netmask.String())
+	assert.Equal(t, "10.0.0.1", n2.NetDevs["default"].Gateway.String())
+	assert.Equal(t, "64", n2.NetDevs["v6"].PrefixLen6)
+	n3, err := registry.GetNode("n3")
+	assert.NoError(t, err)
+	assert.Equal(t, "255.255.0.0", n3.NetDevs["default"].Netmask.String())
+
+	// nodes with addresses are not allocated new ones
+	allocations, err = Allocate(&registry, []string{"n1", "n2"})
+	assert.NoError(t, err)
+	assert.Len(t, allocations, 2)
+	assert.Equal(t, "n1", allocations[0].Node)
+	assert.NotEqual(t, "NetDevs[default].Ipaddr", allocations[0].Field)
+}
+
+func Test_Allocate_UnknownPool(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    network devices:
+      default:
+        pool: missing
+`)
+	env.Configure()
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	_, err = Allocate(&registry, []string{"n1"})
+	assert.ErrorContains(t, err, "address pool not defined: missing")
+}
+
+func Test_Conflicts(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  shared:
+    ipmi:
+      ipaddr: 10.1.0.1
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+  n2:
+    network devices:
+      default:
+        ipaddr: 10.0.0.1
+  n3:
+    profiles: [shared]
+  n4:
+    profiles: [shared]
+  n5:
+    network devices:
+      default:
+        ipaddr: 10.0.0.5
+`)
+
+	registry, err := node.New()
+	assert.NoError(t, err)
+	assignments, err := Assignments(&registry)
+	assert.NoError(t, err)
+	assert.Len(t, assignments, 5)
+
+	conflicts := Conflicts(assignments)
+	if assert.Len(t, conflicts, 2) {
+		assert.Equal(t, "10.0.0.1", conflicts[0].IP.String())
+		assert.Len(t, conflicts[0].Assignments, 2)
+		assert.Equal(t, "10.1.0.1", conflicts[1].IP.String())
+	}
+
+	conflicts, err = NodeConflicts(&registry, []string{"n2", "n5"})
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+}
@@ -0,0 +1,246 @@
+// Package ipam allocates node IP addresses from the address pools defined
+// in warewulf.conf and detects addresses that are assigned to more than one
+// node.
+package ipam
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+// A Pool is a parsed [config.AddressPool].
+type Pool struct {
+	Name    string
+	Subnet  *net.IPNet
+	Start   net.IP
+	End     net.IP
+	Gateway net.IP
+
+	// exclude holds the merged, sorted ranges of addresses that are never
+	// allocated.
+	exclude []ipRange
+}
+
+type ipRange struct {
+	start, end *big.Int
+}
+
+// Pools returns the address pools defined in warewulf.conf.
+func Pools() (map[string]*Pool, error) {
+	pools := make(map[string]*Pool)
+	for name, poolConf := range config.Get().Pools {
+		pool, err := NewPool(name, poolConf)
+		if err != nil {
+			return nil, err
+		}
+		pools[name] = pool
+	}
+	return pools, nil
+}
+
+// NewPool parses and validates an address pool.
+func NewPool(name string, poolConf *config.AddressPool) (*Pool, error) {
+	if poolConf == nil {
+		return nil, fmt.Errorf("address pool %s: not defined", name)
+	}
+	_, subnet, err := net.ParseCIDR(poolConf.Subnet)
+	if err != nil {
+		return nil, fmt.Errorf("address pool %s: invalid subnet: %s", name, poolConf.Subnet)
+	}
+	pool := &Pool{Name: name, Subnet: subnet}
+	ones, bits := subnet.Mask.Size()
+	first := ipToInt(subnet.IP)
+	last := new(big.Int).Add(first, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)), big.NewInt(1)))
+	if pool.IPv6() {
+		// the subnet-router anycast address is never allocated
+		pool.exclude = append(pool.exclude, ipRange{first, first})
+	} else if bits-ones > 1 {
+		// the network and broadcast addresses are never allocated
+		pool.exclude = append(pool.exclude, ipRange{first, first}, ipRange{last, last})
+	}
+
+	if pool.Start, err = pool.parseIP(poolConf.RangeStart, intToIP(first, pool.IPv6())); err != nil {
+		return nil, fmt.Errorf("address pool %s: invalid range start: %w", name, err)
+	}
+	if pool.End, err = pool.parseIP(poolConf.RangeEnd, intToIP(last, pool.IPv6())); err != nil {
+		return nil, fmt.Errorf("address pool %s: invalid range end: %w", name, err)
+	}
+	if ipToInt(pool.Start).Cmp(ipToInt(pool.End)) > 0 {
+		return nil, fmt.Errorf("address pool %s: range start %s is after range end %s", name, pool.Start, pool.End)
+	}
+	if poolConf.Gateway != "" {
+		if pool.Gateway, err = pool.parseIP(poolConf.Gateway, nil); err != nil {
+			return nil, fmt.Errorf("address pool %s: invalid gateway: %w", name, err)
+		}
+		pool.exclude = append(pool.exclude, ipRange{ipToInt(pool.Gateway), ipToInt(pool.Gateway)})
+	}
+	for _, exclude := range poolConf.Exclude {
+		excludeRange, err := pool.parseRange(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("address pool %s: invalid exclusion: %w", name, err)
+		}
+		pool.exclude = append(pool.exclude, excludeRange)
+	}
+	pool.exclude = mergeRanges(pool.exclude)
+	return pool, nil
+}
+
+// parseIP parses an address in the pool's subnet, returning defaultIP if
+// value is empty.
+func (pool *Pool) parseIP(value string, defaultIP net.IP) (net.IP, error) {
+	if value == "" {
+		return defaultIP, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("not an IP address: %s", value)
+	}
+	if !pool.Subnet.Contains(ip) {
+		return nil, fmt.Errorf("%s is not in %s", value, pool.Subnet)
+	}
+	return normalize(ip, pool.IPv6()), nil
+}
+
+// parseRange parses an address, an address range (start-end), or a subnet.
+func (pool *Pool) parseRange(value string) (ipRange, error) {
+	if _, subnet, err := net.ParseCIDR(value); err == nil {
+		ones, bits := subnet.Mask.Size()
+		start := ipToInt(subnet.IP)
+		end := new(big.Int).Add(start, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)), big.NewInt(1)))
+		return ipRange{start, end}, nil
+	}
+	startValue, endValue, isRange := strings.Cut(value, "-")
+	start := net.ParseIP(strings.TrimSpace(startValue))
+	if start == nil {
+		return ipRange{}, fmt.Errorf("not an IP address: %s", value)
+	}
+	end := start
+	if isRange {
+		if end = net.ParseIP(strings.TrimSpace(endValue)); end == nil {
+			return ipRange{}, fmt.Errorf("not an IP address range: %s", value)
+		}
+	}
+	if ipToInt(start).Cmp(ipToInt(end)) > 0 {
+		return ipRange{}, fmt.Errorf("range start is after range end: %s", value)
+	}
+	return ipRange{ipToInt(start), ipToInt(end)}, nil
+}
+
+// IPv6 returns true if the pool allocates IPv6 addresses.
+func (pool *Pool) IPv6() bool {
+	return pool.Subnet.IP.To4() == nil
+}
+
+// Contains returns true if ip is in the pool's range and is not excluded.
+func (pool *Pool) Contains(ip net.IP) bool {
+	if ip == nil || (ip.To4() == nil) != pool.IPv6() {
+		return false
+	}
+	n := ipToInt(ip)
+	if n.Cmp(ipToInt(pool.Start)) < 0 || n.Cmp(ipToInt(pool.End)) > 0 {
+		return false
+	}
+	_, excluded := pool.excluded(n)
+	return !excluded
+}
+
+// excluded returns the end of the exclusion containing n, if any.
+func (pool *Pool) excluded(n *big.Int) (*big.Int, bool) {
+	for _, r := range pool.exclude {
+		if n.Cmp(r.start) >= 0 && n.Cmp(r.end) <= 0 {
+			return r.end, true
+		}
+	}
+	return nil, false
+}
+
+// Size returns the number of addresses that the pool may allocate.
+func (pool *Pool) Size() *big.Int {
+	start, end := ipToInt(pool.Start), ipToInt(pool.End)
+	size := new(big.Int).Sub(end, start)
+	size.Add(size, big.NewInt(1))
+	for _, r := range pool.exclude {
+		overlapStart, overlapEnd := maxInt(r.start, start), minInt(r.end, end)
+		if overlapStart.Cmp(overlapEnd) <= 0 {
+			size.Sub(size, new(big.Int).Add(new(big.Int).Sub(overlapEnd, overlapStart), big.NewInt(1)))
+		}
+	}
+	return size
+}
+
+// Next returns the lowest address in the pool that is not in used, which
+// holds addresses in their string form.
+func (pool *Pool) Next(used map[string]bool) (net.IP, error) {
+	end := ipToInt(pool.End)
+	one := big.NewInt(1)
+	for n := ipToInt(pool.Start); n.Cmp(end) <= 0; n.Add(n, one) {
+		if excludeEnd, ok := pool.excluded(n); ok {
+			n.Set(excludeEnd)
+			continue
+		}
+		ip := intToIP(n, pool.IPv6())
+		if !used[ip.String()] {
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("address pool %s is exhausted", pool.Name)
+}
+
+func mergeRanges(ranges []ipRange) []ipRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Cmp(ranges[j].start) < 0 })
+	var merged []ipRange
+	for _, r := range ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if r.start.Cmp(new(big.Int).Add(last.end, big.NewInt(1))) <= 0 {
+				last.end = maxInt(last.end, r.end)
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func normalize(ip net.IP, v6 bool) net.IP {
+	if v6 {
+		return ip.To16()
+	}
+	return ip.To4()
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	if ip4 := ip.To4(); ip4 != nil {
+		return new(big.Int).SetBytes(ip4)
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+func intToIP(n *big.Int, v6 bool) net.IP {
+	size := net.IPv4len
+	if v6 {
+		size = net.IPv6len
+	}
+	ip := make(net.IP, size)
+	n.FillBytes(ip)
+	return ip
+}
+
+func maxInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) > 0 {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
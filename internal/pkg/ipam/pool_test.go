@@ -0,0 +1,99 @@
+package ipam
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+func Test_NewPool(t *testing.T) {
+	tests := map[string]struct {
+		pool    config.AddressPool
+		wantErr bool
+		start   string
+		end     string
+		size    string
+	}{
+		"ipv4 subnet": {
+			pool:  config.AddressPool{Subnet: "10.0.0.0/24"},
+			start: "10.0.0.0",
+			end:   "10.0.0.255",
+			size:  "254",
+		},
+		"ipv4 range with gateway and exclusions": {
+			pool: config.AddressPool{
+				Subnet:     "10.0.0.0/24",
+				RangeStart: "10.0.0.10",
+				RangeEnd:   "10.0.0.19",
+				Gateway:    "10.0.0.10",
+				Exclude:    []string{"10.0.0.12", "10.0.0.14-10.0.0.15", "10.0.0.15/32"},
+			},
+			start: "10.0.0.10",
+			end:   "10.0.0.19",
+			size:  "6",
+		},
+		"ipv6 subnet": {
+			pool:  config.AddressPool{Subnet: "fd00::/120"},
+			start: "fd00::",
+			end:   "fd00::ff",
+			size:  "255",
+		},
+		"invalid subnet": {
+			pool:    config.AddressPool{Subnet: "10.0.0.0"},
+			wantErr: true,
+		},
+		"range outside subnet": {
+			pool:    config.AddressPool{Subnet: "10.0.0.0/24", RangeStart: "10.0.1.1"},
+			wantErr: true,
+		},
+		"reversed range": {
+			pool:    config.AddressPool{Subnet: "10.0.0.0/24", RangeStart: "10.0.0.20", RangeEnd: "10.0.0.10"},
+			wantErr: true,
+		},
+		"invalid exclusion": {
+			pool:    config.AddressPool{Subnet: "10.0.0.0/24", Exclude: []string{"bogus"}},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool, err := NewPool("test", &tt.pool)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.start, pool.Start.String())
+			assert.Equal(t, tt.end, pool.End.String())
+			assert.Equal(t, tt.size, pool.Size().String())
+		})
+	}
+}
+
+func Test_Next(t *testing.T) {
+	pool, err := NewPool("test", &config.AddressPool{
+		Subnet:  "10.0.0.0/29",
+		Gateway: "10.0.0.1",
+		Exclude: []string{"10.0.0.3"},
+	})
+	assert.NoError(t, err)
+
+	used := map[string]bool{}
+	var allocated []string
+	for {
+		ip, err := pool.Next(used)
+		if err != nil {
+			break
+		}
+		used[ip.String()] = true
+		allocated = append(allocated, ip.String())
+	}
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.4", "10.0.0.5", "10.0.0.6"}, allocated)
+	assert.True(t, pool.Contains(net.ParseIP("10.0.0.6")))
+	assert.False(t, pool.Contains(net.ParseIP("10.0.0.3")))
+	assert.False(t, pool.Contains(net.ParseIP("10.0.0.7")))
+	assert.False(t, pool.Contains(net.ParseIP("fd00::2")))
+}
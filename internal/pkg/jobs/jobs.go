@@ -0,0 +1,380 @@
+// Package jobs runs long-running warewulfd operations, such as image
+// imports and builds, in the background. Each job records its state,
+// progress, and log in the jobs directory, so that the job can be followed
+// by clients other than the one that started it, including wwctl.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// State is the state of a job.
+type State string
+
+const (
+	Running State = "running"
+	// Cancelling is the state of a running job that has been asked to stop.
+	Cancelling State = "cancelling"
+	Succeeded  State = "succeeded"
+	Failed     State = "failed"
+	Cancelled  State = "cancelled"
+)
+
+// Finished returns true if a job in state will not change state again.
+func (state State) Finished() bool {
+	return state == Succeeded || state == Failed || state == Cancelled
+}
+
+// maxRetainedJobs is the number of finished jobs kept in the jobs
+// directory. Older jobs are removed as new jobs are started.
+const maxRetainedJobs = 100
+
+// Status describes a job.
+type Status struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Target   string   `json:"target"`
+	State    State    `json:"state"`
+	Created  int64    `json:"created"`
+	Finished int64    `json:"finished,omitempty"`
+	Done     int      `json:"done"`
+	Total    int      `json:"total"`
+	Error    string   `json:"error,omitempty"`
+	Log      []string `json:"log,omitempty"`
+}
+
+// Job is a running or finished job started by this process.
+type Job struct {
+	lock   sync.Mutex
+	status Status
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// persistLock keeps an older status from being written over a newer one
+	persistLock sync.Mutex
+}
+
+var (
+	jobs     = make(map[string]*Job)
+	jobsLock = sync.Mutex{}
+)
+
+func jobsDir() string {
+	return config.Get().Paths.JobsDir()
+}
+
+func statusFile(id string) string {
+	return path.Join(jobsDir(), id+".json")
+}
+
+func logFile(id string) string {
+	return path.Join(jobsDir(), id+".log")
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Start runs fn in the background as a new job of the given type and
+// target. The context passed to fn is cancelled when the job is cancelled;
+// it is independent of any request that started the job.
+func Start(jobType, target string, fn func(ctx context.Context, job *Job) error) (*Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(jobsDir(), 0755); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		status: Status{
+			ID:      id,
+			Type:    jobType,
+			Target:  target,
+			State:   Running,
+			Created: time.Now().Unix(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	if err := job.persist(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	jobsLock.Lock()
+	jobs[id] = job
+	jobsLock.Unlock()
+	prune()
+
+	go func() {
+		defer close(job.done)
+		defer cancel()
+		job.Logf("Started %s job for %s", jobType, target)
+		err := fn(ctx, job)
+
+		job.lock.Lock()
+		switch {
+		case err != nil && ctx.Err() != nil:
+			job.status.State = Cancelled
+			job.status.Error = ctx.Err().Error()
+		case err != nil:
+			job.status.State = Failed
+			job.status.Error = err.Error()
+		default:
+			job.status.State = Succeeded
+		}
+		job.status.Finished = time.Now().Unix()
+		state := job.status.State
+		job.lock.Unlock()
+
+		if err != nil {
+			job.Logf("Job %s: %s", state, err)
+		} else {
+			job.Logf("Job %s", state)
+		}
+		if err := job.persist(); err != nil {
+			wwlog.Warn("Could not write job status %s: %s", id, err)
+		}
+	}()
+	return job, nil
+}
+
+// Get returns a job started by this process.
+func Get(id string) (*Job, bool) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	job, ok := jobs[id]
+	return job, ok
+}
+
+// ID returns the ID of the job.
+func (job *Job) ID() string {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	return job.status.ID
+}
+
+// Status returns the current status of the job, including its log.
+func (job *Job) Status() Status {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	status := job.status
+	status.Log = append([]string{}, job.status.Log...)
+	return status
+}
+
+// Logf appends a line to the job's log.
+func (job *Job) Logf(format string, a ...interface{}) {
+	message := fmt.Sprintf(format, a...)
+	line := time.Now().Format(time.DateTime) + " " + message
+
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	job.status.Log = append(job.status.Log, line)
+	wwlog.Info("job %s: %s", job.status.ID, message)
+	f, err := os.OpenFile(logFile(job.status.ID), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		wwlog.Warn("Could not write job log %s: %s", job.status.ID, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		wwlog.Warn("Could not write job log %s: %s", job.status.ID, err)
+	}
+}
+
+// SetProgress records that done of total units of work are complete.
+func (job *Job) SetProgress(done, total int) {
+	job.lock.Lock()
+	job.status.Done = done
+	job.status.Total = total
+	job.lock.Unlock()
+	if err := job.persist(); err != nil {
+		wwlog.Warn("Could not write job status %s: %s", job.ID(), err)
+	}
+}
+
+// Cancel requests that the job stop, and returns without waiting for it to
+// do so. The job is in the Cancelling state until it has stopped. Cancel
+// returns false if the job has already finished.
+func (job *Job) Cancel() bool {
+	job.lock.Lock()
+	state := job.status.State
+	if state == Running {
+		job.status.State = Cancelling
+	}
+	job.lock.Unlock()
+	if state.Finished() {
+		return false
+	}
+	if state == Running {
+		job.Logf("Cancelling job")
+		if err := job.persist(); err != nil {
+			wwlog.Warn("Could not write job status %s: %s", job.ID(), err)
+		}
+	}
+	job.cancel()
+	return true
+}
+
+// Wait blocks until the job has finished.
+func (job *Job) Wait() {
+	<-job.done
+}
+
+// persist writes the job status, without its log, to the jobs directory.
+func (job *Job) persist() error {
+	job.persistLock.Lock()
+	defer job.persistLock.Unlock()
+	job.lock.Lock()
+	status := job.status
+	job.lock.Unlock()
+	status.Log = nil
+	return writeStatus(status)
+}
+
+func writeStatus(status Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(jobsDir(), "."+status.ID+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpFile.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), statusFile(status.ID))
+}
+
+// Read returns the status and log of a job from the jobs directory.
+func Read(id string) (Status, error) {
+	status, err := readStatus(id)
+	if err != nil {
+		return status, err
+	}
+	if data, err := os.ReadFile(logFile(id)); err == nil {
+		status.Log = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	return status, nil
+}
+
+func readStatus(id string) (Status, error) {
+	var status Status
+	if id == "" || strings.ContainsAny(id, "/.") {
+		return status, fmt.Errorf("invalid job ID: %s", id)
+	}
+	data, err := os.ReadFile(statusFile(id))
+	if os.IsNotExist(err) {
+		return status, fmt.Errorf("job not found: %s", id)
+	} else if err != nil {
+		return status, err
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return status, fmt.Errorf("could not parse job %s: %w", id, err)
+	}
+	return status, nil
+}
+
+// List returns the status, without logs, of the jobs in the jobs
+// directory, oldest first.
+func List() ([]Status, error) {
+	entries, err := os.ReadDir(jobsDir())
+	if os.IsNotExist(err) {
+		return []Status{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	statuses := []Status{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || strings.HasPrefix(id, ".") {
+			continue
+		}
+		status, err := readStatus(id)
+		if err != nil {
+			wwlog.Warn("%s", err)
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Created < statuses[j].Created
+	})
+	return statuses, nil
+}
+
+// Recover marks jobs that were running when the server stopped as failed.
+// It must be called before any job is started.
+func Recover() {
+	statuses, err := List()
+	if err != nil {
+		wwlog.Warn("Could not read jobs: %s", err)
+		return
+	}
+	for _, status := range statuses {
+		if status.State.Finished() {
+			continue
+		}
+		status.State = Failed
+		status.Error = "interrupted by server restart"
+		status.Finished = time.Now().Unix()
+		if err := writeStatus(status); err != nil {
+			wwlog.Warn("Could not write job status %s: %s", status.ID, err)
+		}
+	}
+}
+
+// prune removes the oldest finished jobs beyond maxRetainedJobs.
+func prune() {
+	statuses, err := List()
+	if err != nil {
+		wwlog.Warn("Could not read jobs: %s", err)
+		return
+	}
+	var finished []Status
+	for _, status := range statuses {
+		if status.State.Finished() {
+			finished = append(finished, status)
+		}
+	}
+	for i := 0; i < len(finished)-maxRetainedJobs; i++ {
+		id := finished[i].ID
+		jobsLock.Lock()
+		delete(jobs, id)
+		jobsLock.Unlock()
+		for _, fileName := range []string{statusFile(id), logFile(id)} {
+			if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+				wwlog.Warn("Could not remove job file: %s", err)
+			}
+		}
+	}
+}
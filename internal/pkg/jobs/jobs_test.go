@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Start(t *testing.T) {
+	tests := map[string]struct {
+		fn    func(ctx context.Context, job *Job) error
+		state State
+		err   string
+	}{
+		"success": {
+			fn: func(ctx context.Context, job *Job) error {
+				job.Logf("working")
+				job.SetProgress(1, 1)
+				return nil
+			},
+			state: Succeeded,
+		},
+		"failure": {
+			fn: func(ctx context.Context, job *Job) error {
+				return errors.New("broken")
+			},
+			state: Failed,
+			err:   "broken",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+
+			job, err := Start("test", "target", tt.fn)
+			assert.NoError(t, err)
+			job.Wait()
+
+			status := job.Status()
+			assert.Equal(t, tt.state, status.State)
+			assert.Equal(t, tt.err, status.Error)
+			assert.NotZero(t, status.Finished)
+
+			read, err := Read(job.ID())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.state, read.State)
+			assert.Equal(t, len(status.Log), len(read.Log))
+		})
+	}
+}
+
+func Test_Cancel(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	started := make(chan struct{})
+	job, err := Start("test", "target", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.NoError(t, err)
+	<-started
+	assert.True(t, job.Cancel())
+	status, err := Read(job.ID())
+	assert.NoError(t, err)
+	assert.Contains(t, []State{Cancelling, Cancelled}, status.State)
+	assert.True(t, job.Cancel())
+	job.Wait()
+	assert.Equal(t, Cancelled, job.Status().State)
+	assert.False(t, job.Cancel())
+}
+
+func Test_List(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	statuses, err := List()
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	env.MkdirAll("var/local/warewulf/jobs")
+	statuses, err = List()
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	assert.NoError(t, writeStatus(Status{ID: "b", State: Succeeded, Created: 2}))
+	assert.NoError(t, writeStatus(Status{ID: "a", State: Running, Created: 1}))
+	statuses, err = List()
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, "a", statuses[0].ID)
+		assert.Equal(t, "b", statuses[1].ID)
+	}
+
+	_, err = Read("../a")
+	assert.Error(t, err)
+	_, err = Read("c")
+	assert.Error(t, err)
+}
+
+func Test_Recover(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.MkdirAll("var/local/warewulf/jobs")
+
+	assert.NoError(t, writeStatus(Status{ID: "a", State: Running, Created: 1}))
+	assert.NoError(t, writeStatus(Status{ID: "b", State: Succeeded, Created: 2}))
+	Recover()
+
+	a, err := Read("a")
+	assert.NoError(t, err)
+	assert.Equal(t, Failed, a.State)
+	assert.Equal(t, "interrupted by server restart", a.Error)
+	b, err := Read("b")
+	assert.NoError(t, err)
+	assert.Equal(t, Succeeded, b.State)
+}
@@ -0,0 +1,66 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+)
+
+// ValidateAttributes checks the custom attributes set by a node or profile
+// against the attribute schema in warewulf.conf: each attribute must be
+// declared, and its value must be valid for the declared type.
+func (profile *Profile) ValidateAttributes() error {
+	schema := warewulfconf.Get().Attributes
+	names := make([]string, 0, len(profile.Attributes))
+	for name := range profile.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := profile.Attributes[name]
+		if value == "" {
+			continue
+		}
+		attr, ok := schema[name]
+		if !ok || attr == nil {
+			return fmt.Errorf("unknown attribute: %s", name)
+		}
+		if _, err := attr.Parse(value); err != nil {
+			return fmt.Errorf("invalid value for attribute %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// TypedAttributes returns the custom attributes of a node as values of
+// their declared types (see config.Attribute.Parse). Attributes that are not
+// declared, or whose values are not valid, are returned as strings.
+func (profile *Profile) TypedAttributes() map[string]interface{} {
+	schema := warewulfconf.Get().Attributes
+	typed := make(map[string]interface{})
+	for name, value := range profile.Attributes {
+		typed[name] = value
+		if attr, ok := schema[name]; ok && attr != nil {
+			if v, err := attr.Parse(value); err == nil {
+				typed[name] = v
+			}
+		}
+	}
+	return typed
+}
+
+// applyAttributeDefaults sets the declared default of each custom attribute
+// that a merged node does not set.
+func (node *Node) applyAttributeDefaults(fields fieldMap) {
+	for name, attr := range warewulfconf.Get().Attributes {
+		if attr == nil || attr.Default == "" || node.Attributes[name] != "" {
+			continue
+		}
+		if node.Attributes == nil {
+			node.Attributes = make(map[string]string)
+		}
+		node.Attributes[name] = attr.Default
+		fields.Set(fmt.Sprintf("Attributes[%s]", name), "(default)", attr.Default)
+	}
+}
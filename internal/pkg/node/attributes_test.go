@@ -0,0 +1,76 @@
+package node
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Attributes(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/warewulf.conf", `
+attributes:
+  rack:
+    type: int
+  bmc:
+    type: IP
+  role:
+    default: compute
+    values: [compute, login]
+`)
+	env.Configure()
+
+	registry, err := Parse([]byte(`
+nodeprofiles:
+  default:
+    attributes:
+      rack: "12"
+nodes:
+  n1:
+    profiles:
+    - default
+    attributes:
+      bmc: 10.0.0.1
+  n2:
+    profiles:
+    - default
+    attributes:
+      rack: "13"
+      role: login
+`))
+	assert.NoError(t, err)
+
+	n1, fields, err := registry.MergeNode("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"rack": "12", "bmc": "10.0.0.1", "role": "compute"}, n1.Attributes)
+	assert.Equal(t, "default", fields.Source("Attributes[rack]"))
+	assert.Equal(t, "", fields.Source("Attributes[bmc]"))
+	assert.Equal(t, "(default)", fields.Source("Attributes[role]"))
+	assert.Equal(t, map[string]interface{}{"rack": 12, "bmc": net.ParseIP("10.0.0.1"), "role": "compute"}, n1.TypedAttributes())
+	assert.NotContains(t, registry.Nodes["n1"].Attributes, "role")
+
+	n2, _, err := registry.MergeNode("n2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"rack": "13", "role": "login"}, n2.Attributes)
+
+	for _, tt := range []struct {
+		attributes map[string]string
+		err        string
+	}{
+		{attributes: map[string]string{"rack": "1", "role": "login"}},
+		{attributes: map[string]string{"rack": "one"}, err: "invalid value for attribute rack: not an integer: one"},
+		{attributes: map[string]string{"role": "storage"}, err: "invalid value for attribute role: storage is not one of compute, login"},
+		{attributes: map[string]string{"color": "blue"}, err: "unknown attribute: color"},
+	} {
+		profile := Profile{Attributes: tt.attributes}
+		if err := profile.ValidateAttributes(); tt.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, tt.err)
+		}
+	}
+}
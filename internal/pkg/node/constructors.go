@@ -1,6 +1,8 @@
 package node
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"sort"
 	"syscall"
@@ -16,16 +18,25 @@ func CanWriteConfig() bool {
 }
 
 /*
-Creates a new nodeDb object from the on-disk configuration
+Creates a new nodeDb object from the on-disk configuration, including its
+fragments; see Load.
 */
 func New() (NodesYaml, error) {
-	nodesConf := warewulfconf.Get().Paths.NodesConf()
-	wwlog.Verbose("Opening node configuration file: %s", nodesConf)
-	data, err := os.ReadFile(nodesConf)
+	return Load(warewulfconf.Get().Paths.NodesConf())
+}
+
+// readLocked reads a file while holding a shared lock on it, so that it is
+// not read while PersistToFile is writing it.
+func readLocked(fileName string) ([]byte, error) {
+	file, err := os.Open(fileName)
 	if err != nil {
-		return NodesYaml{}, err
+		return nil, err
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_SH); err != nil {
+		return nil, fmt.Errorf("could not lock %s: %w", fileName, err)
 	}
-	return Parse(data)
+	return io.ReadAll(file)
 }
 
 // Parse constructs a new nodeDb object from an input YAML
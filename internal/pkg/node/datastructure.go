@@ -21,8 +21,22 @@ func init() {
 Structure of which goes to disk
 */
 type NodesYaml struct {
+	Include      []string            `yaml:"include,omitempty"`
 	NodeProfiles map[string]*Profile `yaml:"nodeprofiles"`
 	Nodes        map[string]*Node    `yaml:"nodes"`
+
+	// sourceFile and sourceDigest record the file the configuration was
+	// read from and its content, so that PersistToFile can detect changes
+	// made to the file by another process.
+	sourceFile   string
+	sourceDigest [32]byte
+
+	// fragmentDigests records the content of each included fragment, and
+	// origins the fragment that defines each node ("node/ID") and profile
+	// ("profile/ID") read from one, so that PersistToFile can write them
+	// back to the same fragment.
+	fragmentDigests map[string][32]byte
+	origins         map[string]string
 }
 
 /*
@@ -34,6 +48,9 @@ type Node struct {
 	// exported values
 	Discoverable wwtype.WWbool     `yaml:"discoverable,omitempty" json:"discoverable,omitempty" lopt:"discoverable" sopt:"e" comment:"discoverable in given network (true/false)"`
 	AssetKey     string            `yaml:"asset key,omitempty"    json:"asset key,omitempty"    lopt:"asset"                 comment:"the node's Asset tag (key)"`
+	State        string            `yaml:"state,omitempty"        json:"state,omitempty"        lopt:"state"                 comment:"the node's lifecycle state (new, provisioning, ready, maintenance, retired)"`
+	StateReason  string            `yaml:"state reason,omitempty" json:"state reason,omitempty" lopt:"reason"                comment:"the reason for the node's lifecycle state"`
+	StateChanged string            `yaml:"state changed,omitempty" json:"state changed,omitempty"` // set by UpdateState
 	Profile      `yaml:"-,inline"` // include all values set in the profile, but inline them in yaml output if these are part of Node
 }
 
@@ -56,6 +73,7 @@ type Profile struct {
 	Root           string                 `yaml:"root,omitempty"             json:"root,omitempty"             lopt:"root"                         comment:"the rootfs" `
 	NetDevs        map[string]*NetDev     `yaml:"network devices,omitempty"  json:"network devices,omitempty"`
 	Tags           map[string]string      `yaml:"tags,omitempty"             json:"tags,omitempty"`
+	Attributes     map[string]string      `yaml:"attributes,omitempty"       json:"attributes,omitempty"`
 	PrimaryNetDev  string                 `yaml:"primary network,omitempty"  json:"primary network,omitempty"  lopt:"primarynet"          sopt:"p" comment:"the primary network interface"`
 	Disks          map[string]*Disk       `yaml:"disks,omitempty"            json:"disks,omitempty"`
 	FileSystems    map[string]*FileSystem `yaml:"filesystems,omitempty"      json:"filesystems,omitempty"`
@@ -68,6 +86,7 @@ type IpmiConf struct {
 	Ipaddr     net.IP            `yaml:"ipaddr,omitempty"     json:"ipaddr,omitempty"     lopt:"ipmiaddr"       comment:"the IPMI IP address" type:"IP"`
 	Gateway    net.IP            `yaml:"gateway,omitempty"    json:"gateway,omitempty"    lopt:"ipmigateway"    comment:"the IPMI gateway" type:"IP"`
 	Netmask    net.IP            `yaml:"netmask,omitempty"    json:"netmask,omitempty"    lopt:"ipminetmask"    comment:"the IPMI netmask" type:"IP"`
+	Pool       string            `yaml:"pool,omitempty"       json:"pool,omitempty"       lopt:"ipmipool"       comment:"the address pool from which to allocate the IPMI IP address"`
 	Port       string            `yaml:"port,omitempty"       json:"port,omitempty"       lopt:"ipmiport"       comment:"the IPMI port"`
 	Interface  string            `yaml:"interface,omitempty"  json:"interface,omitempty"  lopt:"ipmiinterface"  comment:"the node's IPMI interface (defaults: 'lan')"`
 	EscapeChar string            `yaml:"escapechar,omitempty" json:"escapechar,omitempty" lopt:"ipmiescapechar" comment:"the IPMI escape character (defaults: '~')"`
@@ -93,6 +112,7 @@ type NetDev struct {
 	PrefixLen6 string            `yaml:"prefixlen6,omitempty" json:"prefixlen6,omitempty" lopt:"prefixlen6"          comment:"the network's IPv6 prefix length" type:"uint"          scope:"net"`
 	Gateway6   net.IP            `yaml:"gateway6,omitempty"   json:"gateway6,omitempty"   lopt:"gateway6"            comment:"the node's IPv6 network device gateway" type:"IP"     scope:"net"`
 	MTU        string            `yaml:"mtu,omitempty"        json:"mtu,omitempty"        lopt:"mtu"                 comment:"the MTU" type:"uint"                                  scope:"net"`
+	Pool       string            `yaml:"pool,omitempty"       json:"pool,omitempty"       lopt:"netpool"             comment:"the address pool from which to allocate addresses" scope:"net"`
 	Tags       map[string]string `yaml:"tags,omitempty"       json:"tags,omitempty"`
 	primary    bool
 }
@@ -12,9 +12,9 @@ import (
 
 // Change is a single field-level difference.
 type Change struct {
-	Path   string
-	Before string
-	After  string
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
 }
 
 // Profile.Clone yaml deep copy
@@ -0,0 +1,217 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+)
+
+// A ProfileRef is a profile in the graph of profiles that a node inherits,
+// together with the profiles that it inherits in turn.
+type ProfileRef struct {
+	ID string `json:"id"`
+	// Negated is set for a "~ID" entry, which excludes the profile.
+	Negated bool `json:"negated,omitempty"`
+	// Excluded is set if the profile is excluded by a negated entry.
+	Excluded bool `json:"excluded,omitempty"`
+	Missing  bool `json:"missing,omitempty"`
+	// Cycle is set if the profile inherits itself; its profiles are not
+	// expanded again.
+	Cycle bool `json:"cycle,omitempty"`
+	// Repeated is set if the profile was already inherited elsewhere in
+	// the graph, where its profiles are shown.
+	Repeated bool         `json:"repeated,omitempty"`
+	Profiles []ProfileRef `json:"profiles,omitempty"`
+}
+
+// A Definition is the value that a node or one of its profiles sets for a
+// field.
+type Definition struct {
+	Source string `json:"source"`
+	Value  string `json:"value"`
+	// Overridden is set if the value is replaced by a later definition.
+	Overridden bool `json:"overridden,omitempty"`
+}
+
+// A FieldProvenance is the effective value of a node's field and the
+// definitions it was merged from, in merge order.
+type FieldProvenance struct {
+	Field       string       `json:"field"`
+	Value       string       `json:"value"`
+	Source      string       `json:"source"`
+	Definitions []Definition `json:"definitions"`
+	// Negated lists the entries of a list field that are removed by a
+	// "~"-prefixed entry.
+	Negated []string `json:"negated,omitempty"`
+}
+
+// An Explanation describes how a node's effective configuration is merged
+// from its profiles.
+type Explanation struct {
+	Node     string       `json:"node"`
+	File     string       `json:"file,omitempty"`
+	Profiles []ProfileRef `json:"profiles"`
+	// Order lists the profiles in the order they are merged; the node
+	// itself is merged last.
+	Order    []string          `json:"order"`
+	Fields   []FieldProvenance `json:"fields"`
+	Problems []string          `json:"problems,omitempty"`
+}
+
+/*
+Explain returns the profile graph of the node with the given id and the
+provenance of each of its effective fields: which node or profile sets it,
+which values are overridden, and which list entries are negated. Missing
+profiles and profile cycles are reported as problems.
+*/
+func (config *NodesYaml) Explain(id string) (explanation Explanation, err error) {
+	original, ok := config.Nodes[id]
+	if !ok {
+		return explanation, ErrNotFound
+	}
+	merged, fields, err := config.MergeNode(id)
+	if err != nil {
+		return explanation, err
+	}
+	explanation.Node = id
+	explanation.File = config.NodeFile(id)
+	explanation.Profiles = config.profileGraph(original.Profiles, nil, make(map[string]bool), &explanation.Problems)
+	excluded := make(map[string]bool)
+	for _, negated := range negList(config.getProfilesProfiles(original.Profiles, make(map[string]bool))) {
+		excluded[negated] = true
+	}
+	markExcluded(explanation.Profiles, excluded)
+
+	explanation.Order = []string{}
+	definitions := make(map[string][]Definition)
+	addDefinitions := func(source string, obj interface{}) {
+		for _, field := range listFields(obj) {
+			if field == "Profiles" || field == "Comment" {
+				// not inherited from profiles
+				if _, isNode := obj.(Node); !isNode {
+					continue
+				}
+			}
+			if value, err := getNestedFieldString(obj, field); err == nil && value != "" {
+				definitions[field] = append(definitions[field], Definition{Source: source, Value: value})
+			}
+		}
+	}
+	for _, profileID := range config.getNodeProfiles(id) {
+		if profile, ok := config.NodeProfiles[profileID]; ok && profile != nil {
+			explanation.Order = append(explanation.Order, profileID)
+			addDefinitions(profileID, *profile)
+		}
+	}
+	addDefinitions(id, *original)
+
+	explanation.Fields = []FieldProvenance{}
+	for _, field := range fields.List(merged) {
+		provenance := FieldProvenance{
+			Field:       field.Field,
+			Value:       field.Value,
+			Source:      field.Source,
+			Definitions: definitions[field.Field],
+		}
+		if value, err := getNestedFieldString(merged, field.Field); err == nil {
+			provenance.Value = value
+		}
+		if provenance.Source == "" || provenance.Source == "SUPERSEDED" {
+			provenance.Source = id
+		}
+		if isListField(merged, field.Field) {
+			for _, definition := range provenance.Definitions {
+				for _, entry := range strings.Split(definition.Value, ",") {
+					if strings.HasPrefix(entry, "~") {
+						provenance.Negated = append(provenance.Negated, entry[1:])
+					}
+				}
+			}
+		} else {
+			for i := 0; i < len(provenance.Definitions)-1; i++ {
+				provenance.Definitions[i].Overridden = true
+			}
+		}
+		explanation.Fields = append(explanation.Fields, provenance)
+	}
+	return explanation, nil
+}
+
+// FilterFields keeps only the given field and, for a struct or map field,
+// the fields nested in it. Field names are not case-sensitive.
+func (explanation *Explanation) FilterFields(name string) {
+	name = strings.ToLower(name)
+	var fields []FieldProvenance
+	for _, field := range explanation.Fields {
+		fieldName := strings.ToLower(field.Field)
+		if fieldName == name || strings.HasPrefix(fieldName, name+".") || strings.HasPrefix(fieldName, name+"[") {
+			fields = append(fields, field)
+		}
+	}
+	explanation.Fields = fields
+}
+
+// profileGraph returns the profiles inherited through ids. path holds the
+// profiles currently being expanded, to detect cycles, and seen the
+// profiles expanded anywhere in the graph.
+func (config *NodesYaml) profileGraph(ids []string, path []string, seen map[string]bool, problems *[]string) (refs []ProfileRef) {
+	for _, id := range ids {
+		if strings.HasPrefix(id, "~") {
+			refs = append(refs, ProfileRef{ID: id[1:], Negated: true})
+			continue
+		}
+		ref := ProfileRef{ID: id}
+		profile, exists := config.NodeProfiles[id]
+		if contains(path, id) {
+			ref.Cycle = true
+			*problems = append(*problems, fmt.Sprintf("profile cycle: %s", strings.Join(append(path, id), " -> ")))
+		} else if seen[id] {
+			ref.Repeated = true
+		} else if !exists || profile == nil {
+			seen[id] = true
+			ref.Missing = true
+			if len(path) > 0 {
+				*problems = append(*problems, fmt.Sprintf("profile not found: %s (inherited by %s)", id, path[len(path)-1]))
+			} else {
+				*problems = append(*problems, fmt.Sprintf("profile not found: %s", id))
+			}
+		} else {
+			seen[id] = true
+			ref.Profiles = config.profileGraph(profile.Profiles, append(append([]string{}, path...), id), seen, problems)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func markExcluded(refs []ProfileRef, excluded map[string]bool) {
+	for i := range refs {
+		if !refs[i].Negated && excluded[refs[i].ID] {
+			refs[i].Excluded = true
+		}
+		markExcluded(refs[i].Profiles, excluded)
+	}
+}
+
+// isListField returns true if the named field of obj holds a list, whose
+// values are appended rather than overridden when profiles are merged.
+func isListField(obj interface{}, name string) bool {
+	value, err := getNestedFieldValue(obj, name)
+	if err != nil {
+		return false
+	}
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value.Kind() == reflect.Slice && value.Type() != reflect.TypeOf(net.IP{})
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
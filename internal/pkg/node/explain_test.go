@@ -0,0 +1,93 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Explain(t *testing.T) {
+	registry, err := Parse([]byte(`
+nodeprofiles:
+  base:
+    image name: rocky8
+    system overlay:
+    - wwinit
+    - extra
+    profiles:
+    - default
+  default:
+    image name: rocky9
+    profiles:
+    - base
+    - missing
+    kernel:
+      args:
+      - quiet
+  gpu: {}
+nodes:
+  n1:
+    profiles:
+    - default
+    - gpu
+    - ~gpu
+    image name: rocky10
+    system overlay:
+    - ~extra
+    - nvidia
+`))
+	assert.NoError(t, err)
+
+	explanation, err := registry.Explain("n1")
+	assert.NoError(t, err)
+	assert.Equal(t, []ProfileRef{
+		{ID: "default", Profiles: []ProfileRef{
+			{ID: "base", Profiles: []ProfileRef{{ID: "default", Cycle: true}}},
+			{ID: "missing", Missing: true},
+		}},
+		{ID: "gpu", Excluded: true},
+		{ID: "gpu", Negated: true},
+	}, explanation.Profiles)
+	assert.Equal(t, []string{"base", "default"}, explanation.Order)
+	assert.Equal(t, []string{
+		"profile cycle: default -> base -> default",
+		"profile not found: missing (inherited by default)",
+	}, explanation.Problems)
+
+	explanation.FilterFields("imagename")
+	assert.Equal(t, []FieldProvenance{{
+		Field:  "ImageName",
+		Value:  "rocky10",
+		Source: "n1",
+		Definitions: []Definition{
+			{Source: "base", Value: "rocky8", Overridden: true},
+			{Source: "default", Value: "rocky9", Overridden: true},
+			{Source: "n1", Value: "rocky10"},
+		},
+	}}, explanation.Fields)
+
+	explanation, err = registry.Explain("n1")
+	assert.NoError(t, err)
+	explanation.FilterFields("SystemOverlay")
+	assert.Equal(t, []FieldProvenance{{
+		Field:  "SystemOverlay",
+		Value:  "wwinit,nvidia",
+		Source: "base,n1",
+		Definitions: []Definition{
+			{Source: "base", Value: "wwinit,extra"},
+			{Source: "n1", Value: "~extra,nvidia"},
+		},
+		Negated: []string{"extra"},
+	}}, explanation.Fields)
+
+	explanation, err = registry.Explain("n1")
+	assert.NoError(t, err)
+	explanation.FilterFields("Kernel")
+	if assert.Len(t, explanation.Fields, 1) {
+		assert.Equal(t, "Kernel.Args", explanation.Fields[0].Field)
+		assert.Equal(t, "default", explanation.Fields[0].Source)
+	}
+
+	_, err = registry.Explain("n2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -165,6 +165,9 @@ func Test_listFields(t *testing.T) {
 			fields: []string{
 				"Discoverable",
 				"AssetKey",
+				"State",
+				"StateReason",
+				"StateChanged",
 				"Profiles",
 				"Comment",
 				"ClusterName",
@@ -179,6 +182,7 @@ func Test_listFields(t *testing.T) {
 				"Ipmi.Ipaddr",
 				"Ipmi.Gateway",
 				"Ipmi.Netmask",
+				"Ipmi.Pool",
 				"Ipmi.Port",
 				"Ipmi.Interface",
 				"Ipmi.EscapeChar",
@@ -197,6 +201,7 @@ func Test_listFields(t *testing.T) {
 				"NetDevs[default].PrefixLen6",
 				"NetDevs[default].Gateway6",
 				"NetDevs[default].MTU",
+				"NetDevs[default].Pool",
 				"NetDevs[default].Tags[nettag]",
 				"Tags[tag]",
 				"PrimaryNetDev",
@@ -234,6 +239,7 @@ func Test_listFields(t *testing.T) {
 				"Ipmi.Ipaddr",
 				"Ipmi.Gateway",
 				"Ipmi.Netmask",
+				"Ipmi.Pool",
 				"Ipmi.Port",
 				"Ipmi.Interface",
 				"Ipmi.EscapeChar",
@@ -252,6 +258,7 @@ func Test_listFields(t *testing.T) {
 				"NetDevs[default].PrefixLen6",
 				"NetDevs[default].Gateway6",
 				"NetDevs[default].MTU",
+				"NetDevs[default].Pool",
 				"NetDevs[default].Tags[nettag]",
 				"Tags[tag]",
 				"PrimaryNetDev",
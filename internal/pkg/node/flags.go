@@ -61,6 +61,7 @@ type NodeConfDel struct {
 	TagsDel     []string `lopt:"tagdel" comment:"delete tags"`
 	IpmiTagsDel []string `lopt:"ipmitagdel" comment:"delete ipmi tags"`
 	NetTagsDel  []string `lopt:"nettagdel" comment:"delete network tags"`
+	AttrsDel    []string `lopt:"attrdel" comment:"delete custom attributes"`
 	NetDel      string   `lopt:"netdel" comment:"network to delete"`
 	DiskDel     string   `lopt:"diskdel" comment:"delete the disk from the configuration"`
 	PartDel     string   `lopt:"partdel" comment:"delete the partition from the configuration"`
@@ -70,6 +71,7 @@ type NodeConfAdd struct {
 	TagsAdd     map[string]string `lopt:"tagadd" comment:"add tags"`
 	IpmiTagsAdd map[string]string `lopt:"ipmitagadd" comment:"add ipmi tags"`
 	NetTagsAdd  map[string]string `lopt:"nettagadd" comment:"add network tags"`
+	AttrsAdd    map[string]string `lopt:"attr" comment:"set custom attributes declared in warewulf.conf (key=value)"`
 	Net         string            `lopt:"netname" comment:"network which is modified" default:"default"`
 	DiskName    string            `lopt:"diskname" comment:"set diskdevice name"`
 	PartName    string            `lopt:"partname" comment:"set the partition name so it can be used by a file system"`
@@ -0,0 +1,227 @@
+package node
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// IncludeDir is the directory, next to nodes.conf, from which additional
+// node and profile definitions are read.
+const IncludeDir = "nodes.conf.d"
+
+/*
+Load reads the node registry from fileName and from the fragments it
+includes: the files matching each of its include patterns, relative to the
+directory of fileName, and the *.conf files in the nodes.conf.d directory
+next to it. A node or profile may only be defined in one file.
+*/
+func Load(fileName string) (NodesYaml, error) {
+	wwlog.Verbose("Opening node configuration file: %s", fileName)
+	data, err := readLocked(fileName)
+	if err != nil {
+		return NodesYaml{}, err
+	}
+	registry, err := Parse(data)
+	if err != nil {
+		return registry, err
+	}
+	registry.sourceFile = fileName
+	registry.sourceDigest = sha256.Sum256(data)
+
+	fragments, err := registry.fragmentFiles()
+	if err != nil {
+		return registry, err
+	}
+	for _, fragment := range fragments {
+		wwlog.Verbose("Opening node configuration fragment: %s", fragment)
+		data, err := readLocked(fragment)
+		if err != nil {
+			return registry, err
+		}
+		part, err := Parse(data)
+		if err != nil {
+			return registry, fmt.Errorf("%s: %w", fragment, err)
+		}
+		if len(part.Include) > 0 {
+			wwlog.Warn("%s: include is only read from %s", fragment, fileName)
+		}
+		if registry.fragmentDigests == nil {
+			registry.fragmentDigests = make(map[string][32]byte)
+			registry.origins = make(map[string]string)
+		}
+		registry.fragmentDigests[fragment] = sha256.Sum256(data)
+		for id, profile := range part.NodeProfiles {
+			if _, ok := registry.NodeProfiles[id]; ok {
+				return registry, fmt.Errorf("%s: profile %s is already defined in %s", fragment, id, registry.ProfileFile(id))
+			}
+			registry.NodeProfiles[id] = profile
+			registry.origins["profile/"+id] = fragment
+		}
+		for id, node := range part.Nodes {
+			if _, ok := registry.Nodes[id]; ok {
+				return registry, fmt.Errorf("%s: node %s is already defined in %s", fragment, id, registry.NodeFile(id))
+			}
+			registry.Nodes[id] = node
+			registry.origins["node/"+id] = fragment
+		}
+	}
+	return registry, nil
+}
+
+// fragmentFiles returns the fragments included by the registry's source
+// file, in the order in which they are read.
+func (config *NodesYaml) fragmentFiles() ([]string, error) {
+	if config.sourceFile == "" {
+		return nil, nil
+	}
+	dir := filepath.Dir(config.sourceFile)
+	var fragments []string
+	seen := map[string]bool{filepath.Clean(config.sourceFile): true}
+	for _, pattern := range append(append([]string{}, config.Include...), filepath.Join(IncludeDir, "*.conf")) {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() || seen[match] {
+				continue
+			}
+			seen[match] = true
+			fragments = append(fragments, match)
+		}
+	}
+	return fragments, nil
+}
+
+// Files returns the file the registry was read from, followed by the
+// fragments that were read with it.
+func (config *NodesYaml) Files() (files []string) {
+	if config.sourceFile != "" {
+		files = append(files, config.sourceFile)
+	}
+	fragments := make([]string, 0, len(config.fragmentDigests))
+	for fragment := range config.fragmentDigests {
+		fragments = append(fragments, fragment)
+	}
+	sort.Strings(fragments)
+	return append(files, fragments...)
+}
+
+// Changed returns true if any of the files the registry was read from has
+// changed since it was read, or if a fragment has been added to or removed
+// from its includes.
+func (config *NodesYaml) Changed() bool {
+	if config.sourceFile == "" {
+		return true
+	}
+	if data, err := readLocked(config.sourceFile); err != nil || sha256.Sum256(data) != config.sourceDigest {
+		return true
+	}
+	fragments, err := config.fragmentFiles()
+	if err != nil || len(fragments) != len(config.fragmentDigests) {
+		return true
+	}
+	for _, fragment := range fragments {
+		digest, ok := config.fragmentDigests[fragment]
+		if !ok {
+			return true
+		}
+		if data, err := readLocked(fragment); err != nil || sha256.Sum256(data) != digest {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeFile returns the file that defines the node with the given id. Nodes
+// that have not been read from a fragment belong to the registry's source
+// file.
+func (config *NodesYaml) NodeFile(id string) string {
+	if fragment, ok := config.origins["node/"+id]; ok {
+		return fragment
+	}
+	return config.sourceFile
+}
+
+// ProfileFile returns the file that defines the profile with the given id.
+func (config *NodesYaml) ProfileFile(id string) string {
+	if fragment, ok := config.origins["profile/"+id]; ok {
+		return fragment
+	}
+	return config.sourceFile
+}
+
+/*
+Replace replaces the nodes and profiles defined in fileName with those of
+content, e.g., to restore a previous version of the file. If fileName is the
+registry's source file, its include patterns are replaced as well.
+*/
+func (config *NodesYaml) Replace(fileName string, content NodesYaml) error {
+	isFragment := false
+	if _, ok := config.fragmentDigests[fileName]; ok {
+		isFragment = true
+	} else if fileName != config.sourceFile {
+		return fmt.Errorf("not part of the node configuration: %s", fileName)
+	}
+	for id := range config.NodeProfiles {
+		if config.ProfileFile(id) == fileName {
+			delete(config.NodeProfiles, id)
+		}
+	}
+	for id := range config.Nodes {
+		if config.NodeFile(id) == fileName {
+			delete(config.Nodes, id)
+		}
+	}
+	for id, profile := range content.NodeProfiles {
+		if _, ok := config.NodeProfiles[id]; ok {
+			return fmt.Errorf("profile %s is already defined in %s", id, config.ProfileFile(id))
+		}
+		config.NodeProfiles[id] = profile
+		if isFragment {
+			config.origins["profile/"+id] = fileName
+		}
+	}
+	for id, node := range content.Nodes {
+		if _, ok := config.Nodes[id]; ok {
+			return fmt.Errorf("node %s is already defined in %s", id, config.NodeFile(id))
+		}
+		config.Nodes[id] = node
+		if isFragment {
+			config.origins["node/"+id] = fileName
+		}
+	}
+	if !isFragment {
+		config.Include = content.Include
+	}
+	return nil
+}
+
+// split divides the registry into the documents to be written to its source
+// file and to each of its fragments.
+func (config *NodesYaml) split() map[string]*NodesYaml {
+	parts := make(map[string]*NodesYaml)
+	for _, file := range config.Files() {
+		parts[file] = &NodesYaml{
+			NodeProfiles: make(map[string]*Profile),
+			Nodes:        make(map[string]*Node),
+		}
+	}
+	parts[config.sourceFile].Include = config.Include
+	for id, profile := range config.NodeProfiles {
+		parts[config.ProfileFile(id)].NodeProfiles[id] = profile
+	}
+	for id, node := range config.Nodes {
+		parts[config.NodeFile(id)].Nodes[id] = node
+	}
+	return parts
+}
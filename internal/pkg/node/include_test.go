@@ -0,0 +1,195 @@
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Load(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+include:
+- racks/*.yaml
+nodeprofiles:
+  default: {}
+nodes:
+  n1: {}
+`)
+	env.WriteFile("/etc/warewulf/racks/rack1.yaml", `
+nodeprofiles:
+  rack1: {}
+nodes:
+  n2:
+    profiles:
+    - rack1
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/gpu.conf", `
+nodes:
+  n3:
+    comment: gpu
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/ignored.yaml", `
+nodes:
+  n4: {}
+`)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"n1", "n2", "n3"}, keys(registry.Nodes))
+	assert.ElementsMatch(t, []string{"default", "rack1"}, keys(registry.NodeProfiles))
+	assert.Equal(t, env.GetPath("/etc/warewulf/nodes.conf"), registry.NodeFile("n1"))
+	assert.Equal(t, env.GetPath("/etc/warewulf/racks/rack1.yaml"), registry.NodeFile("n2"))
+	assert.Equal(t, env.GetPath("/etc/warewulf/racks/rack1.yaml"), registry.ProfileFile("rack1"))
+	assert.Equal(t, env.GetPath("/etc/warewulf/nodes.conf.d/gpu.conf"), registry.NodeFile("n3"))
+	assert.Equal(t, []string{
+		env.GetPath("/etc/warewulf/nodes.conf"),
+		env.GetPath("/etc/warewulf/nodes.conf.d/gpu.conf"),
+		env.GetPath("/etc/warewulf/racks/rack1.yaml"),
+	}, registry.Files())
+
+	n2, err := registry.GetNode("n2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rack1"}, n2.Profiles)
+}
+
+func Test_Load_Duplicate(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `
+nodes:
+  n1: {}
+`)
+
+	_, err := New()
+	assert.ErrorContains(t, err, "node n1 is already defined in "+env.GetPath("/etc/warewulf/nodes.conf"))
+}
+
+func Test_PersistFragments(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	nodesConf := `nodeprofiles:
+  default: {}
+nodes:
+  n1: {}
+`
+	env.WriteFile("/etc/warewulf/nodes.conf", nodesConf)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `nodes:
+  n2: {}
+  n3: {}
+`)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	registry.Nodes["n2"].Comment = "changed"
+	assert.NoError(t, registry.DelNode("n3"))
+	_, err = registry.AddNode("n4")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Persist())
+
+	assert.Equal(t, `nodeprofiles:
+  default: {}
+nodes:
+  n1: {}
+  n4: {}
+`, env.ReadFile("/etc/warewulf/nodes.conf"))
+	assert.Equal(t, `nodeprofiles: {}
+nodes:
+  n2:
+    comment: changed
+`, env.ReadFile("/etc/warewulf/nodes.conf.d/rack1.conf"))
+
+	// a fragment changed by another process is a conflict
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `nodes:
+  n5: {}
+`)
+	registry.Nodes["n1"].Comment = "changed"
+	err = registry.Persist()
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.NotContains(t, env.ReadFile("/etc/warewulf/nodes.conf"), "changed")
+}
+
+func Test_Replace(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `
+nodes:
+  n2: {}
+`)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	restored, err := Parse([]byte("nodes:\n  n3: {}\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Replace(env.GetPath("/etc/warewulf/nodes.conf.d/rack1.conf"), restored))
+	assert.ElementsMatch(t, []string{"n1", "n3"}, keys(registry.Nodes))
+	assert.Equal(t, env.GetPath("/etc/warewulf/nodes.conf.d/rack1.conf"), registry.NodeFile("n3"))
+
+	restored, err = Parse([]byte("nodes:\n  n1: {}\n"))
+	assert.NoError(t, err)
+	assert.Error(t, registry.Replace(env.GetPath("/etc/warewulf/other.conf"), restored))
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
+
+func Test_Changed(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `
+nodes:
+  n2: {}
+`)
+	load := func() NodesYaml {
+		registry, err := New()
+		assert.NoError(t, err)
+		return registry
+	}
+
+	registry := load()
+	assert.False(t, registry.Changed())
+
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `
+nodes:
+  n3: {}
+`)
+	assert.True(t, registry.Changed())
+
+	registry = load()
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack2.conf", `
+nodes:
+  n4: {}
+`)
+	assert.True(t, registry.Changed())
+
+	registry = load()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n5: {}
+`)
+	assert.True(t, registry.Changed())
+
+	// a registry that was not read from a file is never up to date
+	assert.True(t, new(NodesYaml).Changed())
+}
@@ -0,0 +1,55 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Node lifecycle states
+const (
+	StateNew          = "new"
+	StateProvisioning = "provisioning"
+	StateReady        = "ready"
+	StateMaintenance  = "maintenance"
+	StateRetired      = "retired"
+)
+
+// States lists the valid node lifecycle states.
+var States = []string{StateNew, StateProvisioning, StateReady, StateMaintenance, StateRetired}
+
+/*
+UpdateState checks the node's lifecycle state and, if it differs from the
+previous state, records when it changed. Clearing the state also clears its
+reason and timestamp. The reason is shown on the node's console when it boots,
+so it may not contain control characters such as newlines.
+*/
+func (node *Node) UpdateState(previous string) error {
+	if node.State == "" {
+		node.StateReason = ""
+		node.StateChanged = ""
+		return nil
+	}
+	if !contains(States, node.State) {
+		return fmt.Errorf("invalid state: %s (must be one of %s)", node.State, strings.Join(States, ", "))
+	}
+	if strings.IndexFunc(node.StateReason, unicode.IsControl) >= 0 {
+		return fmt.Errorf("invalid state reason: %q (must not contain control characters)", node.StateReason)
+	}
+	if node.State != previous || node.StateChanged == "" {
+		node.StateChanged = time.Now().UTC().Format(time.RFC3339)
+	}
+	return nil
+}
+
+// InMaintenance returns true if the node must not be provisioned, i.e., if
+// it is in maintenance or retired.
+func (node *Node) InMaintenance() bool {
+	return node.State == StateMaintenance || node.State == StateRetired
+}
+
+// Retired returns true if the node is retired.
+func (node *Node) Retired() bool {
+	return node.State == StateRetired
+}
@@ -0,0 +1,44 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UpdateState(t *testing.T) {
+	n := NewNode("n1")
+	n.State = StateMaintenance
+	n.StateReason = "replacing DIMM"
+	assert.NoError(t, n.UpdateState(""))
+	changed, err := time.Parse(time.RFC3339, n.StateChanged)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), changed, time.Minute)
+	assert.True(t, n.InMaintenance())
+	assert.False(t, n.Retired())
+
+	n.StateChanged = "2026-01-02T03:04:05Z"
+	n.StateReason = "replacing DIMM and PSU"
+	assert.NoError(t, n.UpdateState(StateMaintenance))
+	assert.Equal(t, "2026-01-02T03:04:05Z", n.StateChanged)
+
+	n.State = StateRetired
+	assert.NoError(t, n.UpdateState(StateMaintenance))
+	assert.NotEqual(t, "2026-01-02T03:04:05Z", n.StateChanged)
+	assert.True(t, n.InMaintenance())
+	assert.True(t, n.Retired())
+
+	n.State = ""
+	assert.NoError(t, n.UpdateState(StateRetired))
+	assert.Empty(t, n.StateReason)
+	assert.Empty(t, n.StateChanged)
+	assert.False(t, n.InMaintenance())
+
+	n.State = "broken"
+	assert.ErrorContains(t, n.UpdateState(""), "invalid state: broken")
+
+	n.State = StateMaintenance
+	n.StateReason = "replacing DIMM\nchain http://example.com/boot.ipxe"
+	assert.ErrorContains(t, n.UpdateState(""), "invalid state reason")
+}
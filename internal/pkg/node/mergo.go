@@ -176,6 +176,7 @@ func (config *NodesYaml) MergeNode(id string) (node Node, fields fieldMap, err e
 		delete(fields, "Comment")
 	}
 
+	node.applyAttributeDefaults(fields)
 	node.setIds(id)
 	node.valid = true
 	node.updatePrimaryNetDev()
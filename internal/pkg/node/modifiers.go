@@ -2,12 +2,20 @@ package node
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/gob"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
 
 	"github.com/pkg/errors"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/history"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -36,6 +44,7 @@ func (config *NodesYaml) DelNode(nodeID string) error {
 
 	wwlog.Verbose("Deleting node: %s", nodeID)
 	delete(config.Nodes, nodeID)
+	delete(config.origins, "node/"+nodeID)
 
 	return nil
 }
@@ -102,6 +111,7 @@ func (config *NodesYaml) DelProfile(profileID string) error {
 
 	wwlog.Verbose("deleting profile: %s", profileID)
 	delete(config.NodeProfiles, profileID)
+	delete(config.origins, "profile/"+profileID)
 
 	return nil
 }
@@ -113,33 +123,227 @@ func (config *NodesYaml) Persist() error {
 	return config.PersistToFile(warewulfconf.Get().Paths.NodesConf())
 }
 
+// ErrConflict is returned by PersistToFile when the configuration file was
+// changed by another process after it was read.
+var ErrConflict = errors.New("node configuration was changed by another process")
+
+/*
+PersistToFile writes the registry to configFile. If configFile is the file
+the registry was read from, nodes and profiles read from an included
+fragment are written back to that fragment, and only the files whose content
+changed are written. ErrConflict is returned, and nothing is written, if any
+of the files was changed by another process after it was read.
+*/
 func (config *NodesYaml) PersistToFile(configFile string) (err error) {
 	if configFile == "" {
 		configFile = warewulfconf.Get().Paths.NodesConf()
 	}
-	out, dumpErr := config.Dump()
-	if dumpErr != nil {
-		wwlog.Error("%s", dumpErr)
-		return dumpErr
+	parts := map[string]*NodesYaml{configFile: config}
+	if configFile == config.sourceFile && len(config.fragmentDigests) > 0 {
+		parts = config.split()
+	} else if len(config.fragmentDigests) > 0 {
+		// the fragments are merged into configFile, so it must not
+		// include them again
+		merged := *config
+		merged.Include = nil
+		parts[configFile] = &merged
 	}
-	file, err := os.OpenFile(configFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
-	if err != nil {
-		wwlog.Error("%s", err)
-		return err
+	fileNames := make([]string, 0, len(parts))
+	for fileName := range parts {
+		fileNames = append(fileNames, fileName)
 	}
+	sort.Strings(fileNames)
+
+	// lock and check every file before writing any of them
+	outs := make(map[string][]byte)
+	files := make(map[string]*lockedFile)
 	defer func() {
-		if cerr := file.Close(); cerr != nil && err == nil {
-			err = cerr
+		for _, file := range files {
+			if cerr := file.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
 		}
 	}()
-	_, err = file.WriteString(string(out))
+	for _, fileName := range fileNames {
+		out, dumpErr := parts[fileName].Dump()
+		if dumpErr != nil {
+			wwlog.Error("%s", dumpErr)
+			return dumpErr
+		}
+		outs[fileName] = out
+		file, err := lockForWrite(fileName)
+		if err != nil {
+			wwlog.Error("%s", err)
+			return err
+		}
+		files[fileName] = file
+		if digest, ok := config.digest(fileName); ok && sha256.Sum256(file.current) != digest {
+			return fmt.Errorf("%w: %s", ErrConflict, fileName)
+		}
+	}
+
+	nodesConf := warewulfconf.Get().Paths.NodesConf()
+	for _, fileName := range fileNames {
+		file, out := files[fileName], outs[fileName]
+		if file.current != nil && bytes.Equal(file.current, out) {
+			continue
+		}
+		if err := file.write(out); err != nil {
+			return err
+		}
+		if fileName == nodesConf {
+			history.RecordNodesConf(file.current, out)
+		} else if configFile == nodesConf {
+			history.RecordNodesFragment(fragmentName(nodesConf, fileName), file.current, out)
+		}
+		wwlog.Debug("persisted: %s", fileName)
+	}
+
+	if configFile != config.sourceFile {
+		config.fragmentDigests = nil
+		config.origins = nil
+	}
+	config.sourceFile = configFile
+	config.sourceDigest = sha256.Sum256(outs[configFile])
+	for fragment := range config.fragmentDigests {
+		config.fragmentDigests[fragment] = sha256.Sum256(outs[fragment])
+	}
+	return nil
+}
+
+// digest returns the digest of fileName when the registry was read, if it
+// was read from it.
+func (config *NodesYaml) digest(fileName string) ([32]byte, bool) {
+	if fileName == config.sourceFile {
+		return config.sourceDigest, true
+	}
+	digest, ok := config.fragmentDigests[fileName]
+	return digest, ok
+}
+
+// fragmentName returns the name of a fragment relative to the directory of
+// nodes.conf, if it is within it.
+func fragmentName(nodesConf, fragment string) string {
+	if rel, err := filepath.Rel(filepath.Dir(nodesConf), fragment); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return fragment
+}
+
+// A lockedFile is a file opened while holding an exclusive lock on it, so
+// that it is not read or written by another process meanwhile.
+type lockedFile struct {
+	name string
+	file *os.File
+	// current is the content of the file when it was locked, or nil if it
+	// did not exist.
+	current []byte
+	// written is true once the file has been replaced by write.
+	written bool
+}
+
+func lockForWrite(fileName string) (*lockedFile, error) {
+	for {
+		_, statErr := os.Stat(fileName)
+		existed := statErr == nil
+		file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		// the lock is released when the file is closed
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("could not lock %s: %w", fileName, err)
+		}
+		// the file may have been replaced by another writer while waiting
+		// for the lock, in which case the lock is on the old file
+		if replaced, err := isReplaced(file, fileName); err != nil {
+			file.Close()
+			return nil, err
+		} else if replaced {
+			file.Close()
+			continue
+		}
+		current, err := io.ReadAll(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if !existed {
+			current = nil
+		}
+		return &lockedFile{name: fileName, file: file, current: current}, nil
+	}
+}
+
+// isReplaced returns true if fileName no longer refers to the open file.
+func isReplaced(file *os.File, fileName string) (bool, error) {
+	opened, err := file.Stat()
 	if err != nil {
+		return false, err
+	}
+	current, err := os.Stat(fileName)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !os.SameFile(opened, current), nil
+}
+
+// write replaces the file with out. out is written to a temporary file in
+// the same directory, which is renamed over the file while the lock is
+// held, so that the file is never left partially written.
+func (f *lockedFile) write(out []byte) error {
+	info, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(f.name), "."+filepath.Base(f.name)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(out); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(info.Mode().Perm()); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && (int(stat.Uid) != os.Getuid() || int(stat.Gid) != os.Getgid()) {
+		if err := tmpFile.Chown(int(stat.Uid), int(stat.Gid)); err != nil {
+			wwlog.Warn("Could not preserve the owner of %s: %s", f.name, err)
+		}
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
 		return err
 	}
-	wwlog.Debug("persisted: %s", configFile)
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), f.name); err != nil {
+		return err
+	}
+	f.written = true
 	return nil
 }
 
+// Close releases the lock. A file that was created by lockForWrite but never
+// written is removed first, so that an aborted write leaves no empty file
+// behind.
+func (f *lockedFile) Close() error {
+	if f.current == nil && !f.written {
+		if err := os.Remove(f.name); err != nil && !os.IsNotExist(err) {
+			_ = f.file.Close()
+			return err
+		}
+	}
+	return f.file.Close()
+}
+
 // Dump returns a YAML document representing the nodeDb instance. Passes through any errors
 // generated by yaml encoding.
 func (config *NodesYaml) Dump() ([]byte, error) {
@@ -0,0 +1,109 @@
+package node
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_PersistConflict(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+
+	first, err := New()
+	assert.NoError(t, err)
+	second, err := New()
+	assert.NoError(t, err)
+
+	_, err = first.AddNode("n2")
+	assert.NoError(t, err)
+	assert.NoError(t, first.Persist())
+
+	_, err = second.AddNode("n3")
+	assert.NoError(t, err)
+	err = second.Persist()
+	assert.True(t, errors.Is(err, ErrConflict))
+
+	// a registry may be persisted repeatedly once it has written the file
+	_, err = first.AddNode("n4")
+	assert.NoError(t, err)
+	assert.NoError(t, first.Persist())
+
+	registry, err := New()
+	assert.NoError(t, err)
+	assert.Contains(t, registry.Nodes, "n2")
+	assert.Contains(t, registry.Nodes, "n4")
+	assert.NotContains(t, registry.Nodes, "n3")
+}
+
+func Test_PersistConflictRemovedFile(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+
+	registry, err := New()
+	assert.NoError(t, err)
+	nodesConf := env.GetPath("/etc/warewulf/nodes.conf")
+	assert.NoError(t, os.Remove(nodesConf))
+
+	// the conflict does not leave an empty file in place of the removed one
+	_, err = registry.AddNode("n2")
+	assert.NoError(t, err)
+	err = registry.Persist()
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.NoFileExists(t, nodesConf)
+}
+
+func Test_PersistReplacesFile(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+	nodesConf := env.GetPath("/etc/warewulf/nodes.conf")
+	assert.NoError(t, os.Chmod(nodesConf, 0o600))
+
+	// a reader of the old file is not affected by the write
+	old, err := os.Open(nodesConf)
+	assert.NoError(t, err)
+	defer old.Close()
+
+	registry, err := New()
+	assert.NoError(t, err)
+	_, err = registry.AddNode("n2")
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Persist())
+
+	oldContent, err := io.ReadAll(old)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(oldContent), "n2")
+	assert.Contains(t, env.ReadFile("/etc/warewulf/nodes.conf"), "n2")
+
+	replaced, err := isReplaced(old, nodesConf)
+	assert.NoError(t, err)
+	assert.True(t, replaced)
+
+	info, err := os.Stat(nodesConf)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(env.GetPath("/etc/warewulf"))
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), "nodes.conf-")
+	}
+}
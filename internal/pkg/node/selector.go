@@ -0,0 +1,108 @@
+package node
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// A Selector selects nodes by the values of their fields, as parsed by
+// ParseSelector. A node is selected if it matches every condition.
+type Selector []condition
+
+type condition struct {
+	key    string
+	value  string
+	negate bool
+}
+
+// ParseSelector parses a comma-separated list of conditions of the form
+// key=value or key!=value, e.g. "profile=gpu,tag.rack=12,image=rocky9".
+//
+// The key may be "name", the node name; "profile", any of the node's
+// profiles; "image", the node's image; "tag.KEY", the value of the tag KEY;
+// or the name of any field listed by "wwctl node list --all", such as
+// "Kernel.Version" or "NetDevs[default].Ipaddr", in any case. The value may
+// be a shell pattern, as accepted by path.Match. An empty value matches an
+// unset field.
+func ParseSelector(selector string) (Selector, error) {
+	var sel Selector
+	if strings.TrimSpace(selector) == "" {
+		return sel, nil
+	}
+	for _, term := range strings.Split(selector, ",") {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid selector %q: expected key=value", term)
+		}
+		cond := condition{key: strings.TrimSpace(key), value: strings.TrimSpace(value)}
+		if strings.HasSuffix(cond.key, "!") {
+			cond.negate = true
+			cond.key = strings.TrimSpace(strings.TrimSuffix(cond.key, "!"))
+		}
+		if cond.key == "" {
+			return nil, fmt.Errorf("invalid selector %q: missing key", term)
+		}
+		if _, err := path.Match(cond.value, ""); err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", term, err)
+		}
+		sel = append(sel, cond)
+	}
+	return sel, nil
+}
+
+// Match returns true if node matches every condition of sel.
+func (sel Selector) Match(node Node) bool {
+	for _, cond := range sel {
+		if cond.match(node) == cond.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (cond condition) match(node Node) bool {
+	matchValue := func(value string) bool {
+		matched, _ := path.Match(cond.value, value)
+		return matched
+	}
+	switch key := strings.ToLower(cond.key); {
+	case key == "name":
+		return matchValue(node.Id())
+	case key == "profile":
+		if len(node.Profiles) == 0 {
+			return cond.value == ""
+		}
+		for _, profile := range node.Profiles {
+			if matchValue(profile) {
+				return true
+			}
+		}
+		return false
+	case key == "image":
+		return matchValue(node.ImageName)
+	case strings.HasPrefix(key, "tag."):
+		return matchValue(node.Tags[cond.key[len("tag."):]])
+	}
+	for _, name := range listFields(node) {
+		if strings.EqualFold(name, cond.key) {
+			value, err := getNestedFieldString(node, name)
+			if err != nil {
+				return cond.value == ""
+			}
+			return matchValue(value)
+		}
+	}
+	return cond.value == ""
+}
+
+// FilterNodeListBySelector returns the nodes in set that are selected by
+// sel.
+func FilterNodeListBySelector(set []Node, sel Selector) (ret []Node) {
+	for _, node := range set {
+		if sel.Match(node) {
+			ret = append(ret, node)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,107 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_ParseSelector(t *testing.T) {
+	var tests = map[string]struct {
+		selector string
+		err      bool
+		length   int
+	}{
+		"empty":            {selector: "", length: 0},
+		"single":           {selector: "profile=gpu", length: 1},
+		"multiple":         {selector: "profile=gpu, tag.rack=12", length: 2},
+		"negated":          {selector: "image!=rocky9", length: 1},
+		"missing value":    {selector: "profile", err: true},
+		"missing key":      {selector: "=gpu", err: true},
+		"invalid pattern":  {selector: "name=[n", err: true},
+		"empty condition":  {selector: "profile=gpu,", err: true},
+		"empty value":      {selector: "tag.rack=", length: 1},
+		"negated no value": {selector: "tag.rack!=", length: 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sel, err := ParseSelector(tt.selector)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, sel, tt.length)
+		})
+	}
+}
+
+func Test_FilterNodeListBySelector(t *testing.T) {
+	var data = `
+nodeprofiles:
+  default: {}
+  gpu:
+    image name: rocky9-gpu
+nodes:
+  n1:
+    profiles:
+    - default
+    image name: rocky9
+    tags:
+      rack: "12"
+    kernel:
+      version: 6.1.0
+  n2:
+    profiles:
+    - default
+    - gpu
+    tags:
+      rack: "13"
+    network devices:
+      default:
+        ipaddr: 10.0.0.2
+  n3:
+    profiles:
+    - default
+    image name: rocky8
+`
+	var nodesYaml NodesYaml
+	assert.NoError(t, yaml.Unmarshal([]byte(data), &nodesYaml))
+	nodes, err := nodesYaml.FindAllNodes()
+	assert.NoError(t, err)
+
+	var tests = map[string]struct {
+		selector string
+		nodes    []string
+	}{
+		"name":            {selector: "name=n1", nodes: []string{"n1"}},
+		"name glob":       {selector: "name=n[12]", nodes: []string{"n1", "n2"}},
+		"profile":         {selector: "profile=gpu", nodes: []string{"n2"}},
+		"profile negated": {selector: "profile!=gpu", nodes: []string{"n1", "n3"}},
+		"image from profile": {
+			selector: "image=rocky9*", nodes: []string{"n1", "n2"}},
+		"tag":            {selector: "tag.rack=1*", nodes: []string{"n1", "n2"}},
+		"tag unset":      {selector: "tag.rack=", nodes: []string{"n3"}},
+		"tag set":        {selector: "tag.rack!=", nodes: []string{"n1", "n2"}},
+		"field":          {selector: "kernel.version=6.*", nodes: []string{"n1"}},
+		"netdev field":   {selector: "NetDevs[default].Ipaddr=10.0.0.*", nodes: []string{"n2"}},
+		"multiple":       {selector: "profile=default,tag.rack=12", nodes: []string{"n1"}},
+		"no match":       {selector: "image=sles*", nodes: nil},
+		"unknown field":  {selector: "nosuchfield=x", nodes: nil},
+		"empty selector": {selector: "", nodes: []string{"n1", "n2", "n3"}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sel, err := ParseSelector(tt.selector)
+			assert.NoError(t, err)
+			var names []string
+			for _, n := range FilterNodeListBySelector(nodes, sel) {
+				names = append(names, n.Id())
+			}
+			assert.Equal(t, tt.nodes, names)
+		})
+	}
+}
@@ -136,10 +136,12 @@ func RemoveImage(nodeName string, context string, overlayNames []string) error {
 			return fmt.Errorf("failed to remove overlay image: %w", err)
 		}
 	}
-	compressedImagePath := imagePath + ".gz"
-	if util.IsFile(compressedImagePath) {
-		if err := os.Remove(compressedImagePath); err != nil {
-			return fmt.Errorf("failed to remove compressed overlay image: %w", err)
+	if err := util.RemoveCompressedFiles(imagePath, nil); err != nil {
+		return fmt.Errorf("failed to remove compressed overlay image: %w", err)
+	}
+	if util.IsFile(imagePath + digestSuffix) {
+		if err := os.Remove(imagePath + digestSuffix); err != nil {
+			return fmt.Errorf("failed to remove overlay image digest: %w", err)
 		}
 	}
 	return nil
@@ -42,6 +42,10 @@ type TemplateStruct struct {
 	Tftp          warewulfconf.TFTPConf
 	Paths         warewulfconf.BuildConfig
 	AllNodes      []node.Node
+	// Attributes holds the node's custom attributes as values of the types
+	// declared in warewulf.conf; it shadows node.Node.Attributes, which
+	// holds them as strings.
+	Attributes map[string]interface{}
 	node.Node
 	// backward compatiblity
 	Container     string
@@ -107,5 +111,6 @@ func InitStruct(overlayName string, nodeData node.Node, allNodes []node.Node) (T
 	if err := dec.Decode(&tstruct); err != nil {
 		return tstruct, err
 	}
+	tstruct.Attributes = nodeData.TypedAttributes()
 	return tstruct, nil
 }
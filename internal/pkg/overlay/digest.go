@@ -0,0 +1,153 @@
+package overlay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// digestSuffix is appended to the path of an overlay image to record the
+// digest of the configuration and sources it was built from.
+const digestSuffix = ".digest"
+
+/*
+A Digester computes the digests of overlay images: a hash of everything an
+image is built from, so that an image only has to be rebuilt when its digest
+changes. The digest covers the node's effective configuration, warewulf.conf,
+and the files of each overlay (the content of templates, and the size and
+modification time of other files). The configuration of all nodes is only
+included for overlays whose templates refer to .AllNodes, e.g., to render
+/etc/hosts.
+*/
+type Digester struct {
+	allNodes []node.Node
+
+	once        sync.Once
+	nodesDigest []byte
+	nodesErr    error
+}
+
+// NewDigester returns a Digester for images built from the given node
+// configuration.
+func NewDigester(allNodes []node.Node) *Digester {
+	return &Digester{allNodes: allNodes}
+}
+
+// Digest returns the digest of the image for context built for nodeConf from
+// overlayNames.
+func (digester *Digester) Digest(nodeConf node.Node, context string, overlayNames []string) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "node %s\n", nodeConf.Id())
+	if err := json.NewEncoder(hash).Encode(nodeConf); err != nil {
+		return "", err
+	}
+	if err := json.NewEncoder(hash).Encode(config.Get()); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(hash, "context %s\n", context)
+	usesAllNodes := false
+	for _, overlayName := range overlayNames {
+		fmt.Fprintf(hash, "overlay %s\n", overlayName)
+		overlay_, err := Get(overlayName)
+		if err != nil {
+			fmt.Fprintf(hash, "missing\n")
+			continue
+		}
+		uses, err := digestSources(hash, overlay_.Rootfs())
+		if err != nil {
+			return "", err
+		}
+		usesAllNodes = usesAllNodes || uses
+	}
+	if usesAllNodes {
+		digester.once.Do(func() {
+			nodesHash := sha256.New()
+			for _, n := range digester.allNodes {
+				fmt.Fprintf(nodesHash, "node %s\n", n.Id())
+				if err := json.NewEncoder(nodesHash).Encode(n); err != nil {
+					digester.nodesErr = err
+					return
+				}
+			}
+			digester.nodesDigest = nodesHash.Sum(nil)
+		})
+		if digester.nodesErr != nil {
+			return "", digester.nodesErr
+		}
+		_, _ = hash.Write(digester.nodesDigest)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// digestSources writes the files below rootfs to hash and returns true if
+// any template refers to .AllNodes.
+func digestSources(hash io.Writer, rootfs string) (usesAllNodes bool, err error) {
+	err = filepath.WalkDir(rootfs, func(walkPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(rootfs, walkPath)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s %s", relPath, info.Mode())
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(walkPath)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, " %s", target)
+		case info.Mode().IsRegular() && strings.HasSuffix(walkPath, ".ww"):
+			content, err := os.ReadFile(walkPath)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256(content)
+			fmt.Fprintf(hash, " %x", sum)
+			usesAllNodes = usesAllNodes || bytes.Contains(content, []byte("AllNodes"))
+		case info.Mode().IsRegular():
+			fmt.Fprintf(hash, " %d %d", info.Size(), info.ModTime().UnixNano())
+		}
+		fmt.Fprintln(hash)
+		return nil
+	})
+	return usesAllNodes, err
+}
+
+// ImageDigest returns the digest recorded when the image was built, or the
+// empty string if none was recorded.
+func ImageDigest(nodeName string, context string, overlayNames []string) string {
+	imagePath := Image(nodeName, context, overlayNames)
+	if imagePath == "" {
+		return ""
+	}
+	digest, err := os.ReadFile(imagePath + digestSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(digest))
+}
+
+// writeImageDigest records the digest of a newly built image.
+func writeImageDigest(imagePath string, digest string) {
+	if err := os.WriteFile(imagePath+digestSuffix, []byte(digest+"\n"), 0o640); err != nil {
+		wwlog.Warn("could not record digest of %s: %s", imagePath, err)
+	}
+}
@@ -0,0 +1,59 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Digest(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/var/lib/warewulf/overlays/o1/rootfs/motd.ww", `{{ .Id }}`)
+	env.WriteFile("/var/lib/warewulf/overlays/hosts/rootfs/etc/hosts.ww", `{{ range .AllNodes }}{{ .Id }}{{ end }}`)
+
+	n1 := node.NewNode("n1")
+	n2 := node.NewNode("n2")
+	digest := func(n node.Node, allNodes []node.Node, overlays ...string) string {
+		d, err := NewDigester(allNodes).Digest(n, "system", overlays)
+		assert.NoError(t, err)
+		return d
+	}
+
+	base := digest(n1, []node.Node{n1, n2}, "o1")
+	assert.Len(t, base, 64)
+	assert.Equal(t, base, digest(n1, []node.Node{n1, n2}, "o1"))
+	assert.NotEqual(t, base, digest(n2, []node.Node{n1, n2}, "o1"), "depends on the node")
+	assert.NotEqual(t, base, digest(n1, []node.Node{n1, n2}, "o1", "hosts"), "depends on the overlays")
+
+	changed := node.NewNode("n1")
+	changed.Comment = "changed"
+	assert.NotEqual(t, base, digest(changed, []node.Node{changed, n2}, "o1"), "depends on the node's configuration")
+
+	other := node.NewNode("n2")
+	other.Comment = "changed"
+	assert.Equal(t, base, digest(n1, []node.Node{n1, other}, "o1"), "does not depend on other nodes")
+	assert.NotEqual(t,
+		digest(n1, []node.Node{n1, n2}, "hosts"),
+		digest(n1, []node.Node{n1, other}, "hosts"),
+		"depends on other nodes for templates that use .AllNodes")
+
+	env.WriteFile("/var/lib/warewulf/overlays/o1/rootfs/motd.ww", `{{ .Id }}!`)
+	assert.NotEqual(t, base, digest(n1, []node.Node{n1, n2}, "o1"), "depends on the overlay's templates")
+}
+
+func Test_ImageDigest(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	assert.Equal(t, "", ImageDigest("n1", "system", nil))
+	env.WriteFile("/srv/warewulf/overlays/n1/__SYSTEM__.img", "image")
+	writeImageDigest(Image("n1", "system", nil), "abc123")
+	assert.Equal(t, "abc123", ImageDigest("n1", "system", nil))
+
+	assert.NoError(t, RemoveImage("n1", "system", nil))
+	assert.Equal(t, "", ImageDigest("n1", "system", nil))
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/coreos/go-systemd/v22/unit"
 
 	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/cpio"
+	"github.com/warewulf/warewulf/internal/pkg/history"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -148,14 +150,22 @@ func (overlay Overlay) AddFile(filePath string, content []byte, parents bool, fo
 	}
 
 	// if the file already exists and force is false, return an error
+	var before []byte
 	if util.IsFile(fullPath) {
-		if force {
-			return os.WriteFile(fullPath, content, 0o644)
+		if !force {
+			return fmt.Errorf("file %s already exists in overlay %s", filePath, overlay.Name())
+		}
+		var err error
+		if before, err = os.ReadFile(fullPath); err != nil {
+			return err
 		}
-		return fmt.Errorf("file %s already exists in overlay %s", filePath, overlay.Name())
 	}
 
-	return os.WriteFile(fullPath, content, 0o644)
+	if err := os.WriteFile(fullPath, content, 0o644); err != nil {
+		return err
+	}
+	history.RecordOverlayFile(overlay.Name(), filePath, before, content)
+	return nil
 }
 
 func (overlay Overlay) Delete(force bool) (err error) {
@@ -758,6 +768,7 @@ func resolveFieldChain(rootType reflect.Type, idents []string, basePath string)
 func BuildAllOverlays(nodes []node.Node, allNodes []node.Node, workerCount int) error {
 	nodeChan := make(chan node.Node, len(nodes))
 	errChan := make(chan error, len(nodes)*2)
+	digester := NewDigester(allNodes)
 
 	var wg sync.WaitGroup
 	worker := func() {
@@ -767,7 +778,7 @@ func BuildAllOverlays(nodes []node.Node, allNodes []node.Node, workerCount int)
 			if len(n.SystemOverlay) < 1 {
 				wwlog.Warn("No system overlays defined for %s", n.Id())
 			}
-			if err := BuildOverlay(n, allNodes, "system", n.SystemOverlay); err != nil {
+			if err := buildOverlay(n, allNodes, digester, "system", n.SystemOverlay); err != nil {
 				errChan <- fmt.Errorf("could not build system overlays %v for node %s: %w", n.SystemOverlay, n.Id(), err)
 			}
 
@@ -776,7 +787,7 @@ func BuildAllOverlays(nodes []node.Node, allNodes []node.Node, workerCount int)
 			if len(n.RuntimeOverlay) < 1 {
 				wwlog.Warn("No runtime overlays defined for %s", n.Id())
 			}
-			if err := BuildOverlay(n, allNodes, "runtime", n.RuntimeOverlay); err != nil {
+			if err := buildOverlay(n, allNodes, digester, "runtime", n.RuntimeOverlay); err != nil {
 				errChan <- fmt.Errorf("could not build runtime overlays %v for node %s: %w", n.RuntimeOverlay, n.Id(), err)
 			}
 		}
@@ -863,6 +874,10 @@ func FindOverlays() (overlayList []string) {
 Build the given overlays for a node and create an image for them
 */
 func BuildOverlay(nodeConf node.Node, allNodes []node.Node, context string, overlayNames []string) error {
+	return buildOverlay(nodeConf, allNodes, NewDigester(allNodes), context, overlayNames)
+}
+
+func buildOverlay(nodeConf node.Node, allNodes []node.Node, digester *Digester, context string, overlayNames []string) error {
 	if len(overlayNames) == 0 && context == "" {
 		return nil
 	}
@@ -896,6 +911,13 @@ func BuildOverlay(nodeConf node.Node, allNodes []node.Node, context string, over
 
 	wwlog.Debug("Created temporary directory for %s: %s", name, buildDir)
 
+	// the digest is taken before the build, so that changes made during
+	// the build cause another one
+	digest, digestErr := digester.Digest(nodeConf, context, overlayNames)
+	if digestErr != nil {
+		wwlog.Warn("could not compute digest of %s: %s", overlayImage, digestErr)
+	}
+
 	err = BuildOverlayIndir(nodeConf, allNodes, overlayNames, buildDir)
 	if err != nil {
 		return fmt.Errorf("failed to generate files for %s: %w", name, err)
@@ -903,7 +925,7 @@ func BuildOverlay(nodeConf node.Node, allNodes []node.Node, context string, over
 
 	wwlog.Debug("Generated files for %s", name)
 
-	err = util.BuildFsImage(
+	_, err = util.BuildFsImage(
 		name,
 		buildDir,
 		overlayImage,
@@ -911,7 +933,11 @@ func BuildOverlay(nodeConf node.Node, allNodes []node.Node, context string, over
 		[]string{},
 		// ignore cross-device files
 		true,
-		"newc")
+		config.Get().Warewulf.CompressionFormats(),
+		cpio.Options{})
+	if err == nil && digestErr == nil {
+		writeImageDigest(overlayImage, digest)
+	}
 
 	return err
 }
@@ -427,6 +427,29 @@ func Test_BuildOverlayIndir_PathTraversal(t *testing.T) {
 	}
 }
 
+func Test_BuildOverlayIndir_Attributes(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/warewulf.conf", `
+attributes:
+  gpus:
+    type: int
+  nics:
+    type: list
+  ib:
+    type: bool
+`)
+	env.Configure()
+	env.WriteFile("/var/lib/warewulf/overlays/o1/rootfs/attrs.txt.ww",
+		`{{ if gt .Attributes.gpus 1 }}multi-gpu{{ end }} {{ range .Attributes.nics }}{{ . }};{{ end }} {{ if .Attributes.ib }}ib{{ end }} {{ .ThisNode.Attributes.gpus }}`)
+	env.MkdirAll("/image")
+
+	n := node.NewNode("n1")
+	n.Attributes = map[string]string{"gpus": "4", "nics": "eth0,eth1", "ib": "false"}
+	assert.NoError(t, BuildOverlayIndir(n, []node.Node{n}, []string{"o1"}, env.GetPath("/image")))
+	assert.Equal(t, "multi-gpu eth0;eth1;  4", env.ReadFile("/image/attrs.txt"))
+}
+
 func Test_BuildOverlay(t *testing.T) {
 	tests := []struct {
 		description string
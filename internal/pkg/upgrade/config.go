@@ -19,25 +19,27 @@ func ParseConfig(data []byte) (warewulfYaml *WarewulfYaml, err error) {
 }
 
 type WarewulfYaml struct {
-	WWInternal      string        `yaml:"WW_INTERNAL"`
-	Comment         string        `yaml:"comment"`
-	Ipaddr          string        `yaml:"ipaddr"`
-	Ipaddr6         string        `yaml:"ipaddr6"`
-	Netmask         string        `yaml:"netmask"`
-	Network         string        `yaml:"network"`
-	Ipv6net         string        `yaml:"ipv6net"`
-	PrefixLen6      string        `yaml:"prefixlen6"`
-	Fqdn            string        `yaml:"fqdn"`
-	Warewulf        *WarewulfConf `yaml:"warewulf"`
-	API             *APIConf      `yaml:"api"`
-	DHCP            *DHCPConf     `yaml:"dhcp"`
-	TFTP            *TFTPConf     `yaml:"tftp"`
-	NFS             *NFSConf      `yaml:"nfs"`
-	SSH             *SSHConf      `yaml:"ssh"`
-	MountsImage     []*MountEntry `yaml:"image mounts"`
-	MountsContainer []*MountEntry `yaml:"container mounts"`
-	Paths           *BuildConfig  `yaml:"paths"`
-	WWClient        *WWClientConf `yaml:"wwclient"`
+	WWInternal      string                  `yaml:"WW_INTERNAL"`
+	Comment         string                  `yaml:"comment"`
+	Ipaddr          string                  `yaml:"ipaddr"`
+	Ipaddr6         string                  `yaml:"ipaddr6"`
+	Netmask         string                  `yaml:"netmask"`
+	Network         string                  `yaml:"network"`
+	Ipv6net         string                  `yaml:"ipv6net"`
+	PrefixLen6      string                  `yaml:"prefixlen6"`
+	Fqdn            string                  `yaml:"fqdn"`
+	Warewulf        *WarewulfConf           `yaml:"warewulf"`
+	API             *APIConf                `yaml:"api"`
+	DHCP            *DHCPConf               `yaml:"dhcp"`
+	TFTP            *TFTPConf               `yaml:"tftp"`
+	NFS             *NFSConf                `yaml:"nfs"`
+	SSH             *SSHConf                `yaml:"ssh"`
+	MountsImage     []*MountEntry           `yaml:"image mounts"`
+	MountsContainer []*MountEntry           `yaml:"container mounts"`
+	Paths           *BuildConfig            `yaml:"paths"`
+	WWClient        *WWClientConf           `yaml:"wwclient"`
+	Pools           map[string]*AddressPool `yaml:"address pools"`
+	Attributes      map[string]*Attribute   `yaml:"attributes"`
 }
 
 func (legacy *WarewulfYaml) Upgrade() (upgraded *config.WarewulfYaml) {
@@ -98,6 +100,22 @@ func (legacy *WarewulfYaml) Upgrade() (upgraded *config.WarewulfYaml) {
 	if legacy.WWClient != nil {
 		upgraded.WWClient = legacy.WWClient.Upgrade()
 	}
+	if legacy.Pools != nil {
+		upgraded.Pools = make(map[string]*config.AddressPool)
+		for name, pool := range legacy.Pools {
+			if pool != nil {
+				upgraded.Pools[name] = pool.Upgrade()
+			}
+		}
+	}
+	if legacy.Attributes != nil {
+		upgraded.Attributes = make(map[string]*config.Attribute)
+		for name, attribute := range legacy.Attributes {
+			if attribute != nil {
+				upgraded.Attributes[name] = attribute.Upgrade()
+			}
+		}
+	}
 	if legacy.Warewulf != nil && legacy.Warewulf.DataStore != "" {
 		if upgraded.Paths == nil {
 			upgraded.Paths = new(config.BuildConfig)
@@ -110,18 +128,22 @@ func (legacy *WarewulfYaml) Upgrade() (upgraded *config.WarewulfYaml) {
 }
 
 type WarewulfConf struct {
-	Port              int    `yaml:"port"`
-	TLSPort           int    `yaml:"tls port"`
-	Secure            *bool  `yaml:"secure"`
-	SecureFiles       *bool  `yaml:"secure files"`
-	TLSEnabled        *bool  `yaml:"tls"`
-	UpdateInterval    int    `yaml:"update interval"`
-	AutobuildOverlays *bool  `yaml:"autobuild overlays"`
-	EnableHostOverlay *bool  `yaml:"host overlay"`
-	Syslog            *bool  `yaml:"syslog"`
-	DataStore         string `yaml:"datastore"`
-	GrubBoot          *bool  `yaml:"grubboot"`
-	SystemdName       string `yaml:"systemd name"`
+	Port              int      `yaml:"port"`
+	TLSPort           int      `yaml:"tls port"`
+	Secure            *bool    `yaml:"secure"`
+	SecureFiles       *bool    `yaml:"secure files"`
+	TLSEnabled        *bool    `yaml:"tls"`
+	UpdateInterval    int      `yaml:"update interval"`
+	AutobuildOverlays *bool    `yaml:"autobuild overlays"`
+	PrebuildOverlays  *bool    `yaml:"prebuild overlays"`
+	EnableHostOverlay *bool    `yaml:"host overlay"`
+	Syslog            *bool    `yaml:"syslog"`
+	DataStore         string   `yaml:"datastore"`
+	GrubBoot          *bool    `yaml:"grubboot"`
+	SystemdName       string   `yaml:"systemd name"`
+	Compression       []string `yaml:"compression"`
+	History           *bool    `yaml:"history"`
+	HistoryLimit      int      `yaml:"history limit"`
 }
 
 func (legacy *WarewulfConf) Upgrade() (upgraded *config.WarewulfConf) {
@@ -133,12 +155,16 @@ func (legacy *WarewulfConf) Upgrade() (upgraded *config.WarewulfConf) {
 	upgraded.TLSEnabledP = legacy.TLSEnabled
 	upgraded.UpdateInterval = legacy.UpdateInterval
 	upgraded.AutobuildOverlaysP = legacy.AutobuildOverlays
+	upgraded.PrebuildOverlaysP = legacy.PrebuildOverlays
+	upgraded.Compression = legacy.Compression
 	upgraded.EnableHostOverlayP = legacy.EnableHostOverlay
 	if legacy.Syslog != nil {
 		wwlog.Warn("syslog configuration ignored: all logs now go to stdout/stderr")
 	}
 	upgraded.GrubBootP = legacy.GrubBoot
 	upgraded.SystemdName = legacy.SystemdName
+	upgraded.HistoryP = legacy.History
+	upgraded.HistoryLimit = legacy.HistoryLimit
 	return upgraded
 }
 
@@ -307,3 +333,37 @@ func (legacy *WWClientConf) Upgrade() (upgraded *config.WWClientConf) {
 	upgraded.Port = legacy.Port
 	return upgraded
 }
+
+type AddressPool struct {
+	Subnet     string   `yaml:"subnet"`
+	RangeStart string   `yaml:"range start"`
+	RangeEnd   string   `yaml:"range end"`
+	Gateway    string   `yaml:"gateway"`
+	Exclude    []string `yaml:"exclude"`
+}
+
+func (legacy *AddressPool) Upgrade() (upgraded *config.AddressPool) {
+	upgraded = new(config.AddressPool)
+	upgraded.Subnet = legacy.Subnet
+	upgraded.RangeStart = legacy.RangeStart
+	upgraded.RangeEnd = legacy.RangeEnd
+	upgraded.Gateway = legacy.Gateway
+	upgraded.Exclude = append(upgraded.Exclude, legacy.Exclude...)
+	return upgraded
+}
+
+type Attribute struct {
+	Type        string   `yaml:"type"`
+	Default     string   `yaml:"default"`
+	Values      []string `yaml:"values"`
+	Description string   `yaml:"description"`
+}
+
+func (legacy *Attribute) Upgrade() (upgraded *config.Attribute) {
+	upgraded = new(config.Attribute)
+	upgraded.Type = legacy.Type
+	upgraded.Default = legacy.Default
+	upgraded.Values = append(upgraded.Values, legacy.Values...)
+	upgraded.Description = legacy.Description
+	return upgraded
+}
@@ -739,6 +739,87 @@ dhcp:
 tftp:
   enabled: true
   systemd name: tftp
+`,
+	},
+	{
+		name: "address pools",
+		legacyYaml: `
+ipaddr: 10.0.0.1
+netmask: 255.255.252.0
+warewulf:
+  port: 9873
+address pools:
+  compute:
+    subnet: 10.0.0.0/22
+    range start: 10.0.1.1
+    range end: 10.0.1.254
+    gateway: 10.0.0.1
+    exclude:
+    - 10.0.1.10-10.0.1.19
+  bmc:
+    subnet: 10.0.4.0/24
+`,
+		upgradedYaml: `
+ipaddr: 10.0.0.1
+netmask: 255.255.252.0
+warewulf:
+  port: 9873
+address pools:
+  bmc:
+    subnet: 10.0.4.0/24
+  compute:
+    subnet: 10.0.0.0/22
+    range start: 10.0.1.1
+    range end: 10.0.1.254
+    gateway: 10.0.0.1
+    exclude:
+      - 10.0.1.10-10.0.1.19
+`,
+	},
+	{
+		name: "history",
+		legacyYaml: `
+warewulf:
+  port: 9873
+  history: true
+  history limit: 20
+`,
+		upgradedYaml: `
+warewulf:
+  port: 9873
+  history: true
+  history limit: 20
+`,
+	},
+	{
+		name: "attributes",
+		legacyYaml: `
+warewulf:
+  port: 9873
+attributes:
+  rack:
+    type: int
+    description: rack number
+  role:
+    type: string
+    default: compute
+    values:
+    - compute
+    - login
+`,
+		upgradedYaml: `
+warewulf:
+  port: 9873
+attributes:
+  rack:
+    type: int
+    description: rack number
+  role:
+    type: string
+    default: compute
+    values:
+      - compute
+      - login
 `,
 	},
 }
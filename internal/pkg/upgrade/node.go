@@ -46,7 +46,8 @@ func ParseNodes(data []byte) (nodesYaml *NodesYaml, err error) {
 }
 
 type NodesYaml struct {
-	WWInternal   string `yaml:"WW_INTERNAL"`
+	WWInternal   string   `yaml:"WW_INTERNAL"`
+	Include      []string `yaml:"include"`
 	NodeProfiles map[string]*Profile
 	Nodes        map[string]*Node
 }
@@ -58,6 +59,7 @@ func (legacy *NodesYaml) Upgrade(addDefaults bool, replaceOverlays bool, warewul
 	if legacy.WWInternal != "" {
 		logIgnore("WW_INTERNAL", legacy.WWInternal, "obsolete")
 	}
+	upgraded.Include = append(upgraded.Include, legacy.Include...)
 	for name, profile := range legacy.NodeProfiles {
 		upgraded.NodeProfiles[name] = profile.Upgrade(addDefaults, replaceOverlays)
 	}
@@ -168,6 +170,9 @@ func (legacy *NodesYaml) Upgrade(addDefaults bool, replaceOverlays bool, warewul
 
 type Node struct {
 	Discoverable string `yaml:"discoverable,omitempty"`
+	State        string `yaml:"state,omitempty"`
+	StateReason  string `yaml:"state reason,omitempty"`
+	StateChanged string `yaml:"state changed,omitempty"`
 	Profile      `yaml:"-,inline"`
 }
 
@@ -181,6 +186,9 @@ func (legacy *Node) Upgrade(addDefaults bool, replaceOverlays bool) (upgraded *n
 	upgraded.Kernel = new(node.KernelConf)
 	upgraded.NetDevs = make(map[string]*node.NetDev)
 	upgraded.AssetKey = legacy.AssetKey
+	upgraded.State = legacy.State
+	upgraded.StateReason = legacy.StateReason
+	upgraded.StateChanged = legacy.StateChanged
 	upgraded.ClusterName = legacy.ClusterName
 	upgraded.Comment = legacy.Comment
 	upgraded.ImageName = legacy.ImageName
@@ -339,6 +347,12 @@ func (legacy *Node) Upgrade(addDefaults bool, replaceOverlays bool) (upgraded *n
 			upgraded.Resources[key] = value
 		}
 	}
+	if legacy.Attributes != nil {
+		upgraded.Attributes = make(map[string]string)
+		for key, value := range legacy.Attributes {
+			upgraded.Attributes[key] = value
+		}
+	}
 	return
 }
 
@@ -377,6 +391,7 @@ type Profile struct {
 	Tags           map[string]string      `yaml:"tags,omitempty"`
 	TagsDel        []string               `yaml:"tagsdel,omitempty"`
 	Resources      map[string]Resource    `yaml:"resources,omitempty"`
+	Attributes     map[string]string      `yaml:"attributes,omitempty"`
 }
 
 type Resource interface{}
@@ -537,6 +552,12 @@ func (legacy *Profile) Upgrade(addDefaults bool, replaceOverlays bool) (upgraded
 			upgraded.Resources[key] = value
 		}
 	}
+	if legacy.Attributes != nil {
+		upgraded.Attributes = make(map[string]string)
+		for key, value := range legacy.Attributes {
+			upgraded.Attributes[key] = value
+		}
+	}
 	return
 }
 
@@ -547,6 +568,7 @@ type IpmiConf struct {
 	Ipaddr     string            `yaml:"ipaddr,omitempty"`
 	Netmask    string            `yaml:"netmask,omitempty"`
 	Password   string            `yaml:"password,omitempty"`
+	Pool       string            `yaml:"pool,omitempty"`
 	Port       string            `yaml:"port,omitempty"`
 	Tags       map[string]string `yaml:"tags,omitempty"`
 	TagsDel    []string          `yaml:"tagsdel,omitempty"`
@@ -564,6 +586,7 @@ func (legacy *IpmiConf) Upgrade() (upgraded *node.IpmiConf) {
 	upgraded.Ipaddr = net.ParseIP(legacy.Ipaddr)
 	upgraded.Netmask = net.ParseIP(legacy.Netmask)
 	upgraded.Password = legacy.Password
+	upgraded.Pool = legacy.Pool
 	upgraded.Port = legacy.Port
 	upgraded.Template = legacy.Template
 	if legacy.Tags != nil {
@@ -647,6 +670,7 @@ type NetDev struct {
 	MTU        string            `yaml:"mtu,omitempty"`
 	Netmask    string            `yaml:"netmask,omitempty"`
 	OnBoot     string            `yaml:"onboot,omitempty"`
+	Pool       string            `yaml:"pool,omitempty"`
 	Prefix     string            `yaml:"prefix,omitempty"`
 	PrefixLen6 string            `yaml:"prefixlen6,omitempty"`
 	Primary    string            `yaml:"primary,omitempty"`
@@ -689,6 +713,7 @@ func (legacy *NetDev) Upgrade(addDefaults bool) (upgraded *node.NetDev) {
 	if legacy.OnBoot != "" {
 		warnError(upgraded.OnBoot.Set(legacy.OnBoot))
 	}
+	upgraded.Pool = legacy.Pool
 	if legacy.Prefix != "" {
 		logIgnore("Prefix", legacy.Prefix, "obsolete")
 	}
@@ -463,6 +463,51 @@ nodes:
       resn2:
         - valn2a
         - valn2b
+`,
+	},
+	{
+		name:            "attributes",
+		addDefaults:     false,
+		replaceOverlays: false,
+		legacyYaml: `
+nodeprofiles:
+  default:
+    attributes:
+      rack: "12"
+nodes:
+  n1:
+    attributes:
+      role: login
+`,
+		upgradedYaml: `
+nodeprofiles:
+  default:
+    attributes:
+      rack: "12"
+nodes:
+  n1:
+    attributes:
+      role: login
+`,
+	},
+	{
+		name:            "lifecycle state",
+		addDefaults:     false,
+		replaceOverlays: false,
+		legacyYaml: `
+nodes:
+  n1:
+    state: maintenance
+    state reason: replacing DIMM
+    state changed: "2026-01-02T03:04:05Z"
+`,
+		upgradedYaml: `
+nodeprofiles: {}
+nodes:
+  n1:
+    state: maintenance
+    state reason: replacing DIMM
+    state changed: "2026-01-02T03:04:05Z"
 `,
 	},
 	{
@@ -1184,6 +1229,66 @@ nodes:
   n1:
     ipmi:
       template: node-ipmi.tmpl
+`,
+	},
+	{
+		name:            "address pools",
+		addDefaults:     false,
+		replaceOverlays: false,
+		legacyYaml: `
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        pool: compute
+    ipmi:
+      pool: bmc
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 10.0.1.1
+        pool: compute
+`,
+		upgradedYaml: `
+nodeprofiles:
+  default:
+    network devices:
+      default:
+        pool: compute
+    ipmi:
+      pool: bmc
+nodes:
+  n1:
+    network devices:
+      default:
+        ipaddr: 10.0.1.1
+        pool: compute
+`,
+	},
+	{
+		name:            "include",
+		addDefaults:     false,
+		replaceOverlays: false,
+		legacyYaml: `
+include:
+  - racks/*.conf
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default
+`,
+		upgradedYaml: `
+include:
+  - racks/*.conf
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default
 `,
 	},
 }
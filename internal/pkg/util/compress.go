@@ -0,0 +1,68 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression formats of images, as named by the compress parameter.
+const (
+	CompressGz   = "gz"
+	CompressZstd = "zstd"
+	CompressXz   = "xz"
+)
+
+// CompressFormats lists the supported compression formats.
+var CompressFormats = []string{CompressGz, CompressZstd, CompressXz}
+
+var compressSuffixes = map[string]string{
+	CompressGz:   ".gz",
+	CompressZstd: ".zst",
+	CompressXz:   ".xz",
+}
+
+// CompressedFile returns the path of the copy of file compressed with
+// format, or the empty string if format is not supported.
+func CompressedFile(file string, format string) string {
+	suffix, ok := compressSuffixes[format]
+	if !ok {
+		return ""
+	}
+	return file + suffix
+}
+
+// NewCompressWriter returns a writer that compresses what is written to it
+// with format into w. Closing it flushes the compressed data, but does not
+// close w.
+func NewCompressWriter(w io.Writer, format string) (io.WriteCloser, error) {
+	switch format {
+	case CompressGz:
+		return pgzip.NewWriter(w), nil
+	case CompressZstd:
+		return zstd.NewWriter(w)
+	case CompressXz:
+		return xz.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported compression format: %s", format)
+	}
+}
+
+// RemoveCompressedFiles removes the compressed copies of file, except those
+// in the formats listed in keep.
+func RemoveCompressedFiles(file string, keep []string) error {
+	for _, format := range CompressFormats {
+		if InSlice(keep, format) {
+			continue
+		}
+		compressedFile := CompressedFile(file, format)
+		if err := os.Remove(compressedFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove %s: %w", compressedFile, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package util_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulikunitz/xz"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+func Test_NewCompressWriter(t *testing.T) {
+	tests := map[string]struct {
+		format     string
+		suffix     string
+		decompress func(io.Reader) (io.Reader, error)
+	}{
+		"gz": {
+			format: util.CompressGz,
+			suffix: ".gz",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		"zstd": {
+			format: util.CompressZstd,
+			suffix: ".zst",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return zstd.NewReader(r)
+			},
+		},
+		"xz": {
+			format: util.CompressXz,
+			suffix: ".xz",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return xz.NewReader(r)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "/image.img"+tt.suffix, util.CompressedFile("/image.img", tt.format))
+
+			var compressed bytes.Buffer
+			writer, err := util.NewCompressWriter(&compressed, tt.format)
+			assert.NoError(t, err)
+			_, err = io.WriteString(writer, "image content")
+			assert.NoError(t, err)
+			assert.NoError(t, writer.Close())
+
+			reader, err := tt.decompress(&compressed)
+			assert.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, "image content", string(content))
+		})
+	}
+}
+
+func Test_NewCompressWriter_Unsupported(t *testing.T) {
+	assert.Equal(t, "", util.CompressedFile("/image.img", "lz4"))
+	_, err := util.NewCompressWriter(io.Discard, "lz4")
+	assert.ErrorContains(t, err, "unsupported compression format: lz4")
+}
+
+func Test_RemoveCompressedFiles(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/image.img", "image")
+	env.WriteFile("/image.img.gz", "gz")
+	env.WriteFile("/image.img.zst", "zstd")
+
+	assert.NoError(t, util.RemoveCompressedFiles(env.GetPath("/image.img"), []string{util.CompressGz}))
+	assert.FileExists(t, env.GetPath("/image.img"))
+	assert.FileExists(t, env.GetPath("/image.img.gz"))
+	assert.NoFileExists(t, env.GetPath("/image.img.zst"))
+}
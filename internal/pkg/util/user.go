@@ -0,0 +1,19 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+)
+
+// CurrentUser returns the name of the user running the command, preferring
+// the invoking user when run with sudo.
+func CurrentUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return fmt.Sprintf("uid:%d", os.Getuid())
+}
@@ -3,9 +3,11 @@ package util
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"io/fs"
 	"net"
@@ -14,13 +16,14 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/warewulf/warewulf/internal/pkg/cpio"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
@@ -367,165 +370,39 @@ func AppendLines(fileName string, lines []string) (err error) {
 	return nil
 }
 
-var (
-	cpioRenumberInodesOnce   sync.Once
-	cpioRenumberInodesCached bool
-)
-
-// cpioRenumberInodesSupported returns true if the installed cpio supports
-// --renumber-inodes (GNU cpio >= 2.13). Result is cached after the first call.
-func cpioRenumberInodesSupported() bool {
-	cpioRenumberInodesOnce.Do(func() {
-		out, _ := exec.Command("cpio", "--help").CombinedOutput()
-		cpioRenumberInodesCached = strings.Contains(string(out), "--renumber-inodes")
-	})
-	return cpioRenumberInodesCached
+// contextWriter fails once its context is cancelled.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
 }
 
-/*
-******************************************************************************
-
-	Create an archive using cpio
-*/
-func CpioCreate(
-	rootdir string,
-	ifiles []string,
-	ofile string,
-	format string,
-	cpio_args ...string,
-) (err error) {
-	args := []string{
-		"--quiet",
-		"--create",
-		"--directory", rootdir,
-		"--format", format,
-		"--file=" + ofile,
+func (writer contextWriter) Write(p []byte) (int, error) {
+	if err := writer.ctx.Err(); err != nil {
+		return 0, err
 	}
-
-	if cpioRenumberInodesSupported() {
-		args = append(args, "--renumber-inodes")
-	}
-
-	args = append(args, cpio_args...)
-
-	proc := exec.Command("cpio", args...)
-
-	stdin, err := proc.StdinPipe()
-	if err != nil {
-		return err
-	}
-
-	err_in := make(chan error, 1)
-	go func() {
-		defer func() { _ = stdin.Close() }()
-		_, err := io.WriteString(stdin, strings.Join(ifiles, "\n"))
-		err_in <- err
-	}()
-
-	out, err := proc.CombinedOutput()
-	if err != nil {
-		wwlog.Error("cpio failed: %s", out)
-	} else if len(out) > 0 {
-		wwlog.Debug("cpio: %s", out)
-	}
-
-	return FirstError(err, <-err_in)
+	return writer.w.Write(p)
 }
 
-/*
-******************************************************************************
-
-	Compress a file using gzip or pigz
-*/
-func FileGz(
-	file string,
-) (err error) {
-	file_gz := file + ".gz"
-
-	if IsFile(file_gz) {
-		err := os.Remove(file_gz)
-		if err != nil {
-			return fmt.Errorf("could not remove existing file: %s: %w", file_gz, err)
-		}
-	}
-
-	compressor, err := exec.LookPath("pigz")
-	if err != nil {
-		wwlog.Verbose("Could not locate PIGZ")
-		compressor, err = exec.LookPath("gzip")
-		if err != nil {
-			wwlog.Verbose("Could not locate GZIP")
-			return fmt.Errorf("no compressor program for image file: %s: %w", file_gz, err)
-		}
-	}
-
-	wwlog.Verbose("Using compressor program: %s", compressor)
-
-	proc := exec.Command(
-		compressor,
-		"--keep",
-		file)
-
-	out, err := proc.CombinedOutput()
-	if len(out) > 0 {
-		outStr := string(out[:])
-		if err != nil && strings.HasSuffix(compressor, "gzip") && strings.Contains(outStr, "unrecognized option") {
-			var gzippedFile *os.File
-			var gzipStderr io.ReadCloser
-
-			/* Older version of gzip, try it another way: */
-			wwlog.Verbose("%s does not recognize the --keep flag, trying redirected stdout", compressor)
-
-			/* Open the output file for writing: */
-			gzippedFile, err = os.Create(file_gz)
-			if err != nil {
-				return fmt.Errorf("unable to open compressed image file for writing: %s: %w", file_gz, err)
-			}
-
-			/* We'll execute gzip with output to stdout and attach stdout to the compressed file we just
-			   created:
-			*/
-			proc = exec.Command(
-				compressor,
-				"--stdout",
-				file)
-			proc.Stdout = gzippedFile
-			gzipStderr, err = proc.StderrPipe()
-			if err != nil {
-				return fmt.Errorf("unable to open stderr pipe for compression program: %s: %w", compressor, err)
-			}
-
-			/* Execute the command: */
-			err = proc.Start()
-			if err != nil {
-				_ = proc.Wait()
-				_ = gzippedFile.Close()
-				_ = os.Remove(file_gz)
-				err = fmt.Errorf("unable to successfully execute compression program: %s: %w", compressor, err)
-			} else {
-				err = proc.Wait()
-				if cerr := gzippedFile.Close(); cerr != nil {
-					wwlog.Warn("failed to close compressed image file %s: %s", file_gz, cerr)
-				}
-				if err != nil {
-					_ = os.Remove(file_gz)
-					err = fmt.Errorf("unable to successfully create compressed image file: %s: %w", file_gz, err)
-				} else {
-					wwlog.Verbose("Successfully compressed image file: %s", file_gz)
-				}
-			}
-			out, _ = io.ReadAll(gzipStderr)
-		}
-		wwlog.Debug(string(out))
-	}
-
-	return err
+// An ImageFile is a file written by BuildFsImage.
+type ImageFile struct {
+	Path string
+	// Compression is the compression format of the file, or empty for the
+	// uncompressed image.
+	Compression string
+	Size        int64
+	// Digest is the SHA-256 digest of the file, as "sha256:<hex>".
+	Digest string
 }
 
 /*
 ******************************************************************************
 
-	Create an archive using cpio
+	Create a newc cpio archive of the files below rootfsPath, and a copy
+	compressed with each of the given compression formats, in a single pass.
+	The image and its compressed copies replace any existing ones only once
+	they are complete. If SOURCE_DATE_EPOCH is set, it is used as the
+	modification time of every file, so that the image is reproducible.
+	The written files are returned with their digests.
 */
 func BuildFsImage(
 	name string,
@@ -534,12 +411,28 @@ func BuildFsImage(
 	include []string,
 	ignore []string,
 	ignore_xdev bool,
-	format string,
-	cpio_args ...string,
-) (err error) {
+	compress []string,
+	opts cpio.Options,
+) ([]ImageFile, error) {
+	return BuildFsImageContext(context.Background(), name, rootfsPath, imagePath, include, ignore, ignore_xdev, compress, opts)
+}
+
+// BuildFsImageContext builds an image as BuildFsImage does, stopping early,
+// without replacing the image, if ctx is cancelled.
+func BuildFsImageContext(
+	ctx context.Context,
+	name string,
+	rootfsPath string,
+	imagePath string,
+	include []string,
+	ignore []string,
+	ignore_xdev bool,
+	compress []string,
+	opts cpio.Options,
+) (imageFiles []ImageFile, err error) {
 	err = os.MkdirAll(path.Dir(imagePath), 0o755)
 	if err != nil {
-		return fmt.Errorf("failed to create image directory for %s: %s: %w", name, imagePath, err)
+		return nil, fmt.Errorf("failed to create image directory for %s: %s: %w", name, imagePath, err)
 	}
 	wwlog.Debug("Created image directory for %s: %s", name, imagePath)
 
@@ -549,29 +442,111 @@ func BuildFsImage(
 		ignore,
 		ignore_xdev)
 	if err != nil {
-		return fmt.Errorf("failed discovering files for %s: %s: %w", name, rootfsPath, err)
+		return nil, fmt.Errorf("failed discovering files for %s: %s: %w", name, rootfsPath, err)
 	}
 
-	err = CpioCreate(
-		rootfsPath,
-		files,
-		imagePath,
-		format,
-		cpio_args...)
-	if err != nil {
-		return fmt.Errorf("failed creating image for %s: %s: %w", name, imagePath, err)
+	if opts.Mtime.IsZero() {
+		if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+			seconds, err := strconv.ParseInt(epoch, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %s", epoch)
+			}
+			opts.Mtime = time.Unix(seconds, 0)
+		}
 	}
 
-	wwlog.Info("Created image for %s: %s", name, imagePath)
+	outputs := []string{imagePath}
+	for _, compressFormat := range compress {
+		compressedFile := CompressedFile(imagePath, compressFormat)
+		if compressedFile == "" {
+			return nil, fmt.Errorf("unsupported compression format: %s", compressFormat)
+		}
+		outputs = append(outputs, compressedFile)
+	}
 
-	err = FileGz(imagePath)
-	if err != nil {
-		return fmt.Errorf("failed to compress image for %s: %s: %w", name, imagePath+".gz", err)
+	var tempFiles []*os.File
+	defer func() {
+		for _, tempFile := range tempFiles {
+			_ = tempFile.Close()
+			if err != nil {
+				_ = os.Remove(tempFile.Name())
+			}
+		}
+	}()
+	var writers []io.Writer
+	var compressors []io.WriteCloser
+	var digests []hash.Hash
+	for i, output := range outputs {
+		tempFile, err := os.CreateTemp(path.Dir(output), "."+path.Base(output)+"-")
+		if err != nil {
+			return nil, fmt.Errorf("failed creating image for %s: %s: %w", name, output, err)
+		}
+		tempFiles = append(tempFiles, tempFile)
+		digest := sha256.New()
+		digests = append(digests, digest)
+		if i == 0 {
+			writers = append(writers, tempFile, digest)
+			continue
+		}
+		compressor, err := NewCompressWriter(io.MultiWriter(tempFile, digest), compress[i-1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress image for %s: %s: %w", name, output, err)
+		}
+		compressors = append(compressors, compressor)
+		writers = append(writers, compressor)
 	}
 
-	wwlog.Info("Compressed image for %s: %s", name, imagePath+".gz")
+	buffer := bufio.NewWriterSize(contextWriter{ctx, io.MultiWriter(writers...)}, 1<<20)
+	err = cpio.WriteFiles(buffer, rootfsPath, files, opts)
+	if err == nil {
+		err = buffer.Flush()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed creating image for %s: %s: %w", name, imagePath, err)
+	}
+	for i, compressor := range compressors {
+		if err = compressor.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress image for %s: %s: %w", name, outputs[i+1], err)
+		}
+	}
+	for i, tempFile := range tempFiles {
+		if err = tempFile.Chmod(0o644); err != nil {
+			return nil, err
+		}
+		stat, err := tempFile.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if err = tempFile.Close(); err != nil {
+			return nil, fmt.Errorf("failed writing image for %s: %s: %w", name, outputs[i], err)
+		}
+		imageFile := ImageFile{
+			Path:   outputs[i],
+			Size:   stat.Size(),
+			Digest: "sha256:" + hex.EncodeToString(digests[i].Sum(nil)),
+		}
+		if i > 0 {
+			imageFile.Compression = compress[i-1]
+		}
+		imageFiles = append(imageFiles, imageFile)
+	}
+	for i, tempFile := range tempFiles {
+		if err = os.Rename(tempFile.Name(), outputs[i]); err != nil {
+			return nil, fmt.Errorf("failed writing image for %s: %s: %w", name, outputs[i], err)
+		}
+		if i == 0 {
+			wwlog.Info("Created image for %s: %s", name, outputs[i])
+		} else {
+			wwlog.Info("Compressed image for %s: %s", name, outputs[i])
+		}
+	}
 
-	return nil
+	// compressed copies in formats that are no longer configured would be
+	// out of date
+	if err = RemoveCompressedFiles(imagePath, compress); err != nil {
+		return nil, err
+	}
+	return imageFiles, nil
 }
 
 /*
@@ -0,0 +1,418 @@
+// Package validate checks a node registry (nodes.conf) for values that would
+// otherwise only be discovered when a node boots or its overlays are built,
+// and reports each problem with the file and line on which it is defined.
+package validate
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/ipam"
+	"github.com/warewulf/warewulf/internal/pkg/kernel"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the severity of a Problem.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// A Problem is an invalid or suspicious value in the node registry.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line"`
+	Kind     string   `json:"kind"`
+	ID       string   `json:"id"`
+	Message  string   `json:"message"`
+}
+
+func (problem Problem) String() string {
+	if problem.File != "" {
+		return fmt.Sprintf("%s:%d: %s: %s %s: %s", problem.File, problem.Line, problem.Severity, problem.Kind, problem.ID, problem.Message)
+	} else if problem.Line == 0 {
+		return fmt.Sprintf("%s: %s %s: %s", problem.Severity, problem.Kind, problem.ID, problem.Message)
+	}
+	return fmt.Sprintf("%d: %s: %s %s: %s", problem.Line, problem.Severity, problem.Kind, problem.ID, problem.Message)
+}
+
+// Errors returns the number of problems with Error severity.
+func Errors(problems []Problem) (count int) {
+	for _, problem := range problems {
+		if problem.Severity == Error {
+			count++
+		}
+	}
+	return count
+}
+
+// File validates the node registry in the named file, together with the
+// fragments it includes.
+func File(fileName string) ([]Problem, error) {
+	registry, err := node.Load(fileName)
+	if err != nil {
+		return nil, err
+	}
+	roots := make(map[string]*yaml.Node)
+	for _, file := range registry.Files() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		roots[file] = &root
+	}
+	return validate(&registry, roots)
+}
+
+// Validate validates a node registry. The images, overlays, and kernels that
+// nodes and profiles refer to must exist; addresses must be well-formed and
+// not assigned to more than one node; and numeric values must be in range.
+// An error is returned only if data cannot be parsed.
+func Validate(data []byte) ([]Problem, error) {
+	registry, err := node.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return validate(&registry, map[string]*yaml.Node{"": &root})
+}
+
+// Registry validates a node registry that may have been changed since it was
+// read, e.g., to check changes before they are persisted. The problems are
+// not located in a file.
+func Registry(registry *node.NodesYaml) ([]Problem, error) {
+	problems, err := validate(registry, map[string]*yaml.Node{})
+	for i := range problems {
+		problems[i].File = ""
+		problems[i].Line = 0
+	}
+	return problems, err
+}
+
+// validate validates registry, locating problems in the parsed documents of
+// the files that define its nodes and profiles.
+func validate(registry *node.NodesYaml, roots map[string]*yaml.Node) ([]Problem, error) {
+	v := &validator{
+		registry: registry,
+		roots:    roots,
+		overlays: make(map[string]bool),
+	}
+	for _, name := range overlay.FindOverlays() {
+		v.overlays[name] = true
+	}
+	// if the images cannot be listed, image names are not checked, rather
+	// than reported as not found
+	if sources, err := image.ListSources(); err != nil {
+		wwlog.Warn("Could not list images, image names are not checked: %s", err)
+	} else {
+		v.images = make(map[string]bool)
+		for _, name := range sources {
+			v.images[name] = true
+		}
+	}
+
+	for _, id := range sortedKeys(v.registry.NodeProfiles) {
+		v.checkProfile(entity{kind: "profile", id: id}, registry.NodeProfiles[id])
+	}
+	for _, id := range sortedKeys(v.registry.Nodes) {
+		if n := v.registry.Nodes[id]; n != nil {
+			v.checkProfile(entity{kind: "node", id: id}, &n.Profile)
+		}
+	}
+	if err := v.checkNodes(); err != nil {
+		return v.problems, err
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		if v.problems[i].File != v.problems[j].File {
+			return v.problems[i].File < v.problems[j].File
+		}
+		return v.problems[i].Line < v.problems[j].Line
+	})
+	return v.problems, nil
+}
+
+// An entity is a node or a profile in the registry.
+type entity struct {
+	kind string
+	id   string
+}
+
+func (e entity) section() string {
+	if e.kind == "node" {
+		return "nodes"
+	}
+	return "nodeprofiles"
+}
+
+type validator struct {
+	registry *node.NodesYaml
+	roots    map[string]*yaml.Node
+	overlays map[string]bool
+	// images is nil if the images could not be listed
+	images   map[string]bool
+	problems []Problem
+}
+
+func (v *validator) report(severity Severity, e entity, path []string, format string, args ...interface{}) {
+	file := v.registry.ProfileFile(e.id)
+	if e.kind == "node" {
+		file = v.registry.NodeFile(e.id)
+	}
+	v.problems = append(v.problems, Problem{
+		Severity: severity,
+		File:     file,
+		Line:     v.line(v.roots[file], append([]string{e.section(), e.id}, path...)...),
+		Kind:     e.kind,
+		ID:       e.id,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// line returns the line of the value at path in a document, or of its
+// deepest ancestor that exists. Elements of a sequence are matched by value.
+func (v *validator) line(root *yaml.Node, path ...string) int {
+	if root == nil {
+		return 0
+	}
+	current := root
+	if current.Kind == yaml.DocumentNode && len(current.Content) > 0 {
+		current = current.Content[0]
+	}
+	line := current.Line
+	for _, key := range path {
+		var next *yaml.Node
+		switch current.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(current.Content); i += 2 {
+				if current.Content[i].Value == key {
+					line = current.Content[i].Line
+					next = current.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			for _, item := range current.Content {
+				if item.Value == key {
+					line = item.Line
+					next = item
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		current = next
+	}
+	return line
+}
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkProfile checks the values defined by a node or profile itself.
+func (v *validator) checkProfile(e entity, p *node.Profile) {
+	if p == nil {
+		return
+	}
+	for _, id := range p.Profiles {
+		if strings.HasPrefix(id, "~") {
+			continue
+		}
+		if _, ok := v.registry.NodeProfiles[id]; !ok {
+			v.report(Error, e, []string{"profiles", id}, "profile not found: %s", id)
+		}
+	}
+	if p.ImageName != "" && v.images != nil && !v.images[p.ImageName] {
+		v.report(Error, e, []string{"image name"}, "image not found: %s", p.ImageName)
+	}
+	for _, overlays := range []struct {
+		key   string
+		names []string
+	}{{"system overlay", p.SystemOverlay}, {"runtime overlay", p.RuntimeOverlay}} {
+		for _, name := range overlays.names {
+			if strings.HasPrefix(name, "~") {
+				continue
+			}
+			if !v.overlays[name] {
+				v.report(Error, e, []string{overlays.key, name}, "overlay not found: %s", name)
+			}
+		}
+	}
+	attributes := config.Get().Attributes
+	for _, name := range sortedKeys(p.Attributes) {
+		value := p.Attributes[name]
+		if value == "" {
+			continue
+		}
+		if attr, ok := attributes[name]; !ok || attr == nil {
+			v.report(Error, e, []string{"attributes", name}, "unknown attribute: %s", name)
+		} else if _, err := attr.Parse(value); err != nil {
+			v.report(Error, e, []string{"attributes", name}, "invalid value for attribute %s: %s", name, err)
+		}
+	}
+	for _, name := range sortedKeys(p.NetDevs) {
+		netdev := p.NetDevs[name]
+		if netdev == nil {
+			continue
+		}
+		path := func(key string) []string { return []string{"network devices", name, key} }
+		if netdev.Hwaddr != "" {
+			if _, err := net.ParseMAC(netdev.Hwaddr); err != nil {
+				v.report(Error, e, path("hwaddr"), "invalid hardware address for %s: %s", name, netdev.Hwaddr)
+			}
+		}
+		if netdev.MTU != "" {
+			if mtu, err := strconv.Atoi(netdev.MTU); err != nil || mtu < 68 || mtu > 65535 {
+				v.report(Error, e, path("mtu"), "invalid MTU for %s: %s", name, netdev.MTU)
+			}
+		}
+		if netdev.PrefixLen6 != "" {
+			if prefixLen, err := strconv.Atoi(netdev.PrefixLen6); err != nil || prefixLen < 0 || prefixLen > 128 {
+				v.report(Error, e, path("prefixlen6"), "invalid IPv6 prefix length for %s: %s", name, netdev.PrefixLen6)
+			}
+		}
+	}
+	for _, diskName := range sortedKeys(p.Disks) {
+		disk := p.Disks[diskName]
+		if disk == nil {
+			continue
+		}
+		for _, partName := range sortedKeys(disk.Partitions) {
+			part := disk.Partitions[partName]
+			if part == nil {
+				continue
+			}
+			path := func(key string) []string { return []string{"disks", diskName, "partitions", partName, key} }
+			for _, guid := range [][2]string{{"type_guid", part.TypeGuid}, {"guid", part.Guid}} {
+				if guid[1] != "" && !guidPattern.MatchString(guid[1]) {
+					v.report(Error, e, path(guid[0]), "invalid GUID for partition %s: %s", partName, guid[1])
+				}
+			}
+			for _, value := range [][2]string{{"number", part.Number}, {"size_mib", part.SizeMiB}, {"start_mib", part.StartMiB}} {
+				if _, err := strconv.ParseUint(value[1], 10, 64); value[1] != "" && err != nil {
+					v.report(Error, e, path(value[0]), "invalid %s for partition %s: %s", value[0], partName, value[1])
+				}
+			}
+		}
+	}
+}
+
+// checkNodes checks the nodes with the values inherited from their profiles.
+func (v *validator) checkNodes() error {
+	nodes, err := v.registry.FindAllNodes()
+	if err != nil {
+		return err
+	}
+	sources := make(map[string]func(field string) entity)
+	hwaddrs := make(map[string][]string)
+	for _, n := range nodes {
+		id := n.Id()
+		_, fields, err := v.registry.MergeNode(id)
+		if err != nil {
+			return err
+		}
+		sources[id] = func(field string) entity {
+			if source := fields.Source(field); source != "" && source != "SUPERSEDED" && !strings.Contains(source, ",") {
+				return entity{kind: "profile", id: source}
+			}
+			return entity{kind: "node", id: id}
+		}
+
+		if n.PrimaryNetDev != "" {
+			if _, ok := n.NetDevs[n.PrimaryNetDev]; !ok {
+				v.report(Warning, sources[id]("PrimaryNetDev"), []string{"primary network"}, "primary network device not found: %s", n.PrimaryNetDev)
+			}
+		}
+		if n.ImageName != "" && v.images[n.ImageName] && kernel.FromNode(&n) == nil {
+			if n.Kernel != nil && n.Kernel.Version != "" {
+				v.report(Error, sources[id]("Kernel.Version"), []string{"kernel", "version"}, "kernel %s not found in image %s", n.Kernel.Version, n.ImageName)
+			} else {
+				v.report(Warning, entity{kind: "node", id: id}, nil, "no kernel found in image %s", n.ImageName)
+			}
+		}
+		for _, name := range sortedKeys(n.NetDevs) {
+			if netdev := n.NetDevs[name]; netdev != nil && netdev.Hwaddr != "" {
+				hwaddr := strings.ToLower(netdev.Hwaddr)
+				hwaddrs[hwaddr] = append(hwaddrs[hwaddr], id+"\x00"+name)
+			}
+		}
+	}
+
+	for _, hwaddr := range sortedKeys(hwaddrs) {
+		holders := hwaddrs[hwaddr]
+		if len(holders) < 2 {
+			continue
+		}
+		for _, holder := range holders {
+			id, name, _ := strings.Cut(holder, "\x00")
+			field := "NetDevs[" + name + "].Hwaddr"
+			v.report(Error, sources[id](field), fieldPath(field), "duplicate hardware address %s for node %s", hwaddr, id)
+		}
+	}
+
+	assignments, err := ipam.Assignments(v.registry)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range ipam.Conflicts(assignments) {
+		for _, assignment := range conflict.Assignments {
+			v.report(Error, sources[assignment.Node](assignment.Field), fieldPath(assignment.Field),
+				"duplicate IP address %s for node %s", conflict.IP, assignment.Node)
+		}
+	}
+	return nil
+}
+
+// fieldPath returns the path in nodes.conf of a field such as
+// NetDevs[default].Ipaddr.
+func fieldPath(field string) []string {
+	yamlKeys := map[string]string{
+		"NetDevs": "network devices",
+		"Ipmi":    "ipmi",
+		"Ipaddr":  "ipaddr",
+		"Ipaddr6": "ipaddr6",
+		"Hwaddr":  "hwaddr",
+	}
+	var path []string
+	for _, part := range strings.Split(field, ".") {
+		name, key, isMap := strings.Cut(strings.TrimSuffix(part, "]"), "[")
+		if yamlKey, ok := yamlKeys[name]; ok {
+			name = yamlKey
+		}
+		path = append(path, name)
+		if isMap {
+			path = append(path, key)
+		}
+	}
+	return path
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
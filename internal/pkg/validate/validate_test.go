@@ -0,0 +1,240 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Validate(t *testing.T) {
+	var tests = map[string]struct {
+		nodesConf string
+		problems  []string
+	}{
+		"valid": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    image name: rocky
+    system overlay:
+    - wwinit
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+        ipaddr: 10.0.0.1
+        mtu: "9000"
+`,
+			problems: nil,
+		},
+		"missing references": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    image name: sles
+nodes:
+  n1:
+    profiles:
+    - default
+    - gpu
+    runtime overlay:
+    - wwinit
+    - missing
+`,
+			problems: []string{
+				"4: error: profile default: image not found: sles",
+				"9: error: node n1: profile not found: gpu",
+				"12: error: node n1: overlay not found: missing",
+			},
+		},
+		"negated references": {
+			nodesConf: `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default
+    - ~gpu
+    system overlay:
+    - ~missing
+`,
+			problems: nil,
+		},
+		"malformed values": {
+			nodesConf: `
+nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:01
+        mtu: "9000000"
+        prefixlen6: "129"
+    disks:
+      /dev/vda:
+        partitions:
+          scratch:
+            number: "one"
+            type_guid: 0fc63daf-8483-4772-8e79
+`,
+			problems: []string{
+				"6: error: node n1: invalid hardware address for default: 00:00:00:00:01",
+				"7: error: node n1: invalid MTU for default: 9000000",
+				"8: error: node n1: invalid IPv6 prefix length for default: 129",
+				"13: error: node n1: invalid number for partition scratch: one",
+				"14: error: node n1: invalid GUID for partition scratch: 0fc63daf-8483-4772-8e79",
+			},
+		},
+		"duplicate addresses": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    ipmi:
+      ipaddr: 10.0.1.1
+nodes:
+  n1:
+    profiles:
+    - default
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+        ipaddr: 10.0.0.1
+  n2:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:01
+        ipaddr: 10.0.0.1
+  n3:
+    profiles:
+    - default
+`,
+			problems: []string{
+				"5: error: profile default: duplicate IP address 10.0.1.1 for node n1",
+				"5: error: profile default: duplicate IP address 10.0.1.1 for node n3",
+				"12: error: node n1: duplicate hardware address 00:00:00:00:00:01 for node n1",
+				"13: error: node n1: duplicate IP address 10.0.0.1 for node n1",
+				"17: error: node n2: duplicate hardware address 00:00:00:00:00:01 for node n2",
+				"18: error: node n2: duplicate IP address 10.0.0.1 for node n2",
+			},
+		},
+		"attributes": {
+			nodesConf: `
+nodeprofiles:
+  default:
+    attributes:
+      rack: twelve
+nodes:
+  n1:
+    attributes:
+      rack: "12"
+      color: blue
+`,
+			problems: []string{
+				"5: error: profile default: invalid value for attribute rack: not an integer: twelve",
+				"10: error: node n1: unknown attribute: color",
+			},
+		},
+		"kernels": {
+			nodesConf: `
+nodes:
+  n1:
+    image name: rocky
+    kernel:
+      version: "5.14"
+  n2:
+    image name: rocky
+  n3:
+    image name: alma
+  n4:
+    image name: alma
+    primary network: missing
+`,
+			problems: []string{
+				"6: error: node n1: kernel 5.14 not found in image rocky",
+				"9: warning: node n3: no kernel found in image alma",
+				"11: warning: node n4: no kernel found in image alma",
+				"13: warning: node n4: primary network device not found: missing",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("/etc/warewulf/warewulf.conf", "attributes:\n  rack:\n    type: int\n")
+			env.Configure()
+			env.CreateFile("/var/lib/warewulf/chroots/rocky/rootfs/boot/vmlinuz-6.1.0")
+			env.MkdirAll("/var/lib/warewulf/chroots/alma/rootfs")
+			env.MkdirAll("/usr/share/warewulf/overlays/wwinit")
+			env.MkdirAll("/var/lib/warewulf/overlays")
+			env.WriteFile("/etc/warewulf/nodes.conf", tt.nodesConf)
+
+			problems, err := File(env.GetPath("/etc/warewulf/nodes.conf"))
+			assert.NoError(t, err)
+			var output []string
+			for _, problem := range problems {
+				output = append(output, strings.TrimPrefix(problem.String(), env.GetPath("/etc/warewulf/nodes.conf")+":"))
+			}
+			assert.Equal(t, tt.problems, output)
+		})
+	}
+}
+
+func Test_Validate_Fragments(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.MkdirAll("/var/lib/warewulf/overlays")
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles:
+    - default
+`)
+	env.WriteFile("/etc/warewulf/nodes.conf.d/rack1.conf", `
+nodes:
+  n2:
+    profiles:
+    - gpu
+`)
+
+	problems, err := File(env.GetPath("/etc/warewulf/nodes.conf"))
+	assert.NoError(t, err)
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, env.GetPath("/etc/warewulf/nodes.conf.d/rack1.conf")+":5: error: node n2: profile not found: gpu", problems[0].String())
+	}
+}
+
+func Test_Validate_ParseError(t *testing.T) {
+	_, err := Validate([]byte("nodes:\n  n1:\n    network devices:\n      default:\n        ipaddr: invalid\n"))
+	assert.Error(t, err)
+}
+
+func Test_Validate_ImagesUnavailable(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.MkdirAll("/var/lib/warewulf/overlays")
+	// a file in place of the chroots directory prevents listing images
+	env.WriteFile("/var/lib/warewulf/chroots", "")
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    image name: rocky
+nodes:
+  n1:
+    profiles:
+    - default
+`)
+
+	problems, err := File(env.GetPath("/etc/warewulf/nodes.conf"))
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
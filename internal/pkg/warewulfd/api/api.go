@@ -21,32 +21,54 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 	api.OpenAPISchema().SetDescription("This service provides an API to a Warewulf v4 server.")
 	api.OpenAPISchema().SetVersion(version.Version())
 
+	readOnly := RequireRole(auth, config.RoleReadOnly)
+	nodeOperator := RequireRole(auth, config.RoleNodeOperator)
+	admin := RequireRole(auth, config.RoleAdmin)
+
 	api.Route("/api/nodes", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(AuthMiddleware(auth, allowedNets))
+			r.Use(nodesETagMiddleware)
+
+			r.With(readOnly).Method(http.MethodGet, "/", nethttp.NewHandler(getNodes()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}", nethttp.NewHandler(getNodeByID()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/raw", nethttp.NewHandler(getRawNodeByID()))
+			r.With(admin).Method(http.MethodPut, "/{id}", nethttp.NewHandler(addNode()))
+			r.With(admin).Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteNode()))
+			r.With(nodeOperator).Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateNode()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/fields", nethttp.NewHandler(getNodeFields()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/explain", nethttp.NewHandler(getNodeExplanation()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/events", nethttp.NewHandler(getNodeEvents()))
+			r.With(nodeOperator).Method(http.MethodPost, "/overlays/build", nethttp.NewHandler(buildAllOverlays(), nethttp.SuccessStatus(http.StatusAccepted)))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/power", nethttp.NewHandler(getNodePower()))
+			r.With(nodeOperator).Method(http.MethodPost, "/{id}/power", nethttp.NewHandler(setNodePower()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/sensors", nethttp.NewHandler(getNodeSensors()))
+			r.With(nodeOperator).Method(http.MethodPost, "/{id}/overlays/build", nethttp.NewHandler(buildOverlays()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}/overlays", nethttp.NewHandler(getNodeOverlayInfo()))
+		})
+	})
+
+	// bulk power control is not below /api/nodes, where it would shadow a
+	// node named "power"
+	api.Route("/api/power", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getNodes()))
-			r.Method(http.MethodGet, "/{id}", nethttp.NewHandler(getNodeByID()))
-			r.Method(http.MethodGet, "/{id}/raw", nethttp.NewHandler(getRawNodeByID()))
-			r.Method(http.MethodPut, "/{id}", nethttp.NewHandler(addNode()))
-			r.Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteNode()))
-			r.Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateNode()))
-			r.Method(http.MethodGet, "/{id}/fields", nethttp.NewHandler(getNodeFields()))
-			r.Method(http.MethodPost, "/overlays/build", nethttp.NewHandler(buildAllOverlays()))
-			r.Method(http.MethodPost, "/{id}/overlays/build", nethttp.NewHandler(buildOverlays()))
-			r.Method(http.MethodGet, "/{id}/overlays", nethttp.NewHandler(getNodeOverlayInfo()))
+			r.With(readOnly).Method(http.MethodGet, "/", nethttp.NewHandler(getNodesPower()))
+			r.With(nodeOperator).Method(http.MethodPost, "/", nethttp.NewHandler(setNodesPower()))
 		})
 	})
 
 	api.Route("/api/profiles", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
+			r.Use(nodesETagMiddleware)
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getProfiles()))
-			r.Method(http.MethodGet, "/{id}", nethttp.NewHandler(getProfileByID()))
-			r.Method(http.MethodPut, "/{id}", nethttp.NewHandler(addProfile()))
-			r.Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateProfile()))
-			r.Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteProfile()))
+			r.With(readOnly).Method(http.MethodGet, "/", nethttp.NewHandler(getProfiles()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}", nethttp.NewHandler(getProfileByID()))
+			r.With(admin).Method(http.MethodPut, "/{id}", nethttp.NewHandler(addProfile()))
+			r.With(admin).Method(http.MethodPatch, "/{id}", nethttp.NewHandler(updateProfile()))
+			r.With(admin).Method(http.MethodDelete, "/{id}", nethttp.NewHandler(deleteProfile()))
 		})
 	})
 
@@ -54,12 +76,12 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getImages()))
-			r.Method(http.MethodGet, "/{name}", nethttp.NewHandler(getImageByName()))
-			r.Method(http.MethodPost, "/{name}/import", nethttp.NewHandler(importImage()))
-			r.Method(http.MethodPatch, "/{name}", nethttp.NewHandler(updateImage()))
-			r.Method(http.MethodPost, "/{name}/build", nethttp.NewHandler(buildImage()))
-			r.Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteImage()))
+			r.With(readOnly).Method(http.MethodGet, "/", nethttp.NewHandler(getImages()))
+			r.With(readOnly).Method(http.MethodGet, "/{name}", nethttp.NewHandler(getImageByName()))
+			r.With(admin).Method(http.MethodPost, "/{name}/import", nethttp.NewHandler(importImage(), nethttp.SuccessStatus(http.StatusAccepted)))
+			r.With(admin).Method(http.MethodPatch, "/{name}", nethttp.NewHandler(updateImage()))
+			r.With(admin).Method(http.MethodPost, "/{name}/build", nethttp.NewHandler(buildImage(), nethttp.SuccessStatus(http.StatusAccepted)))
+			r.With(admin).Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteImage()))
 		})
 	})
 
@@ -67,13 +89,23 @@ func Handler(auth *config.Authentication, allowedNets []net.IPNet) *web.Service
 		r.Group(func(r chi.Router) {
 			r.Use(AuthMiddleware(auth, allowedNets))
 
-			r.Method(http.MethodGet, "/", nethttp.NewHandler(getOverlays()))
-			r.Method(http.MethodGet, "/{name}", nethttp.NewHandler(getOverlayByName()))
-			r.Method(http.MethodGet, "/{name}/file", nethttp.NewHandler(getOverlayFile()))
-			r.Method(http.MethodPut, "/{name}", nethttp.NewHandler(createOverlay()))
-			r.Method(http.MethodPut, "/{name}/file", nethttp.NewHandler(addOverlayFile()))
-			r.Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteOverlay()))
-			r.Method(http.MethodDelete, "/{name}/file", nethttp.NewHandler(deleteOverlayFile()))
+			r.With(readOnly).Method(http.MethodGet, "/", nethttp.NewHandler(getOverlays()))
+			r.With(readOnly).Method(http.MethodGet, "/{name}", nethttp.NewHandler(getOverlayByName()))
+			r.With(readOnly).Method(http.MethodGet, "/{name}/file", nethttp.NewHandler(getOverlayFile()))
+			r.With(admin).Method(http.MethodPut, "/{name}", nethttp.NewHandler(createOverlay()))
+			r.With(admin).Method(http.MethodPut, "/{name}/file", nethttp.NewHandler(addOverlayFile()))
+			r.With(admin).Method(http.MethodDelete, "/{name}", nethttp.NewHandler(deleteOverlay()))
+			r.With(admin).Method(http.MethodDelete, "/{name}/file", nethttp.NewHandler(deleteOverlayFile()))
+		})
+	})
+
+	api.Route("/api/jobs", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(AuthMiddleware(auth, allowedNets))
+
+			r.With(readOnly).Method(http.MethodGet, "/", nethttp.NewHandler(getJobs()))
+			r.With(readOnly).Method(http.MethodGet, "/{id}", nethttp.NewHandler(getJobByID()))
+			r.With(nodeOperator).Method(http.MethodDelete, "/{id}", nethttp.NewHandler(cancelJob()))
 		})
 	})
 
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"context"
+
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+)
+
+// auditLog records a change made through the API, attributing it to the
+// authenticated user and client address of the request.
+func auditLog(ctx context.Context, entry audit.Entry) {
+	if user := UserFromContext(ctx); user != nil {
+		entry.User = user.Name
+	}
+	if source, ok := ctx.Value(sourceContextKey).(string); ok {
+		entry.Source = source
+	}
+	audit.Log(entry)
+}
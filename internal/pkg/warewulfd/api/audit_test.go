@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func TestAuditLog(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    comment: old
+  n2: {}
+`)
+
+	auth := config.NewAuthentication()
+	assert.NoError(t, auth.ParseFromRaw([]byte(`
+users:
+- name: admin
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+`)))
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(auth, allowedNets))
+	defer srv.Close()
+
+	request := func(method, path, body string, status int) []byte {
+		req, err := http.NewRequest(method, srv.URL+path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.SetBasicAuth("admin", "admin")
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, status, resp.StatusCode)
+		return data
+	}
+	request(http.MethodGet, "/api/nodes", "", http.StatusOK)
+	request(http.MethodPatch, "/api/nodes/n1", `{"node": {"comment": "new"}}`, http.StatusOK)
+	waitForJob(t, request(http.MethodPost, "/api/nodes/overlays/build", "", http.StatusAccepted), "buildAllOverlays")
+	request(http.MethodDelete, "/api/nodes/n1", "", http.StatusOK)
+
+	entries, err := audit.Query(audit.Filter{Entity: audit.EntityNode, ID: "n1"})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, "admin", entries[0].User)
+		assert.Equal(t, "127.0.0.1", entries[0].Source)
+		assert.Equal(t, "update", entries[0].Operation)
+		assert.Equal(t, []node.Change{{Path: "comment", Before: `"old"`, After: `"new"`}}, entries[0].Changes)
+		assert.Equal(t, "build-overlays", entries[1].Operation)
+		assert.Equal(t, "delete", entries[2].Operation)
+	}
+
+	// building the overlays of all nodes is recorded for each node
+	entries, err = audit.Query(audit.Filter{Entity: audit.EntityNode, ID: "n2"})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "build-overlays", entries[0].Operation)
+	}
+}
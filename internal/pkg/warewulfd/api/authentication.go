@@ -1,14 +1,42 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
+type contextKey string
+
+const (
+	userContextKey   contextKey = "user"
+	sourceContextKey contextKey = "source"
+)
+
+// UserFromContext returns the authenticated API user for a request, or nil
+// if authentication is not configured.
+func UserFromContext(ctx context.Context) *config.User {
+	if user, ok := ctx.Value(userContextKey).(*config.User); ok {
+		return user
+	}
+	return nil
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
 func AuthMiddleware(auth *config.Authentication, allowedNets []net.IPNet) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,23 +58,49 @@ func AuthMiddleware(auth *config.Authentication, allowedNets []net.IPNet) func(h
 					http.Error(w, "Forbidden", http.StatusForbidden)
 					return
 				}
+				r = r.WithContext(context.WithValue(r.Context(), sourceContextKey, ip.String()))
 			} else {
 				http.Error(w, fmt.Sprintf("Invalid remote address: %v", r.RemoteAddr), http.StatusForbidden)
 				return
 			}
 
 			if auth != nil {
-				username, password, ok := r.BasicAuth()
-				if !ok {
+				var user *config.User
+				var err error
+				if secret, ok := bearerToken(r); ok {
+					user, err = auth.AuthenticateToken(secret)
+				} else if username, password, ok := r.BasicAuth(); ok {
+					user, err = auth.Authenticate(username, password)
+				} else {
+					err = config.UnauthorizedError
+				}
+				if err != nil {
 					w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
 					http.Error(w, "Unauthorized", http.StatusUnauthorized)
 					return
 				}
+				r = r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-				_, err := auth.Authenticate(username, password)
-				if err != nil {
-					w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+// RequireRole denies requests from users whose role does not grant the
+// permissions of role. It must be used after AuthMiddleware. When
+// authentication is not configured (auth is nil) every request is allowed.
+func RequireRole(auth *config.Authentication, role config.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth != nil {
+				user := UserFromContext(r.Context())
+				if user == nil || !user.GetRole().Allows(role) {
+					name := ""
+					if user != nil {
+						name = user.Name
+					}
+					wwlog.Denied("API user %s requires role %s for %s %s", name, role, r.Method, r.URL.Path)
+					http.Error(w, "Forbidden", http.StatusForbidden)
 					return
 				}
 			}
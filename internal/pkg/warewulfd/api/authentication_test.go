@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func TestRoleAuthorization(t *testing.T) {
+	// every user's password is "admin"
+	authData := `
+users:
+- name: admin
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+- name: monitor
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+  role: read-only
+- name: operator
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+  role: node-operator
+`
+	tests := map[string]struct {
+		user   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		"read-only can list nodes":        {"monitor", http.MethodGet, "/api/nodes", "", http.StatusOK},
+		"read-only cannot update nodes":   {"monitor", http.MethodPatch, "/api/nodes/n1", `{"node": {"comment": "x"}}`, http.StatusForbidden},
+		"read-only cannot delete images":  {"monitor", http.MethodDelete, "/api/images/img", "", http.StatusForbidden},
+		"operator can update nodes":       {"operator", http.MethodPatch, "/api/nodes/n1", `{"node": {"comment": "x"}}`, http.StatusOK},
+		"operator cannot delete nodes":    {"operator", http.MethodDelete, "/api/nodes/n1", "", http.StatusForbidden},
+		"operator cannot update profiles": {"operator", http.MethodPatch, "/api/profiles/default", `{"profile": {"comment": "x"}}`, http.StatusForbidden},
+		"admin can delete nodes":          {"admin", http.MethodDelete, "/api/nodes/n1", "", http.StatusOK},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			warewulfd.SetNoDaemon()
+			env := testenv.New(t)
+			defer env.RemoveAll()
+			env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1: {}
+`)
+
+			auth := config.NewAuthentication()
+			assert.NoError(t, auth.ParseFromRaw([]byte(authData)))
+			allowedNets := []net.IPNet{
+				{
+					IP:   net.IPv4(127, 0, 0, 0),
+					Mask: net.CIDRMask(8, 32),
+				},
+			}
+			srv := httptest.NewServer(Handler(auth, allowedNets))
+			defer srv.Close()
+
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			req.SetBasicAuth(tt.user, "admin")
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			assert.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestInvalidRole(t *testing.T) {
+	auth := config.NewAuthentication()
+	err := auth.ParseFromRaw([]byte(`
+users:
+- name: admin
+  password hash: ""
+  role: superuser
+`))
+	assert.ErrorContains(t, err, "invalid role")
+}
+
+func TestBearerToken(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1: {}
+`)
+
+	tokens := new(config.APITokens)
+	adminSecret, err := tokens.Create("ci", config.RoleAdmin, time.Time{})
+	assert.NoError(t, err)
+	monitorSecret, err := tokens.Create("monitor", config.RoleReadOnly, time.Time{})
+	assert.NoError(t, err)
+	expiredSecret, err := tokens.Create("old", "", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	tokens.Tokens[2].Expires = time.Now().Add(-time.Hour).Unix()
+	tokensConf := env.GetPath("/etc/warewulf/tokens.conf")
+	assert.NoError(t, tokens.Write(tokensConf))
+
+	auth := config.NewAuthentication()
+	assert.NoError(t, auth.ParseFromRaw([]byte(`
+users:
+- name: admin
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+`)))
+	assert.NoError(t, auth.ReadTokens(tokensConf))
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(auth, allowedNets))
+	defer srv.Close()
+
+	request := func(method, path, token string) int {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		assert.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/nodes", adminSecret))
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/nodes", monitorSecret))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, "/api/nodes/n1", monitorSecret))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/nodes", expiredSecret))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/nodes", "wwt_invalid"))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/nodes", ""))
+
+	// revoked tokens are refused without restarting the server
+	assert.NoError(t, tokens.Revoke("monitor"))
+	assert.NoError(t, tokens.Write(tokensConf))
+	assert.NoError(t, os.Chtimes(tokensConf, time.Now(), time.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/nodes", monitorSecret))
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/nodes", adminSecret))
+}
+
+func TestCancelJobRole(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes: {}`)
+
+	// every user's password is "admin"
+	auth := config.NewAuthentication()
+	assert.NoError(t, auth.ParseFromRaw([]byte(`
+users:
+- name: admin
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+- name: operator
+  password hash: $2b$05$5QVWDpiWE7L4SDL9CYdi3O/l6HnbNOLoXgY2sa1bQQ7aSBKdSqvsC
+  role: node-operator
+`)))
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(auth, allowedNets))
+	defer srv.Close()
+
+	cancel := func(user string, job *jobs.Job) int {
+		req, err := http.NewRequest(http.MethodDelete, srv.URL+"/api/jobs/"+job.ID(), nil)
+		assert.NoError(t, err)
+		req.SetBasicAuth(user, "admin")
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	wait := func(ctx context.Context, job *jobs.Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	imageJob, err := jobs.Start("buildImage", "img", wait)
+	assert.NoError(t, err)
+	overlayJob, err := jobs.Start("buildAllOverlays", "all nodes", wait)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, cancel("operator", imageJob))
+	assert.Equal(t, jobs.Running, imageJob.Status().State)
+	assert.Equal(t, http.StatusOK, cancel("operator", overlayJob))
+	assert.Equal(t, http.StatusOK, cancel("admin", imageJob))
+	imageJob.Wait()
+	overlayJob.Wait()
+}
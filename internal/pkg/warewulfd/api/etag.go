@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/swaggest/usecase/status"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// nodesETag returns the entity tag of the node configuration: the quoted
+// hash of the node database.
+func nodesETag(registry *node.NodesYaml) string {
+	return `"` + registry.StringHash() + `"`
+}
+
+// nodesETagCache holds the entity tag of the node configuration last read
+// from nodesConf, which is reused until the files it was read from change.
+var nodesETagCache struct {
+	lock      sync.Mutex
+	nodesConf string
+	registry  *node.NodesYaml
+	etag      string
+}
+
+// currentNodesETag returns the entity tag of the node configuration. The
+// configuration is only parsed again if its files have changed since the tag
+// was last calculated.
+func currentNodesETag() (string, error) {
+	nodesETagCache.lock.Lock()
+	defer nodesETagCache.lock.Unlock()
+	nodesConf := config.Get().Paths.NodesConf()
+	if nodesETagCache.registry != nil && nodesETagCache.nodesConf == nodesConf && !nodesETagCache.registry.Changed() {
+		return nodesETagCache.etag, nil
+	}
+	registry, err := node.Load(nodesConf)
+	if err != nil {
+		return "", err
+	}
+	nodesETagCache.nodesConf = nodesConf
+	nodesETagCache.registry = &registry
+	nodesETagCache.etag = nodesETag(&registry)
+	return nodesETagCache.etag, nil
+}
+
+// nodesETagMiddleware sets the ETag header of GET requests to the entity tag
+// of the node configuration. The tag is calculated before the request is
+// handled, so it is never newer than the response.
+func nodesETagMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			if etag, err := currentNodesETag(); err != nil {
+				wwlog.Warn("Could not read node configuration for ETag: %s", err)
+			} else {
+				w.Header().Set("ETag", etag)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkIfMatch returns a FailedPrecondition error if an If-Match header was
+// given and none of its entity tags match the node configuration.
+func checkIfMatch(registry *node.NodesYaml, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+	etag := nodesETag(registry)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return nil
+		}
+	}
+	return status.Wrap(fmt.Errorf("node configuration has changed (current ETag %s)", etag), status.FailedPrecondition)
+}
+
+// persistRegistry writes the node configuration, returning an Aborted error
+// if it was changed by another process after it was read.
+func persistRegistry(registry *node.NodesYaml) error {
+	if err := registry.Persist(); err != nil {
+		if errors.Is(err, node.ErrConflict) {
+			return status.Wrap(err, status.Aborted)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func TestNodesETag(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles: [default]
+`)
+
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(nil, allowedNets))
+	defer srv.Close()
+
+	do := func(method, path, ifMatch, body string) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/api/nodes/n1", "", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	resp = do(http.MethodGet, "/api/profiles/", "", "")
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+
+	resp = do(http.MethodPatch, "/api/nodes/n1", etag, `{"node": {"comment": "first"}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the configuration has changed, so the original ETag no longer matches
+	resp = do(http.MethodPatch, "/api/nodes/n1", etag, `{"node": {"comment": "second"}}`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	resp = do(http.MethodDelete, "/api/profiles/default", etag, "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = do(http.MethodGet, "/api/nodes/", "", "")
+	newETag := resp.Header.Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+	resp = do(http.MethodPatch, "/api/nodes/n1", newETag, `{"node": {"comment": "second"}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodPatch, "/api/nodes/n1", "*", `{"node": {"comment": "third"}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, env.ReadFile("/etc/warewulf/nodes.conf"), "third")
+
+	// the ETag is reused until the file changes, including outside the API
+	resp = do(http.MethodGet, "/api/nodes/", "", "")
+	etag = resp.Header.Get("ETag")
+	resp = do(http.MethodGet, "/api/nodes/", "", "")
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default: {}
+nodes:
+  n1:
+    profiles: [default]
+    comment: fourth
+`)
+	resp = do(http.MethodGet, "/api/nodes/", "", "")
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	resp = do(http.MethodPatch, "/api/nodes/n1", resp.Header.Get("ETag"), `{"node": {"comment": "fifth"}}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
 	"github.com/warewulf/warewulf/internal/pkg/kernel"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
@@ -19,6 +21,7 @@ type Image struct {
 	Size      int      `json:"size"`
 	BuildTime int64    `json:"buildtime"`
 	Writable  bool     `json:"writable"`
+	Digest    string   `json:"digest"`
 }
 
 func NewImage(name string) *Image {
@@ -35,6 +38,9 @@ func NewImage(name string) *Image {
 		c.BuildTime = modTime.Unix()
 	}
 	c.Writable = image.IsWriteAble(name)
+	if manifest, err := image.ReadManifest(name); err == nil {
+		c.Digest = manifest.Digest()
+	}
 	return c
 }
 
@@ -87,29 +93,37 @@ func importImage() usecase.Interactor {
 		Password string `json:"password" description:"Password for the registry, if needed"`
 	}
 
-	u := usecase.NewInteractor(func(ctx context.Context, input importImageInput, output *Image) error {
+	u := usecase.NewInteractor(func(ctx context.Context, input importImageInput, output *JobResponse) error {
 		wwlog.Debug("api.importImage(Name:%v, URI:%v, NoHttps:%v, User:%v, Password:[redacted])",
 			input.Name, input.URI, input.NoHttps, input.User)
 		if !strings.HasPrefix(input.URI, "docker://") {
 			return status.Wrap(fmt.Errorf("missing docker:// prefix: %s", input.URI), status.InvalidArgument)
 		}
-
 		if !image.ValidName(input.Name) {
 			return status.Wrap(fmt.Errorf("name contains illegal characters: %s", input.Name), status.InvalidArgument)
 		}
-
-		if sctx, err := image.GetSystemContext(input.NoHttps, input.User, input.Password, ""); err != nil {
+		sctx, err := image.GetSystemContext(input.NoHttps, input.User, input.Password, "")
+		if err != nil {
 			return err
-		} else {
-			if err := image.ImportDocker(input.URI, input.Name, sctx); err != nil {
+		}
+
+		job, err := jobs.Start("importImage", input.Name, func(jobCtx context.Context, job *jobs.Job) error {
+			job.Logf("Importing image %s from %s", input.Name, input.URI)
+			if err := image.ImportDockerContext(jobCtx, input.URI, input.Name, sctx); err != nil {
 				return err
 			}
-			*output = *NewImage(input.Name)
+			auditLog(ctx, audit.Entry{Entity: audit.EntityImage, ID: input.Name, Operation: "import",
+				Changes: []node.Change{{Path: "uri", After: input.URI}}})
 			return nil
+		})
+		if err != nil {
+			return err
 		}
+		*output = newJobResponse(job)
+		return nil
 	})
 	u.SetTitle("Import an image")
-	u.SetDescription("Import an OS image from an OCI registry")
+	u.SetDescription("Start a background job to import an OS image from an OCI registry, and return the job.")
 	u.SetTags("Image")
 
 	return u
@@ -138,7 +152,11 @@ func deleteImage() usecase.Interactor {
 			}
 		}
 
-		return image.Delete(input.Name)
+		if err := image.Delete(input.Name); err != nil {
+			return err
+		}
+		auditLog(ctx, audit.Entry{Entity: audit.EntityImage, ID: input.Name, Operation: "delete"})
+		return nil
 	})
 	u.SetTitle("Delete an image")
 	u.SetDescription("Delete an existing OS image")
@@ -161,6 +179,8 @@ func updateImage() usecase.Interactor {
 			if err := image.Rename(input.Name, input.NewName, input.Build); err != nil {
 				return err
 			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityImage, ID: input.Name, Operation: "rename",
+				Changes: []node.Change{{Path: "name", Before: input.Name, After: input.NewName}}})
 			name = input.NewName
 			warewulfd.Reload()
 		}
@@ -181,19 +201,30 @@ func buildImage() usecase.Interactor {
 		Force bool   `query:"force" default:"false" description:"Build the OS image even if it appears unnecessary, default:'false'"`
 	}
 
-	u := usecase.NewInteractor(func(ctx context.Context, input buildImageInput, output *Image) error {
+	u := usecase.NewInteractor(func(ctx context.Context, input buildImageInput, output *JobResponse) error {
 		wwlog.Debug("api.buildImage(Name:%v, Force:%v)", input.Name, input.Force)
+		if !image.ValidSource(input.Name) {
+			return status.Wrap(fmt.Errorf("image does not exist: %s", input.Name), status.NotFound)
+		}
 
-		if err := image.Build(input.Name, input.Force); err != nil {
+		job, err := jobs.Start("buildImage", input.Name, func(jobCtx context.Context, job *jobs.Job) error {
+			job.Logf("Building image %s", input.Name)
+			if err := image.BuildContext(jobCtx, input.Name, input.Force); err != nil {
+				return err
+			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityImage, ID: input.Name, Operation: "build"})
+			return nil
+		})
+		if err != nil {
 			return err
 		}
-
-		*output = *NewImage(input.Name)
+		*output = newJobResponse(job)
 		return nil
 	})
 	u.SetTitle("Build an image")
-	u.SetDescription("Build an OS image")
+	u.SetDescription("Start a background job to build an OS image, and return the job.")
 	u.SetTags("Image")
+	u.SetExpectedErrors(status.NotFound)
 
 	return u
 }
@@ -21,6 +21,7 @@ var imageTests = []struct {
 	initFiles         []string
 	request           func(serverURL string) (*http.Request, error)
 	response          string
+	job               string
 	status            int
 	resultFiles       []string
 	resultAbsentFiles []string
@@ -46,7 +47,7 @@ var imageTests = []struct {
 		request: func(serverURL string) (*http.Request, error) {
 			return http.NewRequest(http.MethodGet, serverURL+"/api/images", nil)
 		},
-		response:     `{"test-image": {"kernels":[], "size":0, "buildtime":0, "writable":true}}`,
+		response:     `{"test-image": {"kernels":[], "size":0, "buildtime":0, "writable":true, "digest":""}}`,
 		authenticate: true,
 	},
 	{
@@ -57,7 +58,7 @@ var imageTests = []struct {
 		request: func(serverURL string) (*http.Request, error) {
 			return http.NewRequest(http.MethodGet, serverURL+"/api/images/test-image", nil)
 		},
-		response:     `{"kernels":[], "size":0, "buildtime":0, "writable":true}`,
+		response:     `{"kernels":[], "size":0, "buildtime":0, "writable":true, "digest":""}`,
 		authenticate: true,
 	},
 	{
@@ -68,10 +69,12 @@ var imageTests = []struct {
 		request: func(serverURL string) (*http.Request, error) {
 			return http.NewRequest(http.MethodPost, serverURL+"/api/images/test-image/build?force=true&default=true", nil)
 		},
-		response: `{"kernels":[], "size":512, "buildtime":"<<PRESENCE>>", "writable":true}`,
+		job:    "buildImage",
+		status: http.StatusAccepted,
 		resultFiles: []string{
 			"/srv/warewulf/images/test-image.img",
 			"/srv/warewulf/images/test-image.img.gz",
+			"/srv/warewulf/images/test-image.img.manifest.json",
 		},
 		authenticate: true,
 	},
@@ -83,7 +86,7 @@ var imageTests = []struct {
 		request: func(serverURL string) (*http.Request, error) {
 			return http.NewRequest(http.MethodPatch, serverURL+"/api/images/test-image?build=true", bytes.NewBuffer([]byte(`{"name": "new-image"}`)))
 		},
-		response:     `{"kernels":[], "size":512, "buildtime":"<<PRESENCE>>", "writable":true}`,
+		response:     `{"kernels":[], "size":512, "buildtime":"<<PRESENCE>>", "writable":true, "digest":"<<PRESENCE>>"}`,
 		authenticate: true,
 	},
 	{
@@ -94,10 +97,11 @@ var imageTests = []struct {
 		request: func(serverURL string) (*http.Request, error) {
 			return http.NewRequest(http.MethodDelete, serverURL+"/api/images/new-image", nil)
 		},
-		response: `{"kernels":[], "size":512, "buildtime":"<<PRESENCE>>", "writable":true}`,
+		response: `{"kernels":[], "size":512, "buildtime":"<<PRESENCE>>", "writable":true, "digest":"<<PRESENCE>>"}`,
 		resultAbsentFiles: []string{
 			"/var/lib/warewulf/chroots/new-image",
 			"/srv/warewulf/images/new-image.img",
+			"/srv/warewulf/images/new-image.img.manifest.json",
 			"/srv/warewulf/images/new-image.img.gz",
 		},
 		authenticate: true,
@@ -155,7 +159,9 @@ users:
 			assert.NoError(t, err)
 			assert.NoError(t, resp.Body.Close())
 
-			if expectedStatus == http.StatusUnauthorized {
+			if tt.job != "" {
+				waitForJob(t, body, tt.job)
+			} else if expectedStatus == http.StatusUnauthorized {
 				// For plain text responses like Unauthorized
 				assert.Equal(t, tt.response, string(body))
 			} else {
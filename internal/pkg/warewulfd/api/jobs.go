@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+
+	"github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// JobResponse is returned by requests that start a background job.
+type JobResponse struct {
+	Location string `header:"Location" json:"-" description:"URL of the job"`
+	jobs.Status
+}
+
+func newJobResponse(job *jobs.Job) JobResponse {
+	return JobResponse{
+		Location: "/api/jobs/" + job.ID(),
+		Status:   job.Status(),
+	}
+}
+
+// jobCancelRoles maps job types to the role required to cancel them, which
+// is the role required to start them. Other jobs may be cancelled by node
+// operators.
+var jobCancelRoles = map[string]config.Role{
+	"importImage": config.RoleAdmin,
+	"buildImage":  config.RoleAdmin,
+}
+
+func getJobs() usecase.Interactor {
+	u := usecase.NewInteractor(func(ctx context.Context, _ struct{}, output *[]jobs.Status) error {
+		wwlog.Debug("api.getJobs()")
+		statuses, err := jobs.List()
+		if err != nil {
+			return err
+		}
+		*output = statuses
+		return nil
+	})
+	u.SetTitle("Get jobs")
+	u.SetDescription("Get all running and recently finished background jobs.")
+	u.SetTags("Job")
+	return u
+}
+
+func getJobByID() usecase.Interactor {
+	type getJobByIDInput struct {
+		ID string `path:"id" required:"true" description:"ID of job to get"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getJobByIDInput, output *jobs.Status) error {
+		wwlog.Debug("api.getJobByID(ID:%v)", input.ID)
+		if job, ok := jobs.Get(input.ID); ok {
+			*output = job.Status()
+			return nil
+		}
+		jobStatus, err := jobs.Read(input.ID)
+		if err != nil {
+			return status.Wrap(err, status.NotFound)
+		}
+		*output = jobStatus
+		return nil
+	})
+	u.SetTitle("Get a job")
+	u.SetDescription("Get the state, progress, log, and error of a background job.")
+	u.SetTags("Job")
+	u.SetExpectedErrors(status.NotFound)
+	return u
+}
+
+func cancelJob() usecase.Interactor {
+	type cancelJobInput struct {
+		ID string `path:"id" required:"true" description:"ID of job to cancel"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input cancelJobInput, output *jobs.Status) error {
+		wwlog.Debug("api.cancelJob(ID:%v)", input.ID)
+		job, ok := jobs.Get(input.ID)
+		if !ok {
+			return status.Wrap(fmt.Errorf("job not found: %s", input.ID), status.NotFound)
+		}
+		jobType := job.Status().Type
+		if role, ok := jobCancelRoles[jobType]; ok {
+			if user := UserFromContext(ctx); user != nil && !user.GetRole().Allows(role) {
+				wwlog.Denied("API user %s requires role %s to cancel %s job %s", user.Name, role, jobType, input.ID)
+				return status.Wrap(fmt.Errorf("role %s required to cancel %s jobs", role, jobType), status.PermissionDenied)
+			}
+		}
+		if !job.Cancel() {
+			return status.Wrap(fmt.Errorf("job has already finished: %s", input.ID), status.FailedPrecondition)
+		}
+		*output = job.Status()
+		return nil
+	})
+	u.SetTitle("Cancel a job")
+	u.SetDescription("Request that a running background job stop. The job is in the cancelling state until it has stopped.")
+	u.SetTags("Job")
+	u.SetExpectedErrors(status.NotFound, status.PermissionDenied, status.FailedPrecondition)
+	return u
+}
+
+// buildOverlaysInBatches builds overlays for nodes a batch at a time,
+// recording progress after each batch and stopping if ctx is cancelled.
+func buildOverlaysInBatches(ctx context.Context, job *jobs.Job, nodes []node.Node) error {
+	batchSize := runtime.NumCPU()
+	var failed []string
+	job.SetProgress(0, len(nodes))
+	for start := 0; start < len(nodes); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch := nodes[start:min(start+batchSize, len(nodes))]
+		ids := make([]string, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].Id()
+		}
+		if err := overlay.BuildAllOverlays(batch, nodes, batchSize); err != nil {
+			job.Logf("Failed to build overlays for %s: %s", hostlist.Compress(ids), err)
+			failed = append(failed, ids...)
+		} else {
+			job.Logf("Built overlays for %s", hostlist.Compress(ids))
+		}
+		job.SetProgress(start+len(batch), len(nodes))
+	}
+	if len(failed) > 0 {
+		return errors.New("could not build overlays for " + hostlist.Compress(failed))
+	}
+	return nil
+}
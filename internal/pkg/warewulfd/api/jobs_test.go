@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func TestJobsAPI(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes: {}`)
+
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(nil, allowedNets))
+	defer srv.Close()
+
+	do := func(method, path string) (*http.Response, jobs.Status) {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		var status jobs.Status
+		_ = json.Unmarshal(body, &status)
+		return resp, status
+	}
+
+	t.Run("build overlays in the background", func(t *testing.T) {
+		resp, status := do(http.MethodPost, "/api/nodes/overlays/build")
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "/api/jobs/"+status.ID, resp.Header.Get("Location"))
+		assert.Equal(t, "buildAllOverlays", status.Type)
+
+		job, ok := jobs.Get(status.ID)
+		assert.True(t, ok)
+		job.Wait()
+
+		resp, status = do(http.MethodGet, "/api/jobs/"+status.ID)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, jobs.Succeeded, status.State)
+		assert.NotEmpty(t, status.Log)
+
+		resp, _ = do(http.MethodDelete, "/api/jobs/"+status.ID)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	t.Run("build unknown image in the background", func(t *testing.T) {
+		resp, _ := do(http.MethodPost, "/api/images/none/build")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("cancel job", func(t *testing.T) {
+		job, err := jobs.Start("test", "target", func(ctx context.Context, job *jobs.Job) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.NoError(t, err)
+
+		resp, status := do(http.MethodDelete, "/api/jobs/"+job.ID())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, []jobs.State{jobs.Cancelling, jobs.Cancelled}, status.State)
+
+		job.Wait()
+		resp, status = do(http.MethodGet, "/api/jobs/"+job.ID())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, jobs.Cancelled, status.State)
+	})
+
+	t.Run("list jobs", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/jobs/", nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var statuses []jobs.Status
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&statuses))
+		assert.Len(t, statuses, 2)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		resp, _ := do(http.MethodGet, "/api/jobs/0123456789abcdef")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// waitForJob waits for the job described by a 202 response body to finish,
+// and checks that it had type jobType and succeeded.
+func waitForJob(t *testing.T, body []byte, jobType string) {
+	var status jobs.Status
+	assert.NoError(t, json.Unmarshal(body, &status))
+	assert.Equal(t, jobType, status.Type)
+	job, ok := jobs.Get(status.ID)
+	if assert.True(t, ok) {
+		job.Wait()
+		assert.Equal(t, jobs.Succeeded, job.Status().State, job.Status().Error)
+	}
+}
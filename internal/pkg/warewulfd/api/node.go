@@ -2,16 +2,19 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
-	"sort"
 	"time"
 
 	"dario.cat/mergo"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/ipam"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
@@ -168,11 +171,63 @@ func getNodeFields() usecase.Interactor {
 	return u
 }
 
+func getNodeExplanation() usecase.Interactor {
+	type getNodeExplanationInput struct {
+		ID    string `path:"id" required:"true" description:"ID of node to explain"`
+		Field string `query:"field" description:"Only explain this field and the fields nested in it"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodeExplanationInput, output *node.Explanation) error {
+		wwlog.Debug("api.getNodeExplanation(ID:%v, Field:%v)", input.ID, input.Field)
+		if registry, err := node.New(); err != nil {
+			return err
+		} else {
+			if explanation, err := registry.Explain(input.ID); errors.Is(err, node.ErrNotFound) {
+				return status.Wrap(fmt.Errorf("node not found: %v", input.ID), status.NotFound)
+			} else if err != nil {
+				return err
+			} else {
+				if input.Field != "" {
+					explanation.FilterFields(input.Field)
+				}
+				*output = explanation
+				return nil
+			}
+		}
+	})
+	u.SetTitle("Explain a node")
+	u.SetDescription("Get the profile graph of a node and the provenance of each of its fields: the node or profile that sets it, overridden values, and negated list entries.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound)
+	return u
+}
+
+func getNodeEvents() usecase.Interactor {
+	type getNodeEventsInput struct {
+		ID string `path:"id" required:"true" description:"ID of node from which to retrieve provisioning events"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodeEventsInput, output *[]warewulfd.NodeEvent) error {
+		wwlog.Debug("api.getNodeEvents(ID:%v)", input.ID)
+		if !warewulfd.NodeExists(input.ID) {
+			return status.Wrap(fmt.Errorf("node not found: %v", input.ID), status.NotFound)
+		}
+		*output = warewulfd.NodeEvents(input.ID)
+		return nil
+	})
+	u.SetTitle("Get node provisioning events")
+	u.SetDescription("Get the recent provisioning events of a node, oldest first.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound)
+	return u
+}
+
 func addNode() usecase.Interactor {
 	type addNodeInput struct {
 		ID          string    `path:"id" required:"true" description:"ID of node to be added"`
 		Node        node.Node `json:"node" required:"true" description:"Field values in JSON format for added node"`
 		IfNoneMatch string    `header:"If-None-Match" description:"Set to '*' to indicate that the node should only be created if it does not already exist"`
+		IfMatch     string    `header:"If-Match" description:"ETag of the node configuration; the request fails if the configuration has since changed"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input addNodeInput, output *node.Node) error {
@@ -180,6 +235,9 @@ func addNode() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
+			if err := checkIfMatch(&registry, input.IfMatch); err != nil {
+				return err
+			}
 			if input.IfNoneMatch == "*" {
 				if _, ok := registry.Nodes[input.ID]; ok {
 					return status.Wrap(fmt.Errorf("node '%s' already exists", input.ID), status.InvalidArgument)
@@ -203,25 +261,47 @@ func addNode() usecase.Interactor {
 					return status.Wrap(fmt.Errorf("overlay '%s' does not exist", overlay_), status.InvalidArgument)
 				}
 			}
+			if err := input.Node.ValidateAttributes(); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+			before := node.NewNode(input.ID)
+			if existing, ok := registry.Nodes[input.ID]; ok {
+				before = *existing.Clone()
+			}
+			if err := input.Node.UpdateState(before.State); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
 			registry.Nodes[input.ID] = &input.Node
-			if err := registry.Persist(); err != nil {
+			if _, err := ipam.Allocate(&registry, []string{input.ID}); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+			if conflicts, err := ipam.NodeConflicts(&registry, []string{input.ID}); err != nil {
+				return err
+			} else if len(conflicts) > 0 {
+				return status.Wrap(fmt.Errorf("IP address %s is already assigned", conflicts[0].IP), status.InvalidArgument)
+			}
+			changes := node.Diff(&before, &input.Node)
+			if err := persistRegistry(&registry); err != nil {
 				return err
 			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: input.ID, Operation: "add", Changes: changes})
 			warewulfd.Reload()
 			*output = *(registry.Nodes[input.ID])
 			return nil
 		}
 	})
 	u.SetTitle("Add a node")
-	u.SetDescription("Add a new node.")
+	u.SetDescription("Add a new node. Addresses are allocated from the address pools referenced by the node's network devices and IPMI interface.")
 	u.SetTags("Node")
+	u.SetExpectedErrors(status.InvalidArgument, status.FailedPrecondition, status.Aborted)
 
 	return u
 }
 
 func deleteNode() usecase.Interactor {
 	type deleteNodeInput struct {
-		ID string `path:"id" required:"true" description:"ID of node to delete"`
+		ID      string `path:"id" required:"true" description:"ID of node to delete"`
+		IfMatch string `header:"If-Match" description:"ETag of the node configuration; the request fails if the configuration has since changed"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input deleteNodeInput, output *node.Node) error {
@@ -229,15 +309,19 @@ func deleteNode() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
+			if err := checkIfMatch(&registry, input.IfMatch); err != nil {
+				return err
+			}
 			if node, ok := registry.Nodes[input.ID]; ok {
 				*output = *node
 			}
 			if err := registry.DelNode(input.ID); err != nil {
 				return err
 			}
-			if err := registry.Persist(); err != nil {
+			if err := persistRegistry(&registry); err != nil {
 				return err
 			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: input.ID, Operation: "delete"})
 			warewulfd.Reload()
 			return nil
 		}
@@ -245,14 +329,16 @@ func deleteNode() usecase.Interactor {
 	u.SetTitle("Delete a node")
 	u.SetDescription("Delete an existing node.")
 	u.SetTags("Node")
+	u.SetExpectedErrors(status.FailedPrecondition, status.Aborted)
 
 	return u
 }
 
 func updateNode() usecase.Interactor {
 	type updateNodeInput struct {
-		ID   string    `path:"id" description:"ID of node to update"`
-		Node node.Node `json:"node" required:"true" description:"Field values in JSON format to update on node"`
+		ID      string    `path:"id" description:"ID of node to update"`
+		Node    node.Node `json:"node" required:"true" description:"Field values in JSON format to update on node"`
+		IfMatch string    `header:"If-Match" description:"ETag of the node configuration; the request fails if the configuration has since changed"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input updateNodeInput, output *node.Node) error {
@@ -260,6 +346,9 @@ func updateNode() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
+			if err := checkIfMatch(&registry, input.IfMatch); err != nil {
+				return err
+			}
 			for _, profile := range input.Node.Profiles {
 				if _, ok := registry.NodeProfiles[profile]; !ok {
 					return status.Wrap(fmt.Errorf("profile '%s' does not exist", profile), status.InvalidArgument)
@@ -278,15 +367,26 @@ func updateNode() usecase.Interactor {
 					return status.Wrap(fmt.Errorf("overlay '%s' does not exist", overlay_), status.InvalidArgument)
 				}
 			}
+			if err := input.Node.ValidateAttributes(); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
 			if nodePtr, err := registry.GetNodeOnlyPtr(input.ID); err != nil {
 				return status.Wrap(err, status.NotFound)
 			} else {
+				before := nodePtr.Clone()
 				if err := mergo.MergeWithOverwrite(nodePtr, &input.Node); err != nil {
 					return err
 				}
-				if err := registry.Persist(); err != nil {
+				if err := nodePtr.UpdateState(before.State); err != nil {
+					return status.Wrap(err, status.InvalidArgument)
+				}
+				after := nodePtr.Clone()
+				before.Flatten()
+				after.Flatten()
+				if err := persistRegistry(&registry); err != nil {
 					return err
 				}
+				auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: input.ID, Operation: "update", Changes: node.Diff(before, after)})
 				warewulfd.Reload()
 				*output = *nodePtr
 				return nil
@@ -296,34 +396,41 @@ func updateNode() usecase.Interactor {
 	u.SetTitle("Update a node")
 	u.SetDescription("Update an existing node.")
 	u.SetTags("Node")
+	u.SetExpectedErrors(status.FailedPrecondition, status.Aborted)
 
 	return u
 }
 
 func buildAllOverlays() usecase.Interactor {
-	u := usecase.NewInteractor(func(ctx context.Context, _ struct{}, output *[]string) error {
+	u := usecase.NewInteractor(func(ctx context.Context, _ struct{}, output *JobResponse) error {
 		wwlog.Debug("api.buildAllOverlays()")
-		if registry, err := node.New(); err != nil {
+		registry, err := node.New()
+		if err != nil {
 			return err
-		} else {
-			if nodes, err := registry.FindAllNodes(); err != nil {
-				return fmt.Errorf("could not get node list: %w", err)
-			} else {
-				ret := make([]string, len(nodes))
-				for i := range nodes {
-					ret[i] = nodes[i].Id()
-				}
-				sort.Strings(ret)
-				if err := overlay.BuildAllOverlays(nodes, nodes, runtime.NumCPU()); err != nil {
-					return err
-				}
-				*output = ret
-				return nil
+		}
+		nodes, err := registry.FindAllNodes()
+		if err != nil {
+			return fmt.Errorf("could not get node list: %w", err)
+		}
+
+		job, err := jobs.Start("buildAllOverlays", "all nodes", func(jobCtx context.Context, job *jobs.Job) error {
+			if err := buildOverlaysInBatches(jobCtx, job, nodes); err != nil {
+				return err
 			}
+			// one entry per node, so that the build is found by node
+			for i := range nodes {
+				auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: nodes[i].Id(), Operation: "build-overlays"})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
+		*output = newJobResponse(job)
+		return nil
 	})
 	u.SetTitle("Build all overlay images")
-	u.SetDescription("Build system and runtime overlay images for all nodes.")
+	u.SetDescription("Start a background job to build system and runtime overlay images for all nodes, and return the job.")
 	u.SetTags("Node")
 
 	return u
@@ -349,6 +456,7 @@ func buildOverlays() usecase.Interactor {
 				if err := overlay.BuildAllOverlays([]node.Node{node_}, nodes, runtime.NumCPU()); err != nil {
 					return err
 				}
+				auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: input.ID, Operation: "build-overlays"})
 				*output = input.ID
 				return nil
 			}
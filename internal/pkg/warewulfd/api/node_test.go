@@ -19,6 +19,7 @@ var nodeTests = map[string]struct {
 	initFiles   []string
 	request     func(serverURL string) (*http.Request, error)
 	response    string
+	job         string
 	status      int
 	resultConf  string
 	resultFiles []string
@@ -241,6 +242,72 @@ nodes:
 		response: `{"profiles": ["default"], "kernel": {"version": "v1.0.0", "args": ["kernel-args"]}}`,
 	},
 
+	"get a node's fields with attributes": {
+		initConf: `
+nodeprofiles:
+  default:
+    attributes:
+      rack: "12"
+nodes:
+  n1:
+    profiles:
+      - default
+    attributes:
+      role: login
+`,
+		request: func(serverURL string) (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, serverURL+"/api/nodes/n1/fields", nil)
+		},
+		response: `[
+  {"Field": "Profiles", "Source": "", "Value": "default"},
+  {"Field": "Attributes[rack]", "Source": "default", "Value": "12"},
+  {"Field": "Attributes[role]", "Source": "", "Value": "login"}
+]`,
+	},
+
+	"explain a node's field": {
+		initConf: `
+nodeprofiles:
+  default:
+    system overlay:
+      - so1
+      - so2
+nodes:
+  n1:
+    profiles:
+      - default
+    system overlay:
+      - ~so2
+`,
+		request: func(serverURL string) (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, serverURL+"/api/nodes/n1/explain?field=SystemOverlay", nil)
+		},
+		response: `{
+  "node": "n1",
+  "file": "<<PRESENCE>>",
+  "profiles": [{"id": "default"}],
+  "order": ["default"],
+  "fields": [{
+    "field": "SystemOverlay",
+    "value": "so1",
+    "source": "default,n1",
+    "definitions": [{"source": "default", "value": "so1,so2"}, {"source": "n1", "value": "~so2"}],
+    "negated": ["so2"]
+  }]
+}`,
+	},
+
+	"explain a missing node": {
+		initConf: `
+nodes: {}
+`,
+		request: func(serverURL string) (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, serverURL+"/api/nodes/n1/explain", nil)
+		},
+		status:   http.StatusNotFound,
+		response: `{"status": "<<PRESENCE>>", "error": "<<PRESENCE>>"}`,
+	},
+
 	"get unbuilt overlay info for the node": {
 		initConf: `
 nodeprofiles: {}
@@ -359,7 +426,8 @@ nodes:
 		request: func(serverURL string) (*http.Request, error) {
 			return http.NewRequest(http.MethodPost, serverURL+"/api/nodes/overlays/build", nil)
 		},
-		response: `["n1", "n2"]`,
+		job:    "buildAllOverlays",
+		status: http.StatusAccepted,
 		resultFiles: []string{
 			"/srv/warewulf/overlays/n1/__SYSTEM__.img",
 			"/srv/warewulf/overlays/n1/__SYSTEM__.img.gz",
@@ -464,8 +532,12 @@ func TestNodeAPI(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NoError(t, resp.Body.Close())
 
-			ja := jsonassert.New(t)
-			ja.Assert(string(body), tt.response)
+			if tt.job != "" {
+				waitForJob(t, body, tt.job)
+			} else {
+				ja := jsonassert.New(t)
+				ja.Assert(string(body), tt.response)
+			}
 
 			if tt.resultConf != "" {
 				assert.YAMLEq(t, tt.resultConf, env.ReadFile("/etc/warewulf/nodes.conf"))
@@ -11,6 +11,7 @@ import (
 
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -256,6 +257,7 @@ func createOverlay() usecase.Interactor {
 		if err != nil {
 			return err
 		}
+		auditLog(ctx, audit.Entry{Entity: audit.EntityOverlay, ID: input.Name, Operation: "create"})
 		*output = *NewOverlayResponse(newOverlay.Name())
 		return nil
 	})
@@ -298,6 +300,7 @@ func deleteOverlay() usecase.Interactor {
 		if err := overlay_.Delete(input.Force); err != nil {
 			return status.Wrap(fmt.Errorf("failed to remove overlay: %v", err), status.Code(409))
 		}
+		auditLog(ctx, audit.Entry{Entity: audit.EntityOverlay, ID: input.Name, Operation: "delete"})
 		*output = *NewOverlayResponse(input.Name)
 		output.Site = overlay_.IsSiteOverlay()
 		return nil
@@ -331,6 +334,7 @@ func deleteOverlayFile() usecase.Interactor {
 				if err := overlay_.DeleteFile(relPath, input.Force, input.Cleanup); err != nil {
 					return fmt.Errorf("unable to delete overlay file %v: %v: %w", input.Name, relPath, err)
 				}
+				auditLog(ctx, audit.Entry{Entity: audit.EntityOverlay, ID: input.Name, Operation: "delete-file", File: relPath})
 			}
 		}
 		*output = *NewOverlayResponse(input.Name)
@@ -367,6 +371,7 @@ func addOverlayFile() usecase.Interactor {
 			if err := overlay_.AddFile(relPath, []byte(input.Content), true, input.IfNoneMatch != "*"); err != nil {
 				return fmt.Errorf("unable to add overlay file %v: %v: %w", input.Name, relPath, err)
 			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityOverlay, ID: input.Name, Operation: "add-file", File: relPath})
 			*output = *NewOverlayResponse(input.Name)
 			return nil
 		}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+
+	"github.com/warewulf/warewulf/internal/pkg/audit"
+	"github.com/warewulf/warewulf/internal/pkg/bmc"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// defaultBMCFanout is the default number of BMC commands run in parallel,
+// matching wwctl power.
+const defaultBMCFanout = 50
+
+// powerCommands maps power actions to BMC template commands.
+var powerCommands = map[string]string{
+	"status": "PowerStatus",
+	"on":     "PowerOn",
+	"off":    "PowerOff",
+	"cycle":  "PowerCycle",
+	"reset":  "PowerReset",
+	"soft":   "PowerSoft",
+}
+
+// BMCResult is the result of a BMC command for a single node.
+type BMCResult struct {
+	Node   string `json:"node"`
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newBMCResult(result bmc.NodeResult) BMCResult {
+	ret := BMCResult{Node: result.Node, Output: result.Output}
+	if result.Err != nil {
+		ret.Error = result.Err.Error()
+	}
+	return ret
+}
+
+// runBMC runs cmd for the nodes matching the hostlist patterns and returns
+// the per-node results.
+func runBMC(patterns []string, cmd string, fanout int) ([]BMCResult, error) {
+	registry, err := node.New()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := registry.FindAllNodes()
+	if err != nil {
+		return nil, fmt.Errorf("could not get node list: %w", err)
+	}
+	nodes = node.FilterNodeListByName(nodes, hostlist.Expand(patterns))
+	if len(nodes) == 0 {
+		return nil, status.Wrap(fmt.Errorf("no nodes found"), status.NotFound)
+	}
+	if fanout <= 0 {
+		fanout = defaultBMCFanout
+	}
+	results := []BMCResult{}
+	for _, result := range bmc.RunAll(nodes, cmd, fanout, false) {
+		if result.Err != nil {
+			wwlog.Error("%s: %s: %s", result.Node, cmd, result.Err)
+		}
+		results = append(results, newBMCResult(result))
+	}
+	return results, nil
+}
+
+func powerCommand(action string) (string, error) {
+	if cmd, ok := powerCommands[action]; ok {
+		return cmd, nil
+	}
+	return "", status.Wrap(fmt.Errorf("invalid power action: %s", action), status.InvalidArgument)
+}
+
+func getNodePower() usecase.Interactor {
+	type getNodePowerInput struct {
+		ID string `path:"id" required:"true" description:"ID of node to get power status for"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodePowerInput, output *BMCResult) error {
+		wwlog.Debug("api.getNodePower(ID:%v)", input.ID)
+		results, err := runBMC([]string{input.ID}, powerCommands["status"], 1)
+		if err != nil {
+			return err
+		}
+		*output = results[0]
+		return nil
+	})
+	u.SetTitle("Get node power status")
+	u.SetDescription("Get the power status of a node from its BMC.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound)
+
+	return u
+}
+
+func setNodePower() usecase.Interactor {
+	type setNodePowerInput struct {
+		ID     string `path:"id" required:"true" description:"ID of node to control power for"`
+		Action string `json:"action" required:"true" enum:"on,off,cycle,reset,soft" description:"Power action to perform"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input setNodePowerInput, output *BMCResult) error {
+		wwlog.Debug("api.setNodePower(ID:%v, Action:%v)", input.ID, input.Action)
+		cmd, err := powerCommand(input.Action)
+		if err != nil {
+			return err
+		}
+		results, err := runBMC([]string{input.ID}, cmd, 1)
+		if err != nil {
+			return err
+		}
+		auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: input.ID, Operation: "power " + input.Action})
+		*output = results[0]
+		return nil
+	})
+	u.SetTitle("Control node power")
+	u.SetDescription("Power a node on, off, or cycle, reset, or soft power it off, through its BMC.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound, status.InvalidArgument)
+
+	return u
+}
+
+func getNodeSensors() usecase.Interactor {
+	type getNodeSensorsInput struct {
+		ID   string `path:"id" required:"true" description:"ID of node to get sensor readings for"`
+		Full bool   `query:"full" default:"false" description:"Return the full sensor list rather than the SDR list, default:'false'"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodeSensorsInput, output *BMCResult) error {
+		wwlog.Debug("api.getNodeSensors(ID:%v, Full:%v)", input.ID, input.Full)
+		cmd := "SDRList"
+		if input.Full {
+			cmd = "SensorList"
+		}
+		results, err := runBMC([]string{input.ID}, cmd, 1)
+		if err != nil {
+			return err
+		}
+		*output = results[0]
+		return nil
+	})
+	u.SetTitle("Get node sensors")
+	u.SetDescription("Get sensor readings for a node from its BMC.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound)
+
+	return u
+}
+
+func getNodesPower() usecase.Interactor {
+	type getNodesPowerInput struct {
+		Nodes  []string `query:"nodes" required:"true" description:"Hostlist patterns of nodes to get power status for"`
+		Fanout int      `query:"fanout" default:"50" description:"Number of BMC commands to run in parallel, default:'50'"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input getNodesPowerInput, output *[]BMCResult) error {
+		wwlog.Debug("api.getNodesPower(Nodes:%v, Fanout:%v)", input.Nodes, input.Fanout)
+		results, err := runBMC(input.Nodes, powerCommands["status"], input.Fanout)
+		if err != nil {
+			return err
+		}
+		*output = results
+		return nil
+	})
+	u.SetTitle("Get power status for nodes")
+	u.SetDescription("Get the power status of the nodes matching a hostlist from their BMCs.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound)
+
+	return u
+}
+
+func setNodesPower() usecase.Interactor {
+	type setNodesPowerInput struct {
+		Nodes  []string `json:"nodes" required:"true" description:"Hostlist patterns of nodes to control power for"`
+		Action string   `json:"action" required:"true" enum:"status,on,off,cycle,reset,soft" description:"Power action to perform"`
+		Fanout int      `json:"fanout" default:"50" description:"Number of BMC commands to run in parallel, default:'50'"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, input setNodesPowerInput, output *[]BMCResult) error {
+		wwlog.Debug("api.setNodesPower(Nodes:%v, Action:%v, Fanout:%v)", input.Nodes, input.Action, input.Fanout)
+		cmd, err := powerCommand(input.Action)
+		if err != nil {
+			return err
+		}
+		results, err := runBMC(input.Nodes, cmd, input.Fanout)
+		if err != nil {
+			return err
+		}
+		if input.Action != "status" {
+			for _, result := range results {
+				auditLog(ctx, audit.Entry{Entity: audit.EntityNode, ID: result.Node, Operation: "power " + input.Action})
+			}
+		}
+		*output = results
+		return nil
+	})
+	u.SetTitle("Control power for nodes")
+	u.SetDescription("Perform a power action on the nodes matching a hostlist through their BMCs.")
+	u.SetTags("Node")
+	u.SetExpectedErrors(status.NotFound, status.InvalidArgument)
+
+	return u
+}
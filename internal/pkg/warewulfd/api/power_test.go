@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/assert"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
+)
+
+func TestPowerAPI(t *testing.T) {
+	warewulfd.SetNoDaemon()
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/usr/share/warewulf/bmc/echo.tmpl", `echo {{ .Cmd }} {{ .Ipaddr }}`)
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  default:
+    ipmi:
+      template: echo.tmpl
+nodes:
+  n1:
+    profiles: [default]
+    ipmi:
+      ipaddr: 10.10.10.1
+  n2:
+    profiles: [default]
+    ipmi:
+      ipaddr: 10.10.10.2
+  n3:
+    profiles: [default]
+  power:
+    profiles: [default]
+    ipmi:
+      ipaddr: 10.10.10.4
+`)
+
+	allowedNets := []net.IPNet{
+		{
+			IP:   net.IPv4(127, 0, 0, 0),
+			Mask: net.CIDRMask(8, 32),
+		},
+	}
+	srv := httptest.NewServer(Handler(nil, allowedNets))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+	}{
+		"node power status": {
+			method:   http.MethodGet,
+			path:     "/api/nodes/n1/power",
+			status:   http.StatusOK,
+			response: `{"node": "n1", "output": "PowerStatus 10.10.10.1"}`,
+		},
+		"node power on": {
+			method:   http.MethodPost,
+			path:     "/api/nodes/n2/power",
+			body:     `{"action": "on"}`,
+			status:   http.StatusOK,
+			response: `{"node": "n2", "output": "PowerOn 10.10.10.2"}`,
+		},
+		"invalid power action": {
+			method: http.MethodPost,
+			path:   "/api/nodes/n2/power",
+			body:   `{"action": "explode"}`,
+			status: http.StatusBadRequest,
+		},
+		"node named power": {
+			method: http.MethodGet,
+			path:   "/api/nodes/power",
+			status: http.StatusOK,
+		},
+		"power of node named power": {
+			method:   http.MethodGet,
+			path:     "/api/nodes/power/power",
+			status:   http.StatusOK,
+			response: `{"node": "power", "output": "PowerStatus 10.10.10.4"}`,
+		},
+		"unknown node": {
+			method: http.MethodGet,
+			path:   "/api/nodes/n9/power",
+			status: http.StatusNotFound,
+		},
+		"node sensors": {
+			method:   http.MethodGet,
+			path:     "/api/nodes/n1/sensors?full=true",
+			status:   http.StatusOK,
+			response: `{"node": "n1", "output": "SensorList 10.10.10.1"}`,
+		},
+		"batch power status": {
+			method: http.MethodGet,
+			path:   "/api/power?nodes=" + url.QueryEscape("n[1-3]"),
+			status: http.StatusOK,
+			response: `[
+				{"node": "n1", "output": "PowerStatus 10.10.10.1"},
+				{"node": "n2", "output": "PowerStatus 10.10.10.2"},
+				{"node": "n3", "output": "", "error": "no IPMI IP address"}]`,
+		},
+		"batch power cycle": {
+			method: http.MethodPost,
+			path:   "/api/power",
+			body:   `{"nodes": ["n1", "n2"], "action": "cycle", "fanout": 1}`,
+			status: http.StatusOK,
+			response: `[
+				{"node": "n1", "output": "PowerCycle 10.10.10.1"},
+				{"node": "n2", "output": "PowerCycle 10.10.10.2"}]`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.response != "" {
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				jsonassert.New(t).Assertf(string(body), "%s", tt.response)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"dario.cat/mergo"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
+	"github.com/warewulf/warewulf/internal/pkg/audit"
 	"github.com/warewulf/warewulf/internal/pkg/image"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
@@ -60,6 +61,7 @@ func addProfile() usecase.Interactor {
 		ID          string       `path:"id" required:"true" description:"ID of profile to add"`
 		Profile     node.Profile `json:"profile" required:"true" description:"Field values in JSON format for added profile"`
 		IfNoneMatch string       `header:"If-None-Match" description:"Set to '*' to indicate that the profile should only be created if it does not already exist"`
+		IfMatch     string       `header:"If-Match" description:"ETag of the node configuration; the request fails if the configuration has since changed"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input addProfileInput, output *node.Profile) error {
@@ -67,6 +69,9 @@ func addProfile() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
+			if err := checkIfMatch(&registry, input.IfMatch); err != nil {
+				return err
+			}
 			if input.IfNoneMatch == "*" {
 				if _, ok := registry.NodeProfiles[input.ID]; ok {
 					return status.Wrap(fmt.Errorf("profile '%s' already exists", input.ID), status.InvalidArgument)
@@ -90,10 +95,19 @@ func addProfile() usecase.Interactor {
 					return status.Wrap(fmt.Errorf("overlay '%s' does not exist", overlay_), status.InvalidArgument)
 				}
 			}
+			if err := input.Profile.ValidateAttributes(); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
+			before := node.NewProfile(input.ID)
+			if existing, ok := registry.NodeProfiles[input.ID]; ok {
+				before = *existing.Clone()
+			}
+			changes := node.Diff(&before, &input.Profile)
 			registry.NodeProfiles[input.ID] = &input.Profile
-			if err := registry.Persist(); err != nil {
+			if err := persistRegistry(&registry); err != nil {
 				return err
 			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityProfile, ID: input.ID, Operation: "add", Changes: changes})
 			warewulfd.Reload()
 			*output = *(registry.NodeProfiles[input.ID])
 			return nil
@@ -102,6 +116,7 @@ func addProfile() usecase.Interactor {
 	u.SetTitle("Add a profile")
 	u.SetDescription("Add a new node profile.")
 	u.SetTags("Profile")
+	u.SetExpectedErrors(status.FailedPrecondition, status.Aborted)
 
 	return u
 }
@@ -110,6 +125,7 @@ func updateProfile() usecase.Interactor {
 	type updateProfileInput struct {
 		ID      string       `path:"id" required:"true" description:"ID of profile to update"`
 		Profile node.Profile `json:"profile" required:"true" description:"Field values in JSON format to update on profile"`
+		IfMatch string       `header:"If-Match" description:"ETag of the node configuration; the request fails if the configuration has since changed"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input updateProfileInput, output *node.Profile) error {
@@ -117,6 +133,9 @@ func updateProfile() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
+			if err := checkIfMatch(&registry, input.IfMatch); err != nil {
+				return err
+			}
 			for _, profile := range input.Profile.Profiles {
 				if _, ok := registry.NodeProfiles[profile]; !ok {
 					return status.Wrap(fmt.Errorf("profile '%s' does not exist", profile), status.InvalidArgument)
@@ -135,15 +154,23 @@ func updateProfile() usecase.Interactor {
 					return status.Wrap(fmt.Errorf("overlay '%s' does not exist", overlay_), status.InvalidArgument)
 				}
 			}
+			if err := input.Profile.ValidateAttributes(); err != nil {
+				return status.Wrap(err, status.InvalidArgument)
+			}
 			if profilePtr, err := registry.GetProfilePtr(input.ID); err != nil {
 				return status.Wrap(err, status.NotFound)
 			} else {
+				before := profilePtr.Clone()
 				if err := mergo.MergeWithOverwrite(profilePtr, &input.Profile); err != nil {
 					return err
 				}
-				if err := registry.Persist(); err != nil {
+				after := profilePtr.Clone()
+				before.Flatten()
+				after.Flatten()
+				if err := persistRegistry(&registry); err != nil {
 					return err
 				}
+				auditLog(ctx, audit.Entry{Entity: audit.EntityProfile, ID: input.ID, Operation: "update", Changes: node.Diff(before, after)})
 				warewulfd.Reload()
 				*output = *profilePtr
 				return nil
@@ -153,13 +180,15 @@ func updateProfile() usecase.Interactor {
 	u.SetTitle("Update a profile")
 	u.SetDescription("Update an existing node profile.")
 	u.SetTags("Profile")
+	u.SetExpectedErrors(status.FailedPrecondition, status.Aborted)
 
 	return u
 }
 
 func deleteProfile() usecase.Interactor {
 	type deleteProfileInput struct {
-		ID string `path:"id" required:"true" description:"ID of profile to delete"`
+		ID      string `path:"id" required:"true" description:"ID of profile to delete"`
+		IfMatch string `header:"If-Match" description:"ETag of the node configuration; the request fails if the configuration has since changed"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, input deleteProfileInput, output *node.Profile) error {
@@ -167,6 +196,9 @@ func deleteProfile() usecase.Interactor {
 		if registry, err := node.New(); err != nil {
 			return err
 		} else {
+			if err := checkIfMatch(&registry, input.IfMatch); err != nil {
+				return err
+			}
 			if profile, ok := registry.NodeProfiles[input.ID]; ok {
 				*output = *profile
 			}
@@ -183,9 +215,10 @@ func deleteProfile() usecase.Interactor {
 				return err
 			}
 
-			if err := registry.Persist(); err != nil {
+			if err := persistRegistry(&registry); err != nil {
 				return err
 			}
+			auditLog(ctx, audit.Entry{Entity: audit.EntityProfile, ID: input.ID, Operation: "delete"})
 
 			warewulfd.Reload()
 			return nil
@@ -194,6 +227,7 @@ func deleteProfile() usecase.Interactor {
 	u.SetTitle("Delete a profile")
 	u.SetDescription("Delete an existing node profile.")
 	u.SetTags("Profile")
+	u.SetExpectedErrors(status.FailedPrecondition, status.Aborted)
 
 	return u
 }
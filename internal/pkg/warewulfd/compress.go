@@ -0,0 +1,72 @@
+package warewulfd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/warewulf/warewulf/internal/pkg/util"
+)
+
+// contentEncodings lists the content codings that may be negotiated with
+// Accept-Encoding, in the order they are preferred, and their compression
+// formats. zstd is preferred because it decompresses fastest.
+var contentEncodings = []struct {
+	coding string
+	format string
+}{
+	{"zstd", util.CompressZstd},
+	{"gzip", util.CompressGz},
+	{"xz", util.CompressXz},
+}
+
+/*
+negotiateEncoding returns the compression format and content coding of
+fileName to send for the given Accept-Encoding header: of the codings the
+client accepts with the highest quality, the one preferred by
+contentEncodings for which a compressed copy of fileName exists. If the
+client accepts none of them, the format is empty and fileName is sent
+uncompressed.
+*/
+func negotiateEncoding(acceptEncoding string, fileName string) (format string, coding string) {
+	if acceptEncoding == "" {
+		return "", ""
+	}
+	qualities := make(map[string]float64)
+	for _, entry := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(entry, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if name == "x-gzip" {
+			name = "gzip"
+		}
+		qualities[name] = quality
+	}
+
+	best := 0.0
+	for _, encoding := range contentEncodings {
+		quality, ok := qualities[encoding.coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if !ok || quality <= best {
+			continue
+		}
+		if !util.IsFile(util.CompressedFile(fileName, encoding.format)) {
+			continue
+		}
+		best = quality
+		format, coding = encoding.format, encoding.coding
+	}
+	return format, coding
+}
@@ -0,0 +1,109 @@
+package warewulfd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_negotiateEncoding(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/image.img", "image")
+	env.WriteFile("/image.img.gz", "gz")
+	env.WriteFile("/image.img.zst", "zstd")
+	image := env.GetPath("/image.img")
+
+	tests := map[string]struct {
+		acceptEncoding string
+		format         string
+		coding         string
+	}{
+		"none":              {"", "", ""},
+		"identity":          {"identity", "", ""},
+		"gzip":              {"gzip", "gz", "gzip"},
+		"x-gzip":            {"x-gzip", "gz", "gzip"},
+		"zstd preferred":    {"gzip, zstd", "zstd", "zstd"},
+		"quality":           {"zstd;q=0.5, gzip", "gz", "gzip"},
+		"refused":           {"zstd;q=0, gzip;q=0", "", ""},
+		"wildcard":          {"*", "zstd", "zstd"},
+		"not prepared":      {"xz", "", ""},
+		"fallback":          {"xz, gzip;q=0.1", "gz", "gzip"},
+		"case insensitive":  {"ZSTD", "zstd", "zstd"},
+		"unknown parameter": {"gzip;level=1", "gz", "gzip"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			format, coding := negotiateEncoding(tt.acceptEncoding, image)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, tt.coding, coding)
+		})
+	}
+}
+
+func Test_HandleSystemOverlay_Compression(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff`)
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	secureFalse := false
+	conf.Warewulf.SecureP = &secureFalse
+	imageDir := path.Join(conf.Paths.OverlayProvisiondir(), "n1")
+	assert.NoError(t, os.MkdirAll(imageDir, 0700))
+	assert.NoError(t, os.WriteFile(path.Join(imageDir, "__SYSTEM__.img"), []byte("system overlay"), 0600))
+	assert.NoError(t, os.WriteFile(path.Join(imageDir, "__SYSTEM__.img.gz"), []byte("gz system overlay"), 0600))
+	assert.NoError(t, os.WriteFile(path.Join(imageDir, "__SYSTEM__.img.zst"), []byte("zstd system overlay"), 0600))
+
+	tests := map[string]struct {
+		url            string
+		acceptEncoding string
+		status         int
+		body           string
+		encoding       string
+	}{
+		"uncompressed":         {"/system/00:00:00:ff:ff:ff", "", 200, "system overlay", ""},
+		"compress=gz":          {"/system/00:00:00:ff:ff:ff?compress=gz", "", 200, "gz system overlay", ""},
+		"compress=zstd":        {"/system/00:00:00:ff:ff:ff?compress=zstd", "zstd", 200, "zstd system overlay", ""},
+		"compress=xz":          {"/system/00:00:00:ff:ff:ff?compress=xz", "", 404, "", ""},
+		"compress=lz4":         {"/system/00:00:00:ff:ff:ff?compress=lz4", "", 404, "", ""},
+		"Accept-Encoding zstd": {"/system/00:00:00:ff:ff:ff", "gzip, zstd", 200, "zstd system overlay", "zstd"},
+		"Accept-Encoding gzip": {"/system/00:00:00:ff:ff:ff", "gzip", 200, "gz system overlay", "gzip"},
+		"Accept-Encoding xz":   {"/system/00:00:00:ff:ff:ff", "xz", 200, "system overlay", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req.RemoteAddr = "10.10.10.10:9873"
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			HandleSystemOverlay(w, req)
+			res := w.Result()
+			defer func() { _ = res.Body.Close() }()
+
+			data, err := io.ReadAll(res.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.status == 200 {
+				assert.Equal(t, tt.body, string(data))
+				assert.Equal(t, tt.encoding, res.Header.Get("Content-Encoding"))
+				assert.Equal(t, "Accept-Encoding", res.Header.Get("Vary"))
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package warewulfd
 import (
 	"fmt"
 	"net/http"
-	"path"
 
 	"github.com/warewulf/warewulf/internal/pkg/image"
 	"github.com/warewulf/warewulf/internal/pkg/util"
@@ -44,7 +43,7 @@ func HandleEfiBoot(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 	case "grub.cfg":
-		stageFile = path.Join(ctx.conf.Paths.Sysconfdir, "warewulf/grub/grub.cfg.ww")
+		stageFile = grubConfig(ctx)
 		tmplData = buildTemplateVars(ctx.conf, ctx.rinfo, ctx.remoteNode)
 		if !util.IsFile(stageFile) {
 			wwlog.Error("couldn't find grub.cfg template for %s", imageName)
@@ -24,6 +24,7 @@ var efiBootTests = []struct {
 	{"find grub", "/efiboot/grub.efi", "", 200, "10.10.10.10:9873"},
 	{"find grub: node with missing image returns 404", "/efiboot/grub.efi", "", 404, "10.10.10.11:9873"},
 	{"find grub.cfg", "/efiboot/grub.cfg", "dracut 10.10.0.1:9873", 200, "10.10.10.11:9873"},
+	{"find grub.cfg: node in maintenance", "/efiboot/grub.cfg", "n3 maintenance", 200, "10.10.10.12:9873"},
 }
 
 func Test_HandleEfiBoot(t *testing.T) {
@@ -46,11 +47,19 @@ nodes:
         hwaddr: 00:00:00:00:ff:ff
     image name: none
     tags:
-      GrubMenuEntry: dracut`)
+      GrubMenuEntry: dracut
+  n3:
+    state: maintenance
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:ff
+    profiles:
+    - default`)
 
 	env.WriteFile("/var/tmp/arpcache", `IP address       HW type     Flags       HW address            Mask     Device
 10.10.10.10    0x1         0x2         00:00:00:ff:ff:ff     *        dummy
-10.10.10.11    0x1         0x2         00:00:00:00:ff:ff     *        dummy`)
+10.10.10.11    0x1         0x2         00:00:00:00:ff:ff     *        dummy
+10.10.10.12    0x1         0x2         00:00:00:00:00:ff     *        dummy`)
 	prevArpFile := arpFile
 	arpFile = env.GetPath("/var/tmp/arpcache")
 	defer func() {
@@ -60,6 +69,7 @@ nodes:
 	env.CreateFile("/var/lib/warewulf/chroots/suse/rootfs/usr/lib64/efi/shim.efi")
 	env.CreateFile("/var/lib/warewulf/chroots/suse/rootfs/usr/share/efi/x86_64/grub.efi")
 	env.WriteFile("/etc/warewulf/grub/grub.cfg.ww", "{{ .Tags.GrubMenuEntry }} {{ .Authority }}")
+	env.WriteFile("/etc/warewulf/grub/maintenance.cfg.ww", "{{ .Id }} {{ .State }}")
 
 	dbErr := LoadNodeDB()
 	assert.NoError(t, dbErr)
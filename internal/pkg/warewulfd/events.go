@@ -0,0 +1,209 @@
+package warewulfd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// eventHistoryLen is the number of provisioning events retained for each
+// node. Older events are discarded as new events are recorded.
+const eventHistoryLen = 64
+
+// NodeEvent records a single provisioning request served to a node.
+type NodeEvent struct {
+	Time     int64  `json:"time"`
+	Stage    string `json:"stage"`
+	Sent     string `json:"sent,omitempty"`
+	Bytes    int64  `json:"bytes"`
+	Duration int64  `json:"duration ms"`
+	Status   int    `json:"status"`
+	Ipaddr   string `json:"ipaddr"`
+	Error    string `json:"error,omitempty"`
+}
+
+// eventRing is a fixed-size ring buffer of provisioning events.
+type eventRing struct {
+	events [eventHistoryLen]NodeEvent
+	next   int
+	count  int
+}
+
+func (r *eventRing) add(ev NodeEvent) {
+	r.events[r.next] = ev
+	r.next = (r.next + 1) % eventHistoryLen
+	if r.count < eventHistoryLen {
+		r.count++
+	}
+}
+
+// list returns the events in the ring in chronological order.
+func (r *eventRing) list() []NodeEvent {
+	ret := make([]NodeEvent, 0, r.count)
+	start := (r.next - r.count + eventHistoryLen) % eventHistoryLen
+	for i := 0; i < r.count; i++ {
+		ret = append(ret, r.events[(start+i)%eventHistoryLen])
+	}
+	return ret
+}
+
+var (
+	eventDB   = make(map[string]*eventRing)
+	eventLock = sync.RWMutex{}
+)
+
+func recordEvent(nodeID string, ev NodeEvent) {
+	if nodeID == "" {
+		return
+	}
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	ring, ok := eventDB[nodeID]
+	if !ok {
+		ring = new(eventRing)
+		eventDB[nodeID] = ring
+	}
+	ring.add(ev)
+}
+
+// pruneEvents discards the event history of nodes that are no longer in the
+// loaded node DB.
+func pruneEvents() {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	for nodeID := range eventDB {
+		if _, ok := db.yml.Nodes[nodeID]; !ok {
+			delete(eventDB, nodeID)
+		}
+	}
+}
+
+// NodeEvents returns the recorded provisioning events for a node, oldest
+// first.
+func NodeEvents(nodeID string) []NodeEvent {
+	eventLock.RLock()
+	defer eventLock.RUnlock()
+	if ring, ok := eventDB[nodeID]; ok {
+		return ring.list()
+	}
+	return []NodeEvent{}
+}
+
+// responseRecorder wraps an http.ResponseWriter to capture the status code
+// and number of bytes written for the event history.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// ReadFrom preserves the underlying writer's io.ReaderFrom (and thereby
+// sendfile) when serving large files.
+func (r *responseRecorder) ReadFrom(src io.Reader) (int64, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := r.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(r.ResponseWriter, src)
+	}
+	r.bytes += n
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// recordResponse records a provisioning event and request metrics for a
+// completed response.
+func recordResponse(ctx *requestContext, stageFile string, rec *responseRecorder) {
+	elapsed := time.Since(ctx.start)
+	ev := NodeEvent{
+		Time:     ctx.start.Unix(),
+		Stage:    ctx.rinfo.stage,
+		Bytes:    rec.bytes,
+		Duration: elapsed.Milliseconds(),
+		Status:   rec.status,
+		Ipaddr:   ctx.rinfo.ipaddr,
+	}
+	if stageFile != "" {
+		ev.Sent = path.Base(stageFile)
+	}
+	if ev.Status == 0 {
+		ev.Status = http.StatusOK
+	}
+	if ev.Status >= http.StatusBadRequest {
+		ev.Error = http.StatusText(ev.Status)
+	}
+	recordEvent(ctx.remoteNode.Id(), ev)
+	observeRequest(ev.Stage, ev.Status, ev.Bytes, elapsed)
+}
+
+type nodeHistory struct {
+	NodeStatus
+	Events []NodeEvent `json:"events"`
+}
+
+// HandleStatusHistory returns the current status and recorded provisioning
+// events for the node named in the request path (/status/{node}).
+func HandleStatusHistory(w http.ResponseWriter, req *http.Request) {
+	nodeID := strings.Trim(strings.TrimPrefix(req.URL.Path, "/status/"), "/")
+	if nodeID == "" {
+		HandleStatus(w, req)
+		return
+	}
+
+	dbLock.RLock()
+	status, ok := statusDB.Nodes[nodeID]
+	var history nodeHistory
+	if ok {
+		history.NodeStatus = *status
+	}
+	dbLock.RUnlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	history.Events = NodeEvents(nodeID)
+
+	ret, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		wwlog.ErrorExc(fmt.Errorf("could not marshal JSON data from event history: %w", err), "")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write(ret); err != nil {
+		wwlog.Warn("Could not send status history JSON: %s", err)
+	}
+}
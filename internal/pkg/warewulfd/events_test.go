@@ -0,0 +1,75 @@
+package warewulfd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_eventRing(t *testing.T) {
+	var ring eventRing
+	assert.Empty(t, ring.list())
+
+	for i := 0; i < eventHistoryLen+10; i++ {
+		ring.add(NodeEvent{Time: int64(i)})
+	}
+	events := ring.list()
+	assert.Len(t, events, eventHistoryLen)
+	assert.Equal(t, int64(10), events[0].Time)
+	assert.Equal(t, int64(eventHistoryLen+9), events[len(events)-1].Time)
+}
+
+func Test_HandleStatusHistory(t *testing.T) {
+	dbLock.Lock()
+	statusDB.Nodes = map[string]*NodeStatus{
+		"n1": {NodeName: "n1", Stage: "kernel", Lastseen: 1712345678},
+	}
+	dbLock.Unlock()
+	eventLock.Lock()
+	eventDB = make(map[string]*eventRing)
+	eventLock.Unlock()
+
+	recordEvent("n1", NodeEvent{Time: 1712345670, Stage: "ipxe", Sent: "default.ipxe", Bytes: 100, Status: 200})
+	recordEvent("n1", NodeEvent{Time: 1712345678, Stage: "kernel", Sent: "vmlinuz", Bytes: 1000, Status: 200})
+
+	t.Run("known node", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		HandleStatusHistory(w, httptest.NewRequest(http.MethodGet, "/status/n1", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var history nodeHistory
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		assert.Equal(t, "kernel", history.Stage)
+		assert.Len(t, history.Events, 2)
+		assert.Equal(t, "default.ipxe", history.Events[0].Sent)
+		assert.Equal(t, "vmlinuz", history.Events[1].Sent)
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		HandleStatusHistory(w, httptest.NewRequest(http.MethodGet, "/status/n2", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func Test_pruneEvents(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes:
+  n1: {}`)
+	eventLock.Lock()
+	eventDB = make(map[string]*eventRing)
+	eventLock.Unlock()
+	recordEvent("n1", NodeEvent{Time: 1712345670, Stage: "ipxe"})
+	recordEvent("n2", NodeEvent{Time: 1712345670, Stage: "ipxe"})
+
+	Reload()
+	assert.True(t, NodeExists("n1"))
+	assert.False(t, NodeExists("n2"))
+	assert.Len(t, NodeEvents("n1"), 1)
+	assert.Empty(t, NodeEvents("n2"))
+}
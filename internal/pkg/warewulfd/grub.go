@@ -20,7 +20,21 @@ func HandleGrub(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	stageFile := path.Join(ctx.conf.Paths.Sysconfdir, "warewulf/grub/grub.cfg.ww")
+	stageFile := grubConfig(ctx)
 	tmplData := buildTemplateVars(ctx.conf, ctx.rinfo, ctx.remoteNode)
 	sendResponse(w, req, stageFile, tmplData, ctx)
 }
+
+// MaintenanceGrub is the GRUB configuration template served to nodes that
+// are in maintenance or retired, in place of grub.cfg.ww.
+const MaintenanceGrub = "maintenance.cfg.ww"
+
+// grubConfig returns the GRUB configuration template for the requesting
+// node.
+func grubConfig(ctx *requestContext) string {
+	if ctx.remoteNode.InMaintenance() {
+		wwlog.Info("%s is %s; not provisioning", ctx.remoteNode.Id(), ctx.remoteNode.State)
+		return path.Join(ctx.conf.Paths.Sysconfdir, "warewulf/grub", MaintenanceGrub)
+	}
+	return path.Join(ctx.conf.Paths.Sysconfdir, "warewulf/grub/grub.cfg.ww")
+}
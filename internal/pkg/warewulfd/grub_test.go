@@ -21,6 +21,8 @@ var grubHandlerTests = []struct {
 }{
 	{"grub config for node with image", "/grub/00:00:00:ff:ff:ff", "", 200, "10.10.10.10:9873"},
 	{"grub config rendered with tag", "/grub/00:00:00:00:ff:ff", "dracut 10.10.0.1:9873", 200, "10.10.10.11:9873"},
+	{"grub config for a node in maintenance", "/grub/00:00:00:00:00:ff", "n3 maintenance: replacing DIMM", 200, "10.10.10.12:9873"},
+	{"grub config for a retired node", "/grub/00:00:00:00:00:fe", "n4 retired: ", 200, "10.10.10.13:9873"},
 }
 
 func Test_HandleGrub(t *testing.T) {
@@ -43,9 +45,25 @@ nodes:
         hwaddr: 00:00:00:00:ff:ff
     image name: none
     tags:
-      GrubMenuEntry: dracut`)
+      GrubMenuEntry: dracut
+  n3:
+    state: maintenance
+    state reason: replacing DIMM
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:ff
+    profiles:
+    - default
+  n4:
+    state: retired
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:fe
+    profiles:
+    - default`)
 
 	env.WriteFile("/etc/warewulf/grub/grub.cfg.ww", "{{ .Tags.GrubMenuEntry }} {{ .Authority }}")
+	env.WriteFile("/etc/warewulf/grub/maintenance.cfg.ww", "{{ .Id }} {{ .State }}: {{ .StateReason }}")
 
 	dbErr := LoadNodeDB()
 	assert.NoError(t, dbErr)
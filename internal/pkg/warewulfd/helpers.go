@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/Masterminds/sprig/v3"
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
@@ -68,15 +69,39 @@ func buildTemplateVars(conf *warewulfconf.WarewulfYaml, rinfo parsedRequest, rem
 		KernelVersion: kernelVersion,
 		Root:          remoteNode.Root,
 		NetDevs:       remoteNode.NetDevs,
+		State:         remoteNode.State,
+		StateReason:   consoleMessage(remoteNode.StateReason),
 		Tags:          remoteNode.Tags}
 }
 
+// consoleMessage makes message safe to print with echo from an iPXE script
+// or GRUB configuration: control characters, which could start a new
+// command, are replaced with spaces, and quotes, variable expansions, escapes,
+// and command separators are removed.
+func consoleMessage(message string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		if strings.ContainsRune("\"'`$\\&|;", r) {
+			return -1
+		}
+		return r
+	}, message)
+}
+
 // sendResponse handles the common response logic for provision handlers.
 // If tmplData is non-nil, it renders the stageFile as a template. Otherwise, it
-// sends stageFile as a raw file (with optional .gz compression).
+// sends stageFile as a raw file, compressed as requested by the compress
+// parameter or negotiated with Accept-Encoding. Every response is recorded in
+// the node's provisioning event history.
 func sendResponse(w http.ResponseWriter, req *http.Request, stageFile string, tmplData *templateVars, ctx *requestContext) {
 	wwlog.Serv("stage_file '%s'", stageFile)
 
+	rec := &responseRecorder{ResponseWriter: w}
+	w = rec
+	defer func() { recordResponse(ctx, stageFile, rec) }()
+
 	if util.IsFile(stageFile) {
 
 		if tmplData != nil {
@@ -118,8 +143,16 @@ func sendResponse(w http.ResponseWriter, req *http.Request, stageFile string, tm
 			wwlog.Info("send %s -> %s", stageFile, ctx.remoteNode.Id())
 
 		} else {
-			if ctx.rinfo.compress == "gz" {
-				stageFile += ".gz"
+			w.Header().Add("Vary", "Accept-Encoding")
+			if ctx.rinfo.compress != "" {
+				compressedFile := util.CompressedFile(stageFile, ctx.rinfo.compress)
+				if compressedFile == "" {
+					wwlog.Error("unsupported %s compressed version of file %s",
+						ctx.rinfo.compress, stageFile)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				stageFile = compressedFile
 
 				if !util.IsFile(stageFile) {
 					wwlog.Error("unprepared for compressed version of file %s",
@@ -127,10 +160,13 @@ func sendResponse(w http.ResponseWriter, req *http.Request, stageFile string, tm
 					w.WriteHeader(http.StatusNotFound)
 					return
 				}
-			} else if ctx.rinfo.compress != "" {
-				wwlog.Error("unsupported %s compressed version of file %s",
-					ctx.rinfo.compress, stageFile)
-				w.WriteHeader(http.StatusNotFound)
+			} else if format, coding := negotiateEncoding(req.Header.Get("Accept-Encoding"), stageFile); format != "" {
+				stageFile = util.CompressedFile(stageFile, format)
+				w.Header().Set("Content-Encoding", coding)
+				w.Header().Set("Content-Type", "application/octet-stream")
+			}
+			if digest, ok := ctx.digests[stageFile]; ok {
+				setDigestHeaders(w, digest)
 			}
 
 			err := sendFile(w, req, stageFile, ctx.remoteNode.Id())
@@ -1,7 +1,13 @@
 package warewulfd
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/warewulf/warewulf/internal/pkg/image"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
@@ -21,6 +27,7 @@ func HandleImage(w http.ResponseWriter, req *http.Request) {
 	} else {
 		if ctx.remoteNode.ImageName != "" {
 			stageFile = image.ImageFile(ctx.remoteNode.ImageName)
+			ctx.digests = imageDigests(ctx.remoteNode.ImageName)
 		} else {
 			wwlog.Warn("No image set for node %s", ctx.remoteNode.Id())
 		}
@@ -28,3 +35,58 @@ func HandleImage(w http.ResponseWriter, req *http.Request) {
 
 	sendResponse(w, req, stageFile, nil, ctx)
 }
+
+type cachedDigests struct {
+	modTime time.Time
+	size    int64
+	digests map[string]string
+}
+
+var (
+	imageDigestsMutex sync.Mutex
+	imageDigestsCache = make(map[string]cachedDigests)
+)
+
+// imageDigests returns the digests of the image files of an image, by path,
+// from its manifest. Manifests are cached, as they list every file in the
+// image, and are only read again when they change.
+func imageDigests(name string) map[string]string {
+	manifestFile := image.ManifestFile(name)
+	stat, err := os.Stat(manifestFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			wwlog.Warn("Could not read manifest of image %s: %s", name, err)
+		}
+		return nil
+	}
+
+	imageDigestsMutex.Lock()
+	defer imageDigestsMutex.Unlock()
+	if cached, ok := imageDigestsCache[manifestFile]; ok && cached.modTime.Equal(stat.ModTime()) && cached.size == stat.Size() {
+		return cached.digests
+	}
+	manifest, err := image.ReadManifest(name)
+	if err != nil {
+		wwlog.Warn("Could not read manifest of image %s: %s", name, err)
+		return nil
+	}
+	digests := manifest.Digests()
+	imageDigestsCache[manifestFile] = cachedDigests{
+		modTime: stat.ModTime(),
+		size:    stat.Size(),
+		digests: digests,
+	}
+	return digests
+}
+
+// setDigestHeaders sets the ETag and Digest headers of a response to the
+// SHA-256 digest of the file sent, given as "sha256:<hex>".
+func setDigestHeaders(w http.ResponseWriter, digest string) {
+	sum, err := hex.DecodeString(strings.TrimPrefix(digest, "sha256:"))
+	if !strings.HasPrefix(digest, "sha256:") || err != nil {
+		wwlog.Warn("Invalid digest: %s", digest)
+		return
+	}
+	w.Header().Set("ETag", `"`+digest+`"`)
+	w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+}
@@ -0,0 +1,115 @@
+package warewulfd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/image"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_HandleImage_Digest(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes:
+  n1:
+    image name: test-image
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff`)
+	env.WriteFile("/var/lib/warewulf/chroots/test-image/rootfs/etc/hosts", "127.0.0.1 localhost\n")
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	secureFalse := false
+	conf.Warewulf.SecureP = &secureFalse
+	conf.Warewulf.Compression = []string{"zstd"}
+	assert.NoError(t, image.Build("test-image", true))
+	manifest, err := image.ReadManifest("test-image")
+	assert.NoError(t, err)
+	digests := manifest.Digests()
+
+	request := func(acceptEncoding string, ifNoneMatch string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/image/00:00:00:ff:ff:ff", nil)
+		req.RemoteAddr = "10.10.10.10:9873"
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		HandleImage(w, req)
+		return w.Result()
+	}
+
+	for _, tt := range []struct {
+		acceptEncoding string
+		file           string
+	}{
+		{"", image.ImageFile("test-image")},
+		{"zstd", image.ImageFile("test-image") + ".zst"},
+	} {
+		res := request(tt.acceptEncoding, "")
+		data, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `"`+digests[tt.file]+`"`, res.Header.Get("ETag"))
+		sum := sha256.Sum256(data)
+		assert.Equal(t, "sha-256="+base64.StdEncoding.EncodeToString(sum[:]), res.Header.Get("Digest"))
+
+		// caches revalidate with the ETag
+		res = request(tt.acceptEncoding, res.Header.Get("ETag"))
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusNotModified, res.StatusCode)
+	}
+
+	// images built without a manifest are served without a digest
+	assert.NoError(t, os.Remove(image.ManifestFile("test-image")))
+	res := request("", "")
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get("ETag"))
+	assert.Empty(t, res.Header.Get("Digest"))
+}
+
+func Test_HandleImage_Maintenance(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes:
+  n1:
+    image name: test-image
+    state: maintenance
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff`)
+	env.WriteFile("/var/lib/warewulf/chroots/test-image/rootfs/etc/hosts", "127.0.0.1 localhost\n")
+	assert.NoError(t, LoadNodeDB())
+
+	conf := warewulfconf.Get()
+	secureFalse := false
+	conf.Warewulf.SecureP = &secureFalse
+	assert.NoError(t, image.Build("test-image", true))
+
+	for url, handler := range map[string]http.HandlerFunc{
+		"/image/00:00:00:ff:ff:ff":  HandleImage,
+		"/kernel/00:00:00:ff:ff:ff": HandleKernel,
+	} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.RemoteAddr = "10.10.10.10:9873"
+		w := httptest.NewRecorder()
+		handler(w, req)
+		res := w.Result()
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, url)
+	}
+}
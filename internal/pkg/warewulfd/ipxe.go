@@ -7,6 +7,10 @@ import (
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
+// MaintenanceIpxe is the iPXE template served to nodes that are in
+// maintenance or retired, in place of their configured template.
+const MaintenanceIpxe = "maintenance"
+
 // HandleIpxe handles iPXE boot script requests
 func HandleIpxe(w http.ResponseWriter, req *http.Request) {
 	ctx, err := initHandleRequest(w, req)
@@ -22,6 +26,10 @@ func HandleIpxe(w http.ResponseWriter, req *http.Request) {
 		stageFile = path.Join(ctx.conf.Paths.Sysconfdir, "/warewulf/ipxe/unconfigured.ipxe")
 		tmplData = &templateVars{
 			Hwaddr: ctx.rinfo.hwaddr}
+	} else if ctx.remoteNode.InMaintenance() {
+		wwlog.Info("%s is %s; not provisioning", ctx.remoteNode.Id(), ctx.remoteNode.State)
+		stageFile = path.Join(ctx.conf.Paths.Sysconfdir, "warewulf/ipxe", MaintenanceIpxe+".ipxe")
+		tmplData = buildTemplateVars(ctx.conf, ctx.rinfo, ctx.remoteNode)
 	} else {
 		template := ctx.remoteNode.Ipxe
 		if template == "" {
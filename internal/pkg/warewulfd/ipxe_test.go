@@ -33,6 +33,20 @@ var ipxeHandlerTests = []struct {
 		200,
 		"[fd00:10::10:12]:9873",
 	},
+	{
+		"ipxe for a node in maintenance",
+		"/ipxe/00:00:00:00:00:fe",
+		"n4 maintenance: replacing DIMM",
+		200,
+		"10.10.10.13:9873",
+	},
+	{
+		"ipxe for a node in maintenance with an unsafe reason",
+		"/ipxe/00:00:00:00:00:fd",
+		"n5 maintenance: replacing DIMM  chain http://example.com/{file}",
+		200,
+		"10.10.10.14:9873",
+	},
 }
 
 func Test_HandleIpxe(t *testing.T) {
@@ -47,9 +61,24 @@ func Test_HandleIpxe(t *testing.T) {
         device: net
     ipxe template: test
     kernel:
-      version: 1.1.1`)
+      version: 1.1.1
+  n4:
+    state: maintenance
+    state reason: replacing DIMM
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:fe
+    ipxe template: test
+  n5:
+    state: maintenance
+    state reason: "replacing \"DIMM\" ||\nchain http://example.com/${file}"
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:00:fd
+    ipxe template: test`)
 
 	env.WriteFile("/etc/warewulf/ipxe/test.ipxe", "{{.KernelVersion}}{{range $devname, $netdev := .NetDevs}}{{if and $netdev.Hwaddr $netdev.Device}} ifname={{$netdev.Device}}:{{$netdev.Hwaddr}} {{end}}{{end}} {{.Ipaddr}} {{.Ipaddr6}} {{.Authority}}")
+	env.WriteFile("/etc/warewulf/ipxe/maintenance.ipxe", "{{.Id}} {{.State}}: {{.StateReason}}")
 
 	dbErr := LoadNodeDB()
 	assert.NoError(t, dbErr)
@@ -0,0 +1,115 @@
+package warewulfd
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "warewulfd"
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "requests_total",
+		Help:      "Provisioning requests served, by stage and HTTP status code.",
+	}, []string{"stage", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve provisioning requests, by stage.",
+		Buckets:   []float64{.005, .01, .05, .1, .5, 1, 5, 10, 30, 60, 120, 300},
+	}, []string{"stage"})
+
+	sentBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sent_bytes_total",
+		Help:      "Bytes sent in response to provisioning requests, by stage.",
+	}, []string{"stage"})
+
+	assetKeyDenialsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "asset_key_denials_total",
+		Help:      "Provisioning requests denied because of an incorrect asset key, by stage.",
+	}, []string{"stage"})
+
+	unknownHwaddrTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "unknown_hwaddr_requests_total",
+		Help:      "Provisioning requests from hardware addresses not configured for any node, by stage.",
+	}, []string{"stage"})
+
+	overlayBuildDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "overlay_autobuild_duration_seconds",
+		Help:      "Time taken to automatically build node overlay images, by context.",
+		Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"context"})
+
+	nodesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "nodes"),
+		"Number of nodes by their most recent provisioning stage.",
+		[]string{"stage"}, nil)
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		sentBytesTotal,
+		assetKeyDenialsTotal,
+		unknownHwaddrTotal,
+		overlayBuildDuration,
+		nodeStageCollector{},
+	)
+}
+
+// nodeStageCollector reports the number of nodes in each provisioning stage
+// from the status database at scrape time.
+type nodeStageCollector struct{}
+
+func (nodeStageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nodesDesc
+}
+
+func (nodeStageCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[string]int)
+	dbLock.RLock()
+	for id, status := range statusDB.Nodes {
+		if id == "" {
+			// requests from unknown nodes
+			continue
+		}
+		stage := status.Stage
+		if stage == "" {
+			stage = "unknown"
+		}
+		counts[stage]++
+	}
+	dbLock.RUnlock()
+	for stage, count := range counts {
+		ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(count), stage)
+	}
+}
+
+// observeRequest records the metrics for a completed provisioning request.
+func observeRequest(stage string, status int, bytes int64, elapsed time.Duration) {
+	requestsTotal.WithLabelValues(stage, strconv.Itoa(status)).Inc()
+	requestDuration.WithLabelValues(stage).Observe(elapsed.Seconds())
+	sentBytesTotal.WithLabelValues(stage).Add(float64(bytes))
+}
+
+// MetricsHandler serves warewulfd metrics in the Prometheus exposition
+// format.
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
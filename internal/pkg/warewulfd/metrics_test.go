@@ -0,0 +1,61 @@
+package warewulfd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_Metrics(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes:
+  n1:
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:ff
+    asset key: secret
+  n2:
+    network devices:
+      default:
+        hwaddr: 00:00:00:00:ff:ff`)
+	env.WriteFile("/etc/warewulf/ipxe/default.ipxe", "#!ipxe")
+	env.WriteFile("/etc/warewulf/ipxe/unconfigured.ipxe", "#!ipxe")
+	conf := warewulfconf.Get()
+	secureFalse := false
+	conf.Warewulf.SecureP = &secureFalse
+	assert.NoError(t, LoadNodeDB())
+	assert.NoError(t, LoadNodeStatus())
+
+	for _, url := range []string{
+		"/ipxe/00:00:00:00:ff:ff",
+		"/ipxe/00:00:00:ff:ff:ff",
+		"/ipxe/00:00:00:ff:ff:ff?assetkey=secret",
+		"/ipxe/00:00:00:00:00:01",
+	} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.RemoteAddr = "10.10.10.10:9873"
+		HandleIpxe(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	assert.NoError(t, err)
+	metrics := string(body)
+
+	assert.Contains(t, metrics, `warewulfd_requests_total{code="200",stage="ipxe"}`)
+	assert.Contains(t, metrics, `warewulfd_request_duration_seconds_count{stage="ipxe"}`)
+	assert.Contains(t, metrics, `warewulfd_sent_bytes_total{stage="ipxe"}`)
+	assert.Contains(t, metrics, `warewulfd_asset_key_denials_total{stage="ipxe"} 1`)
+	assert.Contains(t, metrics, `warewulfd_unknown_hwaddr_requests_total{stage="ipxe"} 1`)
+	assert.Contains(t, metrics, `warewulfd_nodes{stage="ipxe"} 2`)
+}
@@ -1,12 +1,16 @@
 package warewulfd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/ipam"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/validate"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
 
@@ -14,6 +18,11 @@ type nodeDB struct {
 	lock     sync.RWMutex
 	NodeInfo map[string]string
 	yml      node.NodesYaml
+	// allNodes and digester are used to build overlays
+	allNodes []node.Node
+	digester *overlay.Digester
+	// loaded is set once a node DB has been loaded
+	loaded bool
 }
 
 var (
@@ -30,12 +39,13 @@ func LoadNodeDB() error {
 func loadNodeDB() (err error) {
 	TmpMap := make(map[string]string)
 
-	db.yml, err = node.New()
+	// the previously loaded DB is kept if the new one cannot be loaded
+	yml, err := node.New()
 	if err != nil {
 		return
 	}
 
-	nodes, err := db.yml.FindAllNodes()
+	nodes, err := yml.FindAllNodes()
 	if err != nil {
 		return err
 	}
@@ -50,7 +60,11 @@ func loadNodeDB() (err error) {
 		}
 	}
 
+	db.yml = yml
 	db.NodeInfo = TmpMap
+	db.allNodes = nodes
+	db.digester = overlay.NewDigester(nodes)
+	db.loaded = true
 	return nil
 }
 
@@ -67,6 +81,14 @@ func GetNode(hwaddr string) (node.Node, error) {
 	return db.yml.GetNode(nId)
 }
 
+// NodeExists reports whether a node is configured in the loaded node DB.
+func NodeExists(nodeID string) bool {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	_, ok := db.yml.Nodes[nodeID]
+	return ok
+}
+
 func GetOrDiscoverNode(hwaddr string, autobuildOverlays bool) (node.Node, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -103,8 +125,23 @@ func GetOrDiscoverNode(hwaddr string, autobuildOverlays bool) (node.Node, error)
 	if err != nil {
 		return nodeFound, err
 	}
-	err = db.yml.Persist()
+	allocations, err := ipam.Allocate(&db.yml, []string{nodeFound.Id()})
 	if err != nil {
+		return nodeFound, fmt.Errorf("%s (failed to allocate addresses) %w", hwaddr, err)
+	}
+	for _, allocation := range allocations {
+		wwlog.Info("%s: allocated %s from pool %s for %s", nodeFound.Id(), allocation.IP, allocation.Pool, allocation.Field)
+	}
+	err = db.yml.Persist()
+	if errors.Is(err, node.ErrConflict) {
+		// nodes.conf changed since it was loaded: reload it, rather than
+		// overwrite the change, so that a retry is discovered against the
+		// current configuration
+		if loadErr := loadNodeDB(); loadErr != nil {
+			wwlog.Error("%s (failed to reload configuration) %s", hwaddr, loadErr)
+		}
+		return nodeFound, fmt.Errorf("%s (node configuration changed during discovery) %w", hwaddr, err)
+	} else if err != nil {
 		return nodeFound, fmt.Errorf("%s (failed to persist node configuration) %w", hwaddr, err)
 	}
 	err = loadNodeDB()
@@ -126,12 +163,57 @@ func GetOrDiscoverNode(hwaddr string, autobuildOverlays bool) (node.Node, error)
 	return db.yml.GetNode(nodeFound.Id())
 }
 
+// Reload loads the node DB and node status again. If the node configuration
+// has errors, the previously loaded node DB is kept, so that nodes continue
+// to be provisioned from it until the configuration is fixed.
 func Reload() {
+	if !validateNodeDB() && nodeDBLoaded() {
+		wwlog.Error("Rejected reload of %s: keeping the previously loaded node configuration", warewulfconf.Get().Paths.NodesConf())
+		return
+	}
+
 	if err := LoadNodeDB(); err != nil {
 		wwlog.Error("Could not load node DB: %s", err)
+	} else {
+		pruneEvents()
 	}
 
 	if err := LoadNodeStatus(); err != nil {
 		wwlog.Error("Could not prepopulate node status DB: %s", err)
 	}
 }
+
+func nodeDBLoaded() bool {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return db.loaded
+}
+
+// nodeDBCurrent returns true if the loaded node DB is up to date with the
+// node configuration, e.g., because it was just reloaded after the change.
+func nodeDBCurrent() bool {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return db.loaded && !db.yml.Changed()
+}
+
+// validateNodeDB logs any problems with the node configuration, so that they
+// are reported when the configuration is reloaded rather than when a node
+// boots. It returns false if the configuration could not be validated or
+// has any problem of Error severity.
+func validateNodeDB() bool {
+	nodesConf := warewulfconf.Get().Paths.NodesConf()
+	problems, err := validate.File(nodesConf)
+	if err != nil {
+		wwlog.Error("Could not validate %s: %s", nodesConf, err)
+		return false
+	}
+	for _, problem := range problems {
+		if problem.Severity == validate.Error {
+			wwlog.Error("%s", problem)
+		} else {
+			wwlog.Warn("%s", problem)
+		}
+	}
+	return validate.Errors(problems) == 0
+}
@@ -55,12 +55,14 @@ nodes:
 				"/srv/warewulf/overlays/n1/__SYSTEM__.img.gz",
 				"/srv/warewulf/overlays/n1/__RUNTIME__.img",
 				"/srv/warewulf/overlays/n1/__RUNTIME__.img.gz",
+				"/srv/warewulf/overlays/n1/__RUNTIME__.img.zst",
 			},
 			removedFiles: []string{
 				"/srv/warewulf/overlays/n1/__SYSTEM__.img",
 				"/srv/warewulf/overlays/n1/__SYSTEM__.img.gz",
 				"/srv/warewulf/overlays/n1/__RUNTIME__.img",
 				"/srv/warewulf/overlays/n1/__RUNTIME__.img.gz",
+				"/srv/warewulf/overlays/n1/__RUNTIME__.img.zst",
 			},
 			nodesConf: `
 nodes:
@@ -191,3 +193,43 @@ nodes:
 		assert.Equal(t, string(before), string(after), "GetNode must not write to nodes.conf")
 	})
 }
+
+func Test_Reload_Rejected(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+`)
+	Reload()
+	assert.True(t, NodeExists("n1"))
+
+	// a configuration with errors is not loaded
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}
+  n2:
+    profiles:
+    - missing
+`)
+	Reload()
+	assert.True(t, NodeExists("n1"))
+	assert.False(t, NodeExists("n2"))
+
+	// nor is one that cannot be parsed
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes: [`)
+	Reload()
+	assert.True(t, NodeExists("n1"))
+
+	env.WriteFile("/etc/warewulf/nodes.conf", `
+nodeprofiles:
+  missing: {}
+nodes:
+  n1: {}
+  n2:
+    profiles:
+    - missing
+`)
+	Reload()
+	assert.True(t, NodeExists("n2"))
+}
@@ -42,7 +42,8 @@ func HandleSystemOverlay(w http.ResponseWriter, req *http.Request) {
 }
 
 // HandleRuntimeOverlay handles runtime overlay requests.
-// If TLS is enabled, returns 403 Forbidden for plain-HTTP requests.
+// If TLS is enabled, returns 403 Forbidden for plain-HTTP requests. Retired
+// nodes are also refused with 403 Forbidden; see refuseStage.
 func HandleRuntimeOverlay(w http.ResponseWriter, req *http.Request) {
 	if config.Get().Warewulf.TLSEnabled() && req.TLS == nil {
 		wwlog.Denied("runtime overlay requested over insecure connection")
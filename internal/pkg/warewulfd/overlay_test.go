@@ -22,6 +22,8 @@ var systemOverlayTests = []struct {
 	ip          string
 }{
 	{"system overlay", "/system/00:00:00:ff:ff:ff", "system overlay", 200, "10.10.10.10:9873"},
+	{"system overlay for a retired node", "/system/00:00:00:ff:ff:fe", "", 403, "10.10.10.11:9873"},
+	{"system overlay for a node in maintenance", "/system/00:00:00:ff:ff:fd", "", 403, "10.10.10.12:9873"},
 }
 
 var runtimeOverlayTests = []struct {
@@ -32,6 +34,8 @@ var runtimeOverlayTests = []struct {
 	ip          string
 }{
 	{"runtime overlay", "/runtime/00:00:00:ff:ff:ff", "runtime overlay", 200, "10.10.10.10:9873"},
+	{"runtime overlay for a retired node", "/runtime/00:00:00:ff:ff:fe", "", 403, "10.10.10.11:9873"},
+	{"runtime overlay for a node in maintenance", "/runtime/00:00:00:ff:ff:fd", "runtime overlay", 200, "10.10.10.12:9873"},
 }
 
 func Test_HandleSystemRuntimeOverlay(t *testing.T) {
@@ -47,6 +51,20 @@ nodes:
       default:
         hwaddr: 00:00:00:ff:ff:ff
     profiles:
+    - default
+  n2:
+    state: retired
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:fe
+    profiles:
+    - default
+  n3:
+    state: maintenance
+    network devices:
+      default:
+        hwaddr: 00:00:00:ff:ff:fd
+    profiles:
     - default`)
 
 	dbErr := LoadNodeDB()
@@ -59,6 +77,12 @@ nodes:
 	assert.NoError(t, os.MkdirAll(path.Join(conf.Paths.OverlayProvisiondir(), "n1"), 0700))
 	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n1", "__SYSTEM__.img"), []byte("system overlay"), 0600))
 	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n1", "__RUNTIME__.img"), []byte("runtime overlay"), 0600))
+	assert.NoError(t, os.MkdirAll(path.Join(conf.Paths.OverlayProvisiondir(), "n2"), 0700))
+	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n2", "__SYSTEM__.img"), []byte("system overlay"), 0600))
+	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n2", "__RUNTIME__.img"), []byte("runtime overlay"), 0600))
+	assert.NoError(t, os.MkdirAll(path.Join(conf.Paths.OverlayProvisiondir(), "n3"), 0700))
+	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n3", "__SYSTEM__.img"), []byte("system overlay"), 0600))
+	assert.NoError(t, os.WriteFile(path.Join(conf.Paths.OverlayProvisiondir(), "n3", "__RUNTIME__.img"), []byte("runtime overlay"), 0600))
 
 	for _, tt := range systemOverlayTests {
 		t.Run(tt.description, func(t *testing.T) {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/netip"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
@@ -17,12 +18,16 @@ type requestContext struct {
 	conf       *warewulfconf.WarewulfYaml
 	rinfo      parsedRequest
 	remoteNode node.Node
+	start      time.Time
+	// digests holds the digests of the files that may be sent, by path
+	digests map[string]string
 }
 
 // initHandleRequest performs common initial request parsing, security checks,
 // node lookup, and asset key validation. On error, it writes the HTTP error
 // response and returns a non-nil error so the caller can simply return.
 func initHandleRequest(w http.ResponseWriter, req *http.Request) (*requestContext, error) {
+	start := time.Now()
 	wwlog.Debug("Requested URL: %s", req.URL.String())
 	conf := warewulfconf.Get()
 	rinfo, err := parseRequest(req)
@@ -51,20 +56,66 @@ func initHandleRequest(w http.ResponseWriter, req *http.Request) (*requestContex
 		return nil, err
 	}
 
+	if !remoteNode.Valid() {
+		unknownHwaddrTotal.WithLabelValues(rinfo.stage).Inc()
+	}
+
 	if remoteNode.AssetKey != "" && remoteNode.AssetKey != rinfo.assetkey {
+		assetKeyDenialsTotal.WithLabelValues(rinfo.stage).Inc()
 		w.WriteHeader(http.StatusUnauthorized)
 		wwlog.Denied("incorrect asset key for node %s:", remoteNode.Id())
 		updateStatus(remoteNode.Id(), rinfo.stage, "BAD_ASSET", rinfo.ipaddr)
+		recordEvent(remoteNode.Id(), NodeEvent{
+			Time:     start.Unix(),
+			Stage:    rinfo.stage,
+			Sent:     "BAD_ASSET",
+			Duration: time.Since(start).Milliseconds(),
+			Status:   http.StatusUnauthorized,
+			Ipaddr:   rinfo.ipaddr,
+			Error:    "incorrect asset key",
+		})
 		return nil, fmt.Errorf("incorrect asset key")
 	}
 
+	if refuseStage(remoteNode, rinfo.stage) {
+		w.WriteHeader(http.StatusForbidden)
+		wwlog.Denied("%s requested by %s node %s", rinfo.stage, remoteNode.State, remoteNode.Id())
+		updateStatus(remoteNode.Id(), rinfo.stage, strings.ToUpper(remoteNode.State), rinfo.ipaddr)
+		recordEvent(remoteNode.Id(), NodeEvent{
+			Time:     start.Unix(),
+			Stage:    rinfo.stage,
+			Sent:     strings.ToUpper(remoteNode.State),
+			Duration: time.Since(start).Milliseconds(),
+			Status:   http.StatusForbidden,
+			Ipaddr:   rinfo.ipaddr,
+			Error:    "node is " + remoteNode.State,
+		})
+		return nil, fmt.Errorf("node is %s", remoteNode.State)
+	}
+
 	return &requestContext{
 		conf:       conf,
 		rinfo:      rinfo,
 		remoteNode: remoteNode,
+		start:      start,
 	}, nil
 }
 
+// refuseStage returns true if a node must not be sent the files of stage
+// because of its lifecycle state. Nodes in maintenance or retired are not
+// provisioned, and instead receive the maintenance iPXE or GRUB
+// configuration. Only retired nodes are refused the runtime overlay, so that
+// running nodes in maintenance continue to be updated.
+func refuseStage(remoteNode node.Node, stage string) bool {
+	switch stage {
+	case "kernel", "initramfs", "image", "system":
+		return remoteNode.InMaintenance()
+	case "runtime":
+		return remoteNode.Retired()
+	}
+	return false
+}
+
 type parsedRequest struct {
 	hwaddr     string
 	ipaddr     string
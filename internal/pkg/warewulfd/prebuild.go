@@ -0,0 +1,273 @@
+package warewulfd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/warewulf/warewulf/internal/pkg/batch"
+	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/hostlist"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/util"
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+// prebuildDelay is how long the overlay watcher waits after the last change
+// before it pre-builds overlays, so that a series of changes, e.g., while
+// an overlay is edited or nodes.conf is persisted, is handled at once.
+var prebuildDelay = 2 * time.Second
+
+// overlayContexts are the overlay images built for each node.
+var overlayContexts = []string{"system", "runtime"}
+
+// overlayTarget is an overlay image to be built.
+type overlayTarget struct {
+	node    node.Node
+	context string
+}
+
+/*
+WatchOverlays watches the node configuration and the overlay directories
+and, after they change, rebuilds the overlay images that are out of date in
+the background, so that nodes do not wait for them when they boot and build
+errors are reported before nodes reboot. Each pre-build is recorded as a
+job. WatchOverlays returns once the directories are watched; they are
+watched until ctx is cancelled.
+*/
+func WatchOverlays(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("could not watch overlays: %w", err)
+	}
+	conf := warewulfconf.Get()
+	// directories are watched, rather than files, as files are often
+	// replaced rather than written
+	for _, dir := range nodeConfigDirs() {
+		if util.IsDir(dir) {
+			if err := watcher.Add(dir); err != nil {
+				wwlog.Warn("could not watch %s: %s", dir, err)
+			}
+		}
+	}
+	overlayDirs := []string{conf.Paths.DistributionOverlaydir(), conf.Paths.SiteOverlaydir()}
+	for _, dir := range overlayDirs {
+		watchTree(watcher, dir)
+	}
+
+	trigger := make(chan struct{}, 1)
+	var reload atomic.Bool
+	go func() {
+		for range trigger {
+			// the node DB is usually reloaded by whatever changed it, so it
+			// is only reloaded here after changes that were not reloaded,
+			// e.g., when nodes.conf is edited by hand
+			if reload.Swap(false) && !nodeDBCurrent() {
+				Reload()
+			}
+			if err := PrebuildOverlays(); err != nil {
+				wwlog.Error("%s", err)
+			}
+		}
+	}()
+	go func() {
+		defer close(trigger)
+		defer func() { _ = watcher.Close() }()
+		debounce := time.NewTimer(prebuildDelay)
+		debounce.Stop()
+		defer debounce.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				nodesChanged := isNodeConfigFile(event.Name)
+				if !nodesChanged && !inDirs(event.Name, overlayDirs) {
+					continue
+				}
+				wwlog.Debug("overlay watcher: %s", event)
+				if event.Has(fsnotify.Create) && util.IsDir(event.Name) && inDirs(event.Name, overlayDirs) {
+					watchTree(watcher, event.Name)
+				}
+				if nodesChanged {
+					reload.Store(true)
+				}
+				debounce.Reset(prebuildDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				wwlog.Warn("overlay watcher: %s", err)
+			case <-debounce.C:
+				select {
+				case trigger <- struct{}{}:
+				default:
+					// a pre-build is already pending
+				}
+			}
+		}
+	}()
+	wwlog.Info("Watching %s for overlay changes", strings.Join(append(nodeConfigDirs(), overlayDirs...), ", "))
+	return nil
+}
+
+// nodeConfigDirs returns the directories that contain the node
+// configuration.
+func nodeConfigDirs() (dirs []string) {
+	nodesConf := warewulfconf.Get().Paths.NodesConf()
+	dirs = []string{filepath.Dir(nodesConf), filepath.Join(filepath.Dir(nodesConf), node.IncludeDir)}
+	db.lock.RLock()
+	files := db.yml.Files()
+	db.lock.RUnlock()
+	for _, file := range files {
+		if dir := filepath.Dir(file); !util.InSlice(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// isNodeConfigFile returns true if fileName is, or may become, part of the
+// node configuration.
+func isNodeConfigFile(fileName string) bool {
+	nodesConf := warewulfconf.Get().Paths.NodesConf()
+	if fileName == nodesConf {
+		return true
+	}
+	if filepath.Dir(fileName) == filepath.Join(filepath.Dir(nodesConf), node.IncludeDir) && strings.HasSuffix(fileName, ".conf") {
+		return true
+	}
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return util.InSlice(db.yml.Files(), fileName)
+}
+
+func inDirs(fileName string, dirs []string) bool {
+	for _, dir := range dirs {
+		if dir != "" && strings.HasPrefix(fileName, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchTree adds root and the directories below it to watcher.
+func watchTree(watcher *fsnotify.Watcher, root string) {
+	if !util.IsDir(root) {
+		return
+	}
+	err := filepath.WalkDir(root, func(walkPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(walkPath)
+		}
+		return nil
+	})
+	if err != nil {
+		wwlog.Warn("could not watch %s: %s", root, err)
+	}
+}
+
+// outOfDateOverlays returns the overlay images of allNodes that do not
+// exist or are out of date. Retired nodes are skipped.
+func outOfDateOverlays(allNodes []node.Node, digester *overlay.Digester) (targets []overlayTarget) {
+	for _, n := range allNodes {
+		if n.Retired() {
+			continue
+		}
+		for _, context := range overlayContexts {
+			if overlayOutOfDate(n, context, digester) {
+				targets = append(targets, overlayTarget{node: n, context: context})
+			}
+		}
+	}
+	return targets
+}
+
+/*
+PrebuildOverlays builds the overlay images that are out of date, with one
+worker per CPU, and waits for the build to finish. The build is recorded as
+a "prebuildOverlays" job, which reports its progress and any errors.
+*/
+func PrebuildOverlays() error {
+	allNodes, digester, err := overlayNodes()
+	if err != nil {
+		return fmt.Errorf("could not pre-build overlays: %w", err)
+	}
+	targets := outOfDateOverlays(allNodes, digester)
+	if len(targets) == 0 {
+		wwlog.Verbose("All overlay images are up to date")
+		return nil
+	}
+	var ids []string
+	for _, target := range targets {
+		if !util.InSlice(ids, target.node.Id()) {
+			ids = append(ids, target.node.Id())
+		}
+	}
+	wwlog.Info("Pre-building %d overlay images for %s", len(targets), hostlist.Compress(ids))
+
+	job, err := jobs.Start("prebuildOverlays", hostlist.Compress(ids), func(ctx context.Context, job *jobs.Job) error {
+		var lock sync.Mutex
+		var failed []string
+		done := 0
+		job.SetProgress(0, len(targets))
+		pool := batch.New(runtime.NumCPU())
+		for _, target := range targets {
+			pool.Submit(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				err := buildOverlayImage(target.node, target.context, allNodes)
+				lock.Lock()
+				defer lock.Unlock()
+				done++
+				if err != nil {
+					wwlog.Error("Failed to pre-build %s overlay for %s: %s", target.context, target.node.Id(), err)
+					job.Logf("Failed to build %s overlay for %s: %s", target.context, target.node.Id(), err)
+					if !util.InSlice(failed, target.node.Id()) {
+						failed = append(failed, target.node.Id())
+					}
+				} else {
+					job.Logf("Built %s overlay for %s", target.context, target.node.Id())
+				}
+				job.SetProgress(done, len(targets))
+			})
+		}
+		pool.Run()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("could not build overlays for %s", hostlist.Compress(failed))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not pre-build overlays: %w", err)
+	}
+	job.Wait()
+	if status := job.Status(); status.Error != "" {
+		return fmt.Errorf("pre-build of overlays failed: %s", status.Error)
+	}
+	return nil
+}
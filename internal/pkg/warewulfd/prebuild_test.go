@@ -0,0 +1,140 @@
+package warewulfd
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_outOfDateOverlays(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    system overlay:
+    - o1
+    runtime overlay:
+    - o1
+  n2:
+    system overlay:
+    - o1
+    runtime overlay:
+    - o1
+  n3:
+    state: retired
+    system overlay:
+    - o1
+    runtime overlay:
+    - o1`)
+	env.WriteFile("var/lib/warewulf/overlays/o1/rootfs/motd.ww", "{{ .Id }}")
+	assert.NoError(t, LoadNodeDB())
+
+	allNodes, digester, err := overlayNodes()
+	assert.NoError(t, err)
+	for _, n := range allNodes {
+		digest, err := digester.Digest(n, "system", []string{"o1"})
+		assert.NoError(t, err)
+		image := overlay.Image(n.Id(), "system", nil)
+		assert.NoError(t, os.MkdirAll(path.Dir(image), 0700))
+		assert.NoError(t, os.WriteFile(image, []byte("prebuilt"), 0600))
+		if n.Id() == "n2" {
+			digest = "stale"
+		}
+		assert.NoError(t, os.WriteFile(image+".digest", []byte(digest), 0600))
+	}
+
+	var targets []string
+	for _, target := range outOfDateOverlays(allNodes, digester) {
+		targets = append(targets, target.node.Id()+" "+target.context)
+	}
+	assert.ElementsMatch(t, []string{"n1 runtime", "n2 system", "n2 runtime"}, targets)
+}
+
+func Test_isNodeConfigFile(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+include:
+- racks/*.yaml
+nodes:
+  n1: {}`)
+	env.WriteFile("etc/warewulf/racks/rack1.yaml", `
+nodes:
+  n2: {}`)
+	assert.NoError(t, LoadNodeDB())
+
+	assert.True(t, isNodeConfigFile(env.GetPath("etc/warewulf/nodes.conf")))
+	assert.True(t, isNodeConfigFile(env.GetPath("etc/warewulf/nodes.conf.d/rack2.conf")))
+	assert.True(t, isNodeConfigFile(env.GetPath("etc/warewulf/racks/rack1.yaml")))
+	assert.False(t, isNodeConfigFile(env.GetPath("etc/warewulf/nodes.conf.d/rack2.conf.swp")))
+	assert.False(t, isNodeConfigFile(env.GetPath("etc/warewulf/racks/rack2.yaml")))
+	assert.False(t, isNodeConfigFile(env.GetPath("etc/warewulf/warewulf.conf")))
+
+	assert.True(t, inDirs("/overlays/o1/rootfs/motd", []string{"/other", "/overlays"}))
+	assert.False(t, inDirs("/overlays2/o1", []string{"/overlays"}))
+	assert.False(t, inDirs("/overlays", []string{"/overlays"}))
+}
+
+func Test_nodeDBCurrent(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n1: {}`)
+	assert.NoError(t, LoadNodeDB())
+	assert.True(t, nodeDBCurrent())
+
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n2: {}`)
+	assert.False(t, nodeDBCurrent())
+
+	// the watcher reuses a node DB that was reloaded after the change
+	Reload()
+	assert.True(t, nodeDBCurrent())
+	assert.True(t, NodeExists("n2"))
+}
+
+func Test_WatchOverlays(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  n1:
+    system overlay:
+    - o1
+    runtime overlay:
+    - o1`)
+	env.WriteFile("var/lib/warewulf/overlays/o1/rootfs/motd.ww", "{{ .Id }}")
+	assert.NoError(t, LoadNodeDB())
+
+	defer func(delay time.Duration) { prebuildDelay = delay }(prebuildDelay)
+	prebuildDelay = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, WatchOverlays(ctx))
+
+	env.WriteFile("var/lib/warewulf/overlays/o1/rootfs/issue.ww", "{{ .Id }}")
+	var status jobs.Status
+	assert.Eventually(t, func() bool {
+		statuses, err := jobs.List()
+		if err != nil || len(statuses) == 0 {
+			return false
+		}
+		status = statuses[len(statuses)-1]
+		return status.State.Finished()
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "prebuildOverlays", status.Type)
+	assert.Equal(t, "n1", status.Target)
+	assert.Equal(t, 2, status.Total)
+	assert.Equal(t, 2, status.Done)
+}
@@ -25,6 +25,8 @@ type templateVars struct {
 	KernelVersion string
 	Root          string
 	TLS           bool
+	State         string
+	StateReason   string
 	Tags          map[string]string
 	NetDevs       map[string]*node.NetDev
 }
@@ -5,6 +5,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"syscall"
 
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
+	"github.com/warewulf/warewulf/internal/pkg/jobs"
 	"github.com/warewulf/warewulf/internal/pkg/util"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd"
 	"github.com/warewulf/warewulf/internal/pkg/warewulfd/api"
@@ -64,7 +66,9 @@ func configureRootHandler(apiHandler http.Handler) *slashFix {
 	wwHandler.HandleFunc("/system/", warewulfd.HandleSystemOverlay)
 	wwHandler.HandleFunc("/runtime/", warewulfd.HandleRuntimeOverlay)
 	wwHandler.HandleFunc("/status", warewulfd.HandleStatus)
+	wwHandler.HandleFunc("/status/", warewulfd.HandleStatusHistory)
 	wwHandler.HandleFunc("/files/", warewulfd.HandleFiles)
+	wwHandler.Handle("/metrics", warewulfd.MetricsHandler())
 
 	/* Deprecated */
 	wwHandler.HandleFunc("/container/", warewulfd.HandleImage)
@@ -89,9 +93,27 @@ func RunServer() error {
 		}
 	}()
 
+	term := make(chan os.Signal, 1)
+	signal.Notify(term, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-term
+		wwlog.Info("Received %s, saving node status...", sig)
+		if err := warewulfd.PersistNodeStatus(); err != nil {
+			wwlog.Warn("Could not persist node status: %s", err)
+		}
+		os.Exit(0)
+	}()
+
 	warewulfd.Reload()
+	jobs.Recover()
 
 	conf := warewulfconf.Get()
+	if conf.Warewulf.PrebuildOverlays() {
+		if err := warewulfd.WatchOverlays(context.Background()); err != nil {
+			wwlog.Warn("%s", err)
+		}
+	}
 	daemonPort := conf.Warewulf.Port
 
 	auth := warewulfconf.NewAuthentication()
@@ -100,6 +122,9 @@ func RunServer() error {
 			wwlog.Warn("%v", err)
 		}
 	}
+	if err := auth.ReadTokens(conf.Paths.APITokensConf()); err != nil {
+		wwlog.Warn("%v", err)
+	}
 
 	var apiHandler http.Handler
 	if conf.API != nil && conf.API.Enabled() {
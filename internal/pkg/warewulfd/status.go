@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path"
 	"sync"
 	"time"
 
+	"github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/node"
 	"github.com/warewulf/warewulf/internal/pkg/wwlog"
 )
@@ -23,9 +26,24 @@ type NodeStatus struct {
 	Lastseen int64  `json:"last seen"`
 }
 
+// statusPersistInterval bounds how often the status database is written
+// to disk. Updates that arrive within the interval are coalesced into a
+// single deferred write.
+const statusPersistInterval = 5 * time.Second
+
+// statusFileName is the name of the persisted status database, relative
+// to the provision directory.
+const statusFileName = "status.json"
+
 var (
 	statusDB allStatus
 	dbLock   = sync.RWMutex{}
+
+	// statusLoaded records whether the persisted status file has been
+	// read into statusDB.
+	statusLoaded bool
+	lastPersist  time.Time
+	persistTimer *time.Timer
 )
 
 func init() {
@@ -38,6 +56,19 @@ func LoadNodeStatus() error {
 	var newDB allStatus
 	newDB.Nodes = make(map[string]*NodeStatus)
 
+	if !statusLoaded {
+		persisted, err := readStatusFile(statusFile())
+		if err != nil {
+			wwlog.Warn("Could not read persisted node status: %s", err)
+		}
+		for id, status := range persisted {
+			if _, ok := statusDB.Nodes[id]; !ok {
+				statusDB.Nodes[id] = status
+			}
+		}
+		statusLoaded = true
+	}
+
 	DB, err := node.New()
 	if err != nil {
 		return err
@@ -58,6 +89,7 @@ func LoadNodeStatus() error {
 	}
 
 	statusDB = newDB
+	resetStatusSubscribers()
 	return nil
 }
 
@@ -76,6 +108,85 @@ func updateStatus(nodeID, stage, sent, ipaddr string) {
 		Ipaddr:   ipaddr,
 	}
 	statusDB.Nodes[nodeID] = &n
+	schedulePersist()
+	publishStatus(n)
+}
+
+// statusFile returns the path of the file that the status database is
+// persisted to.
+func statusFile() string {
+	return path.Join(config.Get().Paths.WWProvisiondir, statusFileName)
+}
+
+// readStatusFile reads a previously persisted status database. A missing
+// file is not an error.
+func readStatusFile(fileName string) (map[string]*NodeStatus, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var persisted allStatus
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", fileName, err)
+	}
+	return persisted.Nodes, nil
+}
+
+// schedulePersist writes the status database to disk, at most once per
+// statusPersistInterval. The caller must hold dbLock.
+func schedulePersist() {
+	if persistTimer != nil {
+		return
+	}
+	wait := statusPersistInterval - time.Since(lastPersist)
+	if wait <= 0 {
+		if err := persistStatus(); err != nil {
+			wwlog.Warn("Could not persist node status: %s", err)
+		}
+		return
+	}
+	persistTimer = time.AfterFunc(wait, func() {
+		dbLock.Lock()
+		defer dbLock.Unlock()
+		persistTimer = nil
+		if err := persistStatus(); err != nil {
+			wwlog.Warn("Could not persist node status: %s", err)
+		}
+	})
+}
+
+// persistStatus atomically writes the status database to statusFile(). The
+// caller must hold dbLock.
+func persistStatus() error {
+	lastPersist = time.Now()
+	data, err := json.Marshal(statusDB)
+	if err != nil {
+		return fmt.Errorf("could not marshal JSON data from status structure: %w", err)
+	}
+	fileName := statusFile()
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	tmpFile := fileName + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, fileName)
+}
+
+// PersistNodeStatus immediately writes the status database to disk,
+// cancelling any pending deferred write.
+func PersistNodeStatus() error {
+	dbLock.Lock()
+	defer dbLock.Unlock()
+	if persistTimer != nil {
+		persistTimer.Stop()
+		persistTimer = nil
+	}
+	return persistStatus()
 }
 
 func statusJSON() ([]byte, error) {
@@ -92,7 +203,13 @@ func statusJSON() ([]byte, error) {
 	return ret, nil
 }
 
+// HandleStatus returns the status of all nodes as a JSON document or, if the
+// client accepts text/event-stream, as a stream of status updates.
 func HandleStatus(w http.ResponseWriter, req *http.Request) {
+	if wantsStatusStream(req) {
+		serveStatusStream(w, req)
+		return
+	}
 
 	status, err := statusJSON()
 	if err != nil {
@@ -0,0 +1,41 @@
+package warewulfd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_PersistNodeStatus(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	env.WriteFile("/etc/warewulf/nodes.conf", `nodes:
+  n1: {}
+  n2: {}`)
+
+	dbLock.Lock()
+	statusDB.Nodes = map[string]*NodeStatus{
+		"n1": {NodeName: "n1", Stage: "kernel", Sent: "vmlinuz", Ipaddr: "10.0.0.1", Lastseen: 1712345678},
+		"n3": {NodeName: "n3", Stage: "ipxe", Lastseen: 1712345600},
+	}
+	dbLock.Unlock()
+	assert.NoError(t, PersistNodeStatus())
+	assert.FileExists(t, env.GetPath("/srv/warewulf/status.json"))
+
+	// simulate a daemon restart
+	dbLock.Lock()
+	statusDB.Nodes = make(map[string]*NodeStatus)
+	statusLoaded = false
+	dbLock.Unlock()
+
+	assert.NoError(t, LoadNodeStatus())
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+	assert.Len(t, statusDB.Nodes, 2)
+	assert.Equal(t, &NodeStatus{NodeName: "n1", Stage: "kernel", Sent: "vmlinuz", Ipaddr: "10.0.0.1", Lastseen: 1712345678}, statusDB.Nodes["n1"])
+	assert.Equal(t, &NodeStatus{NodeName: "n2"}, statusDB.Nodes["n2"])
+	assert.NotContains(t, statusDB.Nodes, "n3")
+}
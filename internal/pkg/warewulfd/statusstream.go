@@ -0,0 +1,148 @@
+package warewulfd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/warewulf/warewulf/internal/pkg/wwlog"
+)
+
+const (
+	// statusSubscriberBuffer is the number of status updates buffered for
+	// each stream client. A client that falls further behind is
+	// disconnected and is expected to reconnect for a fresh snapshot.
+	statusSubscriberBuffer = 4096
+
+	// statusKeepaliveInterval is how often an idle status stream sends a
+	// comment line to keep intermediate proxies from closing it.
+	statusKeepaliveInterval = 30 * time.Second
+)
+
+var (
+	statusSubscribers = make(map[chan NodeStatus]struct{})
+	subscriberLock    = sync.Mutex{}
+)
+
+func subscribeStatus() chan NodeStatus {
+	subscriberLock.Lock()
+	defer subscriberLock.Unlock()
+	ch := make(chan NodeStatus, statusSubscriberBuffer)
+	statusSubscribers[ch] = struct{}{}
+	return ch
+}
+
+func unsubscribeStatus(ch chan NodeStatus) {
+	subscriberLock.Lock()
+	defer subscriberLock.Unlock()
+	if _, ok := statusSubscribers[ch]; ok {
+		delete(statusSubscribers, ch)
+		close(ch)
+	}
+}
+
+// publishStatus sends a status update to every stream client.
+func publishStatus(status NodeStatus) {
+	subscriberLock.Lock()
+	defer subscriberLock.Unlock()
+	for ch := range statusSubscribers {
+		select {
+		case ch <- status:
+		default:
+			wwlog.Warn("Status stream client is too slow, disconnecting")
+			delete(statusSubscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// resetStatusSubscribers disconnects every stream client, e.g., after the
+// set of nodes has changed, so that clients reconnect for a fresh snapshot.
+func resetStatusSubscribers() {
+	subscriberLock.Lock()
+	defer subscriberLock.Unlock()
+	for ch := range statusSubscribers {
+		delete(statusSubscribers, ch)
+		close(ch)
+	}
+}
+
+// wantsStatusStream returns true if the client asked for a Server-Sent
+// Events stream rather than a single JSON document.
+func wantsStatusStream(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		if strings.Contains(accept, "text/event-stream") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeStatusEvent(w http.ResponseWriter, status NodeStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("could not marshal JSON data from status structure: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+	return err
+}
+
+// serveStatusStream sends the status of every node as a Server-Sent Events
+// stream, followed by each status update as it is recorded.
+func serveStatusStream(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		wwlog.Error("Status stream not supported by response writer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// subscribe before taking the snapshot so that no update is missed
+	updates := subscribeStatus()
+	defer unsubscribeStatus(updates)
+
+	dbLock.RLock()
+	snapshot := make([]NodeStatus, 0, len(statusDB.Nodes))
+	for _, status := range statusDB.Nodes {
+		snapshot = append(snapshot, *status)
+	}
+	dbLock.RUnlock()
+
+	wwlog.Debug("Streaming node status to %s", req.RemoteAddr)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, status := range snapshot {
+		if err := writeStatusEvent(w, status); err != nil {
+			wwlog.Debug("Status stream closed: %s", err)
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(statusKeepaliveInterval)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case status, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := writeStatusEvent(w, status); err != nil {
+				wwlog.Debug("Status stream closed: %s", err)
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				wwlog.Debug("Status stream closed: %s", err)
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
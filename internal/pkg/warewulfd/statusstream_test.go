@@ -0,0 +1,82 @@
+package warewulfd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warewulf/warewulf/internal/pkg/testenv"
+)
+
+func Test_StatusStream(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+
+	dbLock.Lock()
+	statusDB.Nodes = map[string]*NodeStatus{
+		"n1": {NodeName: "n1", Stage: "kernel", Lastseen: 1712345678},
+	}
+	dbLock.Unlock()
+
+	srv := httptest.NewServer(http.HandlerFunc(HandleStatus))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan NodeStatus)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var status NodeStatus
+				if json.Unmarshal([]byte(data), &status) == nil {
+					events <- status
+				}
+			}
+		}
+		close(events)
+	}()
+
+	readEvent := func() NodeStatus {
+		select {
+		case status := <-events:
+			return status
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for status event")
+		}
+		return NodeStatus{}
+	}
+
+	assert.Equal(t, "kernel", readEvent().Stage)
+	updateStatus("n1", "image", "rootfs.img", "10.0.0.1")
+	status := readEvent()
+	assert.Equal(t, "n1", status.NodeName)
+	assert.Equal(t, "image", status.Stage)
+	assert.Equal(t, "rootfs.img", status.Sent)
+}
+
+func Test_HandleStatusJSON(t *testing.T) {
+	dbLock.Lock()
+	statusDB.Nodes = map[string]*NodeStatus{
+		"n1": {NodeName: "n1", Stage: "kernel", Lastseen: 1712345678},
+	}
+	dbLock.Unlock()
+
+	w := httptest.NewRecorder()
+	HandleStatus(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status allStatus
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "kernel", status.Nodes["n1"].Stage)
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 
 	"github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/node"
@@ -44,36 +47,115 @@ func sendFile(
 	return nil
 }
 
+// overlayBuilds deduplicates concurrent builds of the same overlay image,
+// e.g., when many nodes boot at once.
+var overlayBuilds singleflight.Group
+
+/*
+getOverlayFile returns the overlay image of a node for context, building it
+if it does not exist. If autobuild is set, the image is also rebuilt if its
+digest, which covers the node's configuration and the overlay sources, has
+changed since it was built. Concurrent builds of the same image are
+performed only once.
+*/
 func getOverlayFile(n node.Node, context string, autobuild bool) (stage_file string, err error) {
 	stage_file = overlay.Image(n.Id(), context, nil)
-	build := !util.IsFile(stage_file)
+	if stage_file == "" {
+		return
+	}
 	wwlog.Verbose("stage file: %s", stage_file)
-	if !build && autobuild {
-		build = util.PathIsNewer(stage_file, config.Get().Paths.NodesConf())
+	build := !util.IsFile(stage_file)
+	if !build && !autobuild {
+		return
+	}
+
+	allNodes, digester, err := overlayNodes()
+	if err != nil {
+		wwlog.Error("Failed to build overlay: %s, %s\n%s",
+			n.Id(), stage_file, err)
+		return "", err
+	}
+	if !build {
+		build = overlayOutOfDate(n, context, digester)
 	}
 
 	if build {
-		registry, err := node.New()
-		if err != nil {
-			wwlog.Error("Failed to build overlay: %s, %s\n%s",
-				n.Id(), stage_file, err)
-			return "", err
-		}
-		var allNodes []node.Node
-		allNodes, err = registry.FindAllNodes()
-		if err != nil {
+		// build failures are logged; the handler reports a missing image
+		if buildErr := buildOverlayImage(n, context, allNodes); buildErr != nil {
 			wwlog.Error("Failed to build overlay: %s, %s\n%s",
-				n.Id(), stage_file, err)
-			return "", err
-		}
-		err = overlay.BuildAllOverlays([]node.Node{n}, allNodes, 1)
-		if err != nil {
-			wwlog.Error("Failed to build overlay: %s, %s\n%s",
-				n.Id(), stage_file, err)
+				n.Id(), stage_file, buildErr)
 		}
 	}
 
-	return
+	return stage_file, nil
+}
+
+// overlayOutOfDate returns true if the node's image for context does not
+// exist, or if its digest has changed since it was built.
+func overlayOutOfDate(n node.Node, context string, digester *overlay.Digester) bool {
+	image := overlay.Image(n.Id(), context, nil)
+	if !util.IsFile(image) {
+		return true
+	}
+	recorded := overlay.ImageDigest(n.Id(), context, nil)
+	if recorded == "" {
+		// images built without a digest, e.g., by an earlier version, are
+		// rebuilt if nodes.conf is newer, as they were before
+		return util.PathIsNewer(image, config.Get().Paths.NodesConf())
+	}
+	digest, err := digester.Digest(n, context, contextOverlays(n, context))
+	if err != nil {
+		wwlog.Warn("Failed to compute overlay digest: %s, %s: %s", n.Id(), context, err)
+		return true
+	}
+	return digest != recorded
+}
+
+// buildOverlayImage builds the node's image for context. Concurrent builds
+// of the same image are performed only once.
+func buildOverlayImage(n node.Node, context string, allNodes []node.Node) error {
+	_, err, _ := overlayBuilds.Do(overlay.Image(n.Id(), context, nil), func() (interface{}, error) {
+		start := time.Now()
+		defer func() {
+			overlayBuildDuration.WithLabelValues(context).Observe(time.Since(start).Seconds())
+		}()
+		wwlog.Info("Building %s overlay image for %s", context, n.Id())
+		return nil, overlay.BuildOverlay(n, allNodes, context, contextOverlays(n, context))
+	})
+	return err
+}
+
+// contextOverlays returns the overlays from which a node's image for context
+// is built.
+func contextOverlays(n node.Node, context string) []string {
+	switch context {
+	case "system":
+		return n.SystemOverlay
+	case "runtime":
+		return n.RuntimeOverlay
+	}
+	return nil
+}
+
+// overlayNodes returns the configuration of all nodes, from which overlays
+// are built, and a Digester for it. These are taken from the node DB if it
+// is loaded.
+func overlayNodes() ([]node.Node, *overlay.Digester, error) {
+	db.lock.RLock()
+	allNodes, digester := db.allNodes, db.digester
+	db.lock.RUnlock()
+	if digester != nil {
+		return allNodes, digester, nil
+	}
+	registry, err := node.New()
+	if err != nil {
+		return nil, nil, err
+	}
+	allNodes, err = registry.FindAllNodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return allNodes, overlay.NewDigester(allNodes), nil
 }
 
 var arpFile string
@@ -4,10 +4,12 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	warewulfconf "github.com/warewulf/warewulf/internal/pkg/config"
 	"github.com/warewulf/warewulf/internal/pkg/node"
+	"github.com/warewulf/warewulf/internal/pkg/overlay"
 	"github.com/warewulf/warewulf/internal/pkg/testenv"
 )
 
@@ -67,3 +69,71 @@ nodes:
 		})
 	}
 }
+
+func Test_getOverlayFile_Digest(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  node1:
+    system overlay:
+    - o1`)
+	env.WriteFile("var/lib/warewulf/overlays/o1/rootfs/motd.ww", "{{ .Id }}")
+	assert.NoError(t, LoadNodeDB())
+
+	nodeInfo, err := db.yml.GetNode("node1")
+	assert.NoError(t, err)
+	_, digester, err := overlayNodes()
+	assert.NoError(t, err)
+	digest, err := digester.Digest(nodeInfo, "system", []string{"o1"})
+	assert.NoError(t, err)
+
+	image := overlay.Image("node1", "system", nil)
+	assert.NoError(t, os.MkdirAll(path.Dir(image), 0700))
+	assert.NoError(t, os.WriteFile(image, []byte("prebuilt"), 0600))
+	assert.NoError(t, os.WriteFile(image+".digest", []byte(digest), 0600))
+
+	// an image whose digest is current is not rebuilt, even though
+	// nodes.conf is newer
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  node1:
+    system overlay:
+    - o1
+  node2: {}`)
+	assert.NoError(t, LoadNodeDB())
+	nodeInfo, err = db.yml.GetNode("node1")
+	assert.NoError(t, err)
+	result, err := getOverlayFile(nodeInfo, "system", true)
+	assert.NoError(t, err)
+	assert.Equal(t, image, result)
+	content, err := os.ReadFile(image)
+	assert.NoError(t, err)
+	assert.Equal(t, "prebuilt", string(content))
+}
+
+func Test_overlayOutOfDate_NoDigest(t *testing.T) {
+	env := testenv.New(t)
+	defer env.RemoveAll()
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  node1: {}`)
+	assert.NoError(t, LoadNodeDB())
+	nodeInfo, err := db.yml.GetNode("node1")
+	assert.NoError(t, err)
+	_, digester, err := overlayNodes()
+	assert.NoError(t, err)
+
+	image := overlay.Image("node1", "system", nil)
+	assert.NoError(t, os.MkdirAll(path.Dir(image), 0700))
+	assert.NoError(t, os.WriteFile(image, []byte("prebuilt"), 0600))
+
+	// an image without a digest is current unless nodes.conf is newer
+	assert.False(t, overlayOutOfDate(nodeInfo, "system", digester))
+	time.Sleep(10 * time.Millisecond)
+	env.WriteFile("etc/warewulf/nodes.conf", `
+nodes:
+  node1: {}
+  node2: {}`)
+	assert.True(t, overlayOutOfDate(nodeInfo, "system", digester))
+}